@@ -0,0 +1,150 @@
+package burst
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/anggi-susanto/go-face-detection-be/domain"
+	"github.com/anggi-susanto/go-face-detection-be/internal/repository/mongo"
+)
+
+// ErrNotFound is returned when no photo was uploaded with the burst ID.
+var ErrNotFound = errors.New("burst not found")
+
+// Weights of the ranking criteria, they add up to 1.
+const (
+	facesWeight     = 0.3
+	eyesOpenWeight  = 0.3
+	sharpnessWeight = 0.25
+	faceSizeWeight  = 0.15
+)
+
+type Service interface {
+	GetBurst(ctx context.Context, id string) (*domain.Burst, error)
+}
+
+type service struct {
+	photoRepository mongo.PhotoRepository
+}
+
+func NewService(photoRepository mongo.PhotoRepository) Service {
+	return &service{
+		photoRepository: photoRepository,
+	}
+}
+
+// GetBurst returns the burst with its photos ranked once every photo has been
+// processed.
+func (s *service) GetBurst(ctx context.Context, id string) (*domain.Burst, error) {
+	photos, err := s.photoRepository.FindByBurstID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(photos) == 0 {
+		return nil, ErrNotFound
+	}
+
+	burst := &domain.Burst{
+		ID:     id,
+		Status: "processed",
+		Photos: len(photos),
+		Frames: []domain.BurstFrame{},
+	}
+	processed := make([]domain.Photo, 0, len(photos))
+	for _, p := range photos {
+		switch p.Status {
+		case "pending":
+			burst.Status = "pending"
+		case "processed":
+			processed = append(processed, p)
+		default:
+			burst.Failed++
+		}
+	}
+	if burst.Status == "pending" {
+		return burst, nil
+	}
+
+	burst.Frames = rank(processed)
+	if len(burst.Frames) > 0 {
+		best := burst.Frames[0].PhotoID
+		burst.BestPhotoID = &best
+	}
+	return burst, nil
+}
+
+// rank scores every photo on its face count, mean face sharpness, fraction of
+// open eyes and mean face size, each relative to the best photo, and returns
+// the frames best first. Ties keep the upload order.
+func rank(photos []domain.Photo) []domain.BurstFrame {
+	frames := make([]domain.BurstFrame, len(photos))
+	var maxFaces, maxSharpness, maxSize float64
+	for i, p := range photos {
+		sharpness, eyesOpen, size := faceStats(p.Faces)
+		frames[i] = domain.BurstFrame{
+			PhotoID:       p.ID,
+			FacesDetected: len(p.Faces),
+			Faces:         float64(len(p.Faces)),
+			Sharpness:     sharpness,
+			EyesOpen:      eyesOpen,
+			FaceSize:      size,
+		}
+		maxFaces = max(maxFaces, frames[i].Faces)
+		maxSharpness = max(maxSharpness, sharpness)
+		maxSize = max(maxSize, size)
+	}
+
+	for i := range frames {
+		f := &frames[i]
+		f.Faces = relative(f.Faces, maxFaces)
+		f.Sharpness = relative(f.Sharpness, maxSharpness)
+		f.FaceSize = relative(f.FaceSize, maxSize)
+		var eyesOpen float64
+		if f.EyesOpen != nil {
+			eyesOpen = *f.EyesOpen
+		}
+		f.Score = facesWeight*f.Faces +
+			eyesOpenWeight*eyesOpen +
+			sharpnessWeight*f.Sharpness +
+			faceSizeWeight*f.FaceSize
+	}
+	sort.SliceStable(frames, func(i, j int) bool {
+		return frames[i].Score > frames[j].Score
+	})
+	return frames
+}
+
+// faceStats returns the mean sharpness, the fraction of faces with eyes
+// found open and the mean normalized area of the faces, all zero without
+// faces. The eyes open fraction is nil when no face has an eyes open cue.
+func faceStats(faces []domain.Face) (sharpness float64, eyesOpen *float64, size float64) {
+	if len(faces) == 0 {
+		return 0, nil, 0
+	}
+	open, known := 0, 0
+	for _, f := range faces {
+		sharpness += f.Quality.Sharpness
+		size += f.Normalized.Width * f.Normalized.Height
+		if f.EyesOpen != nil {
+			known++
+			if *f.EyesOpen {
+				open++
+			}
+		}
+	}
+	n := float64(len(faces))
+	if known > 0 {
+		fraction := float64(open) / n
+		eyesOpen = &fraction
+	}
+	return sharpness / n, eyesOpen, size / n
+}
+
+// relative returns v as a fraction of the best value, 0 when best is 0.
+func relative(v, best float64) float64 {
+	if best == 0 {
+		return 0
+	}
+	return v / best
+}
@@ -0,0 +1,134 @@
+package burst
+
+import (
+	"math"
+	"testing"
+
+	"github.com/anggi-susanto/go-face-detection-be/domain"
+)
+
+func TestRank(t *testing.T) {
+	open, closed := true, false
+	face := func(sharpness float64, eyesOpen *bool) domain.Face {
+		return domain.Face{
+			Normalized: domain.NormalizedBox{Width: 0.1, Height: 0.1},
+			Quality:    domain.FaceQuality{Sharpness: sharpness},
+			EyesOpen:   eyesOpen,
+		}
+	}
+
+	tests := []struct {
+		name  string
+		faces [][]domain.Face
+		// wantOrder lists the photo indexes best first, wantScores their
+		// scores in that order.
+		wantOrder  []int
+		wantScores []float64
+	}{
+		{name: "no photos", faces: nil, wantOrder: []int{}, wantScores: []float64{}},
+		{
+			name:      "single photo scores one",
+			faces:     [][]domain.Face{{face(10, &open)}},
+			wantOrder: []int{0}, wantScores: []float64{1},
+		},
+		{
+			name:      "more faces first",
+			faces:     [][]domain.Face{{face(10, &open)}, {face(10, &open), face(10, &open)}},
+			wantOrder: []int{1, 0}, wantScores: []float64{1, 0.85},
+		},
+		{
+			name:      "open eyes first",
+			faces:     [][]domain.Face{{face(10, &closed)}, {face(10, &open)}},
+			wantOrder: []int{1, 0}, wantScores: []float64{1, 0.7},
+		},
+		{
+			name:      "unknown eyes ranked below open eyes",
+			faces:     [][]domain.Face{{face(10, nil)}, {face(10, &open)}},
+			wantOrder: []int{1, 0}, wantScores: []float64{1, 0.7},
+		},
+		{
+			name:      "unknown eyes tie with closed eyes",
+			faces:     [][]domain.Face{{face(10, nil)}, {face(10, &closed)}},
+			wantOrder: []int{0, 1}, wantScores: []float64{0.7, 0.7},
+		},
+		{
+			name:      "unknown eyes do not count as open",
+			faces:     [][]domain.Face{{face(10, &open), face(10, nil)}, {face(10, &open), face(10, &open)}},
+			wantOrder: []int{1, 0}, wantScores: []float64{1, 0.85},
+		},
+		{
+			name:      "sharper first",
+			faces:     [][]domain.Face{{face(5, &open)}, {face(10, &open)}},
+			wantOrder: []int{1, 0}, wantScores: []float64{1, 0.875},
+		},
+		{
+			name:      "ties keep the upload order",
+			faces:     [][]domain.Face{{face(10, &open)}, {face(10, &open)}},
+			wantOrder: []int{0, 1}, wantScores: []float64{1, 1},
+		},
+		{
+			name:      "photo without faces last",
+			faces:     [][]domain.Face{nil, {face(10, &open)}},
+			wantOrder: []int{1, 0}, wantScores: []float64{1, 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			photos := make([]domain.Photo, len(tt.faces))
+			for i, faces := range tt.faces {
+				photos[i] = domain.Photo{ID: domain.NewPhotoID(), Faces: faces}
+			}
+
+			frames := rank(photos)
+			if len(frames) != len(tt.wantOrder) {
+				t.Fatalf("rank() returned %d frames, want %d", len(frames), len(tt.wantOrder))
+			}
+			for i, f := range frames {
+				if want := photos[tt.wantOrder[i]].ID; f.PhotoID != want {
+					t.Errorf("frame %d is photo %s, want %s", i, f.PhotoID, want)
+				}
+				if math.Abs(f.Score-tt.wantScores[i]) > 1e-9 {
+					t.Errorf("frame %d score = %v, want %v", i, f.Score, tt.wantScores[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFaceStatsEyesOpen(t *testing.T) {
+	open, closed := true, false
+	half, none := 0.5, 0.0
+
+	tests := []struct {
+		name     string
+		eyesOpen []*bool
+		want     *float64
+	}{
+		{name: "no faces", eyesOpen: nil, want: nil},
+		{name: "no cues", eyesOpen: []*bool{nil, nil}, want: nil},
+		{name: "all closed", eyesOpen: []*bool{&closed, &closed}, want: &none},
+		{name: "half open", eyesOpen: []*bool{&open, &closed}, want: &half},
+		{name: "unknown counts against", eyesOpen: []*bool{&open, nil}, want: &half},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			faces := make([]domain.Face, len(tt.eyesOpen))
+			for i, e := range tt.eyesOpen {
+				faces[i].EyesOpen = e
+			}
+			_, got, _ := faceStats(faces)
+			switch {
+			case got == nil && tt.want == nil:
+			case got == nil || tt.want == nil || *got != *tt.want:
+				t.Errorf("faceStats() eyes open = %v, want %v", deref(got), deref(tt.want))
+			}
+		})
+	}
+}
+
+func deref(v *float64) any {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
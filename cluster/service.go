@@ -0,0 +1,390 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/anggi-susanto/go-face-detection-be/config"
+	"github.com/anggi-susanto/go-face-detection-be/domain"
+	"github.com/anggi-susanto/go-face-detection-be/internal/clustering"
+	"github.com/anggi-susanto/go-face-detection-be/internal/embedding"
+	"github.com/anggi-susanto/go-face-detection-be/internal/repository/mongo"
+	"github.com/anggi-susanto/go-face-detection-be/person"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrInvalidEdit is returned when a merge or split does not fit the stored
+// clusters.
+var ErrInvalidEdit = errors.New("invalid cluster edit")
+
+type Service interface {
+	clustering.Assigner
+	clustering.Remover
+	Recluster(ctx context.Context) error
+	Run(ctx context.Context)
+	ListClusters(ctx context.Context) ([]domain.Cluster, error)
+	GetCluster(ctx context.Context, id domain.ClusterID) (*domain.Cluster, error)
+	Merge(ctx context.Context, id domain.ClusterID, req domain.ClusterMergeRequest) (*domain.Cluster, error)
+	Split(ctx context.Context, id domain.ClusterID, req domain.ClusterSplitRequest) (*domain.Cluster, error)
+	Name(ctx context.Context, id domain.ClusterID, req domain.PersonRequest) (*domain.Cluster, error)
+}
+
+// service serializes every change to the clusters, so that background runs,
+// incremental assignment and user edits never interleave.
+type service struct {
+	mu                sync.Mutex
+	clusterRepository mongo.ClusterRepository
+	photoRepository   mongo.PhotoRepository
+	personService     person.Service
+	config            *config.ClusterConfig
+}
+
+// NewService creates the face clustering service.
+//
+// Parameters:
+// - clusterRepository: The repository holding the clusters.
+// - photoRepository: The repository holding the processed photos.
+// - personService: The service named clusters are enrolled with.
+// - config: A pointer to a config.ClusterConfig object tuning the clustering.
+//
+// Returns:
+// - Service: The face clustering service.
+func NewService(clusterRepository mongo.ClusterRepository, photoRepository mongo.PhotoRepository, personService person.Service, config *config.ClusterConfig) Service {
+	return &service{
+		clusterRepository: clusterRepository,
+		photoRepository:   photoRepository,
+		personService:     personService,
+		config:            config,
+	}
+}
+
+// Run clusters the collection once and then again every configured interval
+// until the context is done.
+func (s *service) Run(ctx context.Context) {
+	if s.config.Interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+	for {
+		if err := s.Recluster(ctx); err != nil {
+			logrus.Errorf("Failed to cluster faces: %v", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Recluster groups every stored face that is not part of a locked cluster
+// with DBSCAN and replaces the unlocked clusters with the result.
+func (s *service) Recluster(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	start := time.Now()
+	locked, err := s.clusterRepository.FindLocked(ctx)
+	if err != nil {
+		return err
+	}
+	taken := make(map[domain.FaceRef]bool)
+	for _, c := range locked {
+		for _, f := range c.Faces {
+			taken[f] = true
+		}
+	}
+
+	photos, err := s.photoRepository.FindFaceEmbeddings(ctx)
+	if err != nil {
+		return err
+	}
+	faces := faceEmbeddings(photos)
+	var refs []domain.FaceRef
+	var vectors [][]float32
+	for ref, vector := range faces {
+		if !taken[ref] {
+			refs = append(refs, ref)
+			vectors = append(vectors, vector)
+		}
+	}
+
+	labels := clustering.DBSCAN(vectors, s.config.Distance, s.config.MinFaces)
+	groups := make(map[int][]int)
+	for i, label := range labels {
+		if label != clustering.Noise {
+			groups[label] = append(groups[label], i)
+		}
+	}
+	clusters := make([]domain.Cluster, 0, len(groups))
+	for _, members := range groups {
+		c := domain.Cluster{ID: domain.NewClusterID(), UpdatedAt: time.Now()}
+		groupVectors := make([][]float32, 0, len(members))
+		for _, i := range members {
+			c.Faces = append(c.Faces, refs[i])
+			groupVectors = append(groupVectors, vectors[i])
+		}
+		sortFaces(c.Faces)
+		c.Size = len(c.Faces)
+		c.Centroid = clustering.Centroid(groupVectors)
+		clusters = append(clusters, c)
+	}
+	if err := s.clusterRepository.ReplaceUnlocked(ctx, clusters); err != nil {
+		return err
+	}
+	logrus.Infof("Clustered %d faces into %d clusters in %s", len(refs), len(clusters), time.Since(start))
+	return nil
+}
+
+// Assign adds every face of the photo to the cluster with the closest
+// centroid within the configured distance. Faces of an earlier run of the
+// same photo are dropped first.
+func (s *service) Assign(ctx context.Context, photo *domain.Photo) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.clusterRepository.RemovePhoto(ctx, photo.ID); err != nil {
+		return err
+	}
+	clusters, err := s.clusterRepository.FindCentroids(ctx)
+	if err != nil {
+		return err
+	}
+	for i, face := range photo.Faces {
+		if len(face.Embedding) == 0 {
+			continue
+		}
+		best, bestScore := -1, 0.0
+		for j, c := range clusters {
+			if score := embedding.Cosine(face.Embedding, c.Centroid); best < 0 || score > bestScore {
+				best, bestScore = j, score
+			}
+		}
+		if best < 0 || 1-bestScore > s.config.Distance {
+			continue
+		}
+		c := &clusters[best]
+		c.Centroid = clustering.AddToCentroid(c.Centroid, c.Size, face.Embedding)
+		c.Size++
+		ref := domain.FaceRef{PhotoID: photo.ID, FaceIndex: i}
+		if err := s.clusterRepository.AddFace(ctx, c.ID, ref, c.Centroid); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RemovePhoto drops every face of the photo from the clusters, deleting the
+// unlocked clusters left empty.
+func (s *service) RemovePhoto(ctx context.Context, id domain.PhotoID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.clusterRepository.RemovePhoto(ctx, id)
+}
+
+func (s *service) ListClusters(ctx context.Context) ([]domain.Cluster, error) {
+	return s.clusterRepository.FindAll(ctx)
+}
+
+func (s *service) GetCluster(ctx context.Context, id domain.ClusterID) (*domain.Cluster, error) {
+	return s.clusterRepository.FindByID(ctx, id)
+}
+
+// Merge moves every face of the given clusters into the cluster and deletes
+// them. The merged cluster keeps its name, or takes the first one found.
+func (s *service) Merge(ctx context.Context, id domain.ClusterID, req domain.ClusterMergeRequest) (*domain.Cluster, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	target, err := s.clusterRepository.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	var merged []domain.ClusterID
+	for _, otherID := range req.ClusterIDs {
+		if otherID == id || slices.Contains(merged, otherID) {
+			continue
+		}
+		other, err := s.clusterRepository.FindByID(ctx, otherID)
+		if err != nil {
+			return nil, err
+		}
+		if target.PersonID == nil {
+			target.PersonID, target.Name = other.PersonID, other.Name
+		}
+		target.Faces = append(target.Faces, other.Faces...)
+		merged = append(merged, otherID)
+	}
+	if len(merged) == 0 {
+		return nil, fmt.Errorf("%w: a cluster cannot be merged into itself", ErrInvalidEdit)
+	}
+
+	if err := s.refresh(ctx, target); err != nil {
+		return nil, err
+	}
+	if err := s.clusterRepository.Update(ctx, target); err != nil {
+		return nil, err
+	}
+	if err := s.clusterRepository.Delete(ctx, merged...); err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+// Split moves the given faces out of the cluster into a new one, which is
+// returned.
+func (s *service) Split(ctx context.Context, id domain.ClusterID, req domain.ClusterSplitRequest) (*domain.Cluster, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	source, err := s.clusterRepository.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	split := &domain.Cluster{ID: domain.NewClusterID()}
+	for _, f := range req.Faces {
+		i := slices.Index(source.Faces, f)
+		if i < 0 {
+			return nil, fmt.Errorf("%w: face %d of photo %s is not in the cluster", ErrInvalidEdit, f.FaceIndex, f.PhotoID)
+		}
+		source.Faces = slices.Delete(source.Faces, i, i+1)
+		split.Faces = append(split.Faces, f)
+	}
+	if len(source.Faces) == 0 {
+		return nil, fmt.Errorf("%w: at least one face must stay in the cluster", ErrInvalidEdit)
+	}
+
+	if err := s.refresh(ctx, source); err != nil {
+		return nil, err
+	}
+	if err := s.refresh(ctx, split); err != nil {
+		return nil, err
+	}
+	if err := s.clusterRepository.Update(ctx, source); err != nil {
+		return nil, err
+	}
+	if err := s.clusterRepository.Create(ctx, split); err != nil {
+		return nil, err
+	}
+	return split, nil
+}
+
+// Name enrolls a person from the faces closest to the cluster centroid,
+// links the cluster to them and assigns every face of the cluster to them.
+func (s *service) Name(ctx context.Context, id domain.ClusterID, req domain.PersonRequest) (*domain.Cluster, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, err := s.clusterRepository.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	faces, err := s.embeddings(ctx, c.Faces...)
+	if err != nil {
+		return nil, err
+	}
+
+	// The faces closest to the centroid are the most typical references.
+	refs := slices.Clone(c.Faces)
+	refs = slices.DeleteFunc(refs, func(f domain.FaceRef) bool { return faces[f] == nil })
+	sort.SliceStable(refs, func(i, j int) bool {
+		return embedding.Cosine(faces[refs[i]], c.Centroid) > embedding.Cosine(faces[refs[j]], c.Centroid)
+	})
+	if len(refs) == 0 {
+		return nil, fmt.Errorf("%w: the cluster has no searchable faces", ErrInvalidEdit)
+	}
+	references := make([][]float32, 0, domain.MaxReferencePhotos)
+	for _, f := range refs[:min(len(refs), domain.MaxReferencePhotos)] {
+		references = append(references, faces[f])
+	}
+
+	p, err := s.personService.EnrollEmbeddings(ctx, req, references)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range refs {
+		score := 0.0
+		for _, ref := range references {
+			score = max(score, embedding.Cosine(faces[f], ref))
+		}
+		if err := s.photoRepository.SetFacePerson(ctx, f, p.ID, score); err != nil {
+			return nil, err
+		}
+	}
+
+	c.PersonID, c.Name = &p.ID, p.Name
+	c.Locked = true
+	c.UpdatedAt = time.Now()
+	if err := s.clusterRepository.Update(ctx, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// refresh recomputes the size and centroid of an edited cluster and locks
+// it.
+func (s *service) refresh(ctx context.Context, c *domain.Cluster) error {
+	faces, err := s.embeddings(ctx, c.Faces...)
+	if err != nil {
+		return err
+	}
+	vectors := make([][]float32, 0, len(c.Faces))
+	for _, f := range c.Faces {
+		if v := faces[f]; v != nil {
+			vectors = append(vectors, v)
+		}
+	}
+	sortFaces(c.Faces)
+	c.Size = len(c.Faces)
+	c.Centroid = clustering.Centroid(vectors)
+	c.Locked = true
+	c.UpdatedAt = time.Now()
+	return nil
+}
+
+// embeddings loads the embeddings of the given faces.
+func (s *service) embeddings(ctx context.Context, refs ...domain.FaceRef) (map[domain.FaceRef][]float32, error) {
+	if len(refs) == 0 {
+		return map[domain.FaceRef][]float32{}, nil
+	}
+	var ids []domain.PhotoID
+	for _, f := range refs {
+		if !slices.Contains(ids, f.PhotoID) {
+			ids = append(ids, f.PhotoID)
+		}
+	}
+	photos, err := s.photoRepository.FindFaceEmbeddings(ctx, ids...)
+	if err != nil {
+		return nil, err
+	}
+	return faceEmbeddings(photos), nil
+}
+
+// faceEmbeddings indexes the face embeddings of the photos by face.
+func faceEmbeddings(photos []domain.Photo) map[domain.FaceRef][]float32 {
+	faces := make(map[domain.FaceRef][]float32)
+	for _, photo := range photos {
+		for i, face := range photo.Faces {
+			if len(face.Embedding) > 0 {
+				faces[domain.FaceRef{PhotoID: photo.ID, FaceIndex: i}] = face.Embedding
+			}
+		}
+	}
+	return faces
+}
+
+func sortFaces(faces []domain.FaceRef) {
+	sort.Slice(faces, func(i, j int) bool {
+		if faces[i].PhotoID != faces[j].PhotoID {
+			return faces[i].PhotoID.Compare(faces[j].PhotoID) < 0
+		}
+		return faces[i].FaceIndex < faces[j].FaceIndex
+	})
+}
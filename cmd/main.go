@@ -3,13 +3,23 @@ package main
 import (
 	"context"
 	"os"
+	"strconv"
 	"time"
 
+	"github.com/anggi-susanto/go-face-detection-be/burst"
+	"github.com/anggi-susanto/go-face-detection-be/cluster"
 	"github.com/anggi-susanto/go-face-detection-be/config"
+	"github.com/anggi-susanto/go-face-detection-be/internal/detector"
+	"github.com/anggi-susanto/go-face-detection-be/internal/embedding"
+	"github.com/anggi-susanto/go-face-detection-be/internal/index"
 	"github.com/anggi-susanto/go-face-detection-be/internal/queue"
+	"github.com/anggi-susanto/go-face-detection-be/internal/recognition"
 	mongoRepo "github.com/anggi-susanto/go-face-detection-be/internal/repository/mongo"
 	"github.com/anggi-susanto/go-face-detection-be/internal/rest"
+	"github.com/anggi-susanto/go-face-detection-be/person"
 	"github.com/anggi-susanto/go-face-detection-be/photo"
+	"github.com/anggi-susanto/go-face-detection-be/search"
+	"github.com/anggi-susanto/go-face-detection-be/verify"
 	"github.com/gofiber/swagger"
 	"github.com/sirupsen/logrus"
 
@@ -41,18 +51,97 @@ func initMongo(uri string) {
 	logrus.Println("Connected to MongoDB")
 }
 
+// getEnv reads a string environment variable, falling back to def when it is
+// unset or empty.
+func getEnv(key string, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// getEnvInt reads an integer environment variable, falling back to def when
+// it is unset or invalid.
+func getEnvInt(key string, def int) int {
+	v, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+// getEnvFloat reads a float environment variable, falling back to def when
+// it is unset or invalid.
+func getEnvFloat(key string, def float64) float64 {
+	v, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+// getEnvBool reads a boolean environment variable, falling back to def when
+// it is unset or invalid.
+func getEnvBool(key string, def bool) bool {
+	v, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return v
+}
+
 func main() {
 
 	// setup config
 	config := config.Config{
 		MongoConfig: config.MongoConfig{
-			Uri:        os.Getenv("MONGO_URI"),
-			Database:   os.Getenv("MONGO_DB"),
-			Collection: os.Getenv("MONGO_COLLECTION"),
+			Uri:                os.Getenv("MONGO_URI"),
+			Database:           os.Getenv("MONGO_DB"),
+			Collection:         os.Getenv("MONGO_COLLECTION"),
+			PeopleCollection:   getEnv("MONGO_PEOPLE_COLLECTION", "people"),
+			ClustersCollection: getEnv("MONGO_CLUSTERS_COLLECTION", "clusters"),
 		},
 		RabbitMqConfig: config.RabbitMqConfig{
 			Uri: os.Getenv("RABBITMQ_URI"),
 		},
+		DetectorConfig: config.DetectorConfig{
+			Backend:     os.Getenv("DETECTOR_BACKEND"),
+			CascadePath: os.Getenv("PIGO_CASCADE_PATH"),
+			PoolSize:    getEnvInt("PYTHON_POOL_SIZE", 4),
+			PythonPath:  os.Getenv("PYTHON_BIN"),
+			Timeout:     time.Duration(getEnvInt("DETECTOR_TIMEOUT_SECONDS", 30)) * time.Second,
+		},
+		WorkerConfig: config.WorkerConfig{
+			CropPadding:          getEnvFloat("FACE_CROP_PADDING", 0.2),
+			CropSize:             getEnvInt("FACE_CROP_SIZE", 160),
+			Concurrency:          getEnvInt("WORKER_CONCURRENCY", 4),
+			AutoOrient:           getEnvBool("PREPROCESS_AUTO_ORIENT", true),
+			MaxDimension:         getEnvInt("PREPROCESS_MAX_DIMENSION", 2048),
+			Equalize:             os.Getenv("PREPROCESS_EQUALIZE"),
+			PersonMatchThreshold: getEnvFloat("PERSON_MATCH_THRESHOLD", 0.93),
+		},
+		VerifyConfig: config.VerifyConfig{
+			Timeout: time.Duration(getEnvInt("VERIFY_TIMEOUT_MS", 5000)) * time.Millisecond,
+		},
+		ClusterConfig: config.ClusterConfig{
+			Distance: getEnvFloat("CLUSTER_DISTANCE", 0.07),
+			MinFaces: getEnvInt("CLUSTER_MIN_FACES", 2),
+			Interval: time.Duration(getEnvInt("CLUSTER_INTERVAL_MINUTES", 60)) * time.Minute,
+		},
+		ComplianceConfig: config.ComplianceConfig{
+			MinFaceRatio:        getEnvFloat("COMPLIANCE_MIN_FACE_RATIO", 0.35),
+			MaxFaceRatio:        getEnvFloat("COMPLIANCE_MAX_FACE_RATIO", 0.7),
+			MaxCenterOffset:     getEnvFloat("COMPLIANCE_MAX_CENTER_OFFSET", 0.1),
+			MaxTilt:             getEnvFloat("COMPLIANCE_MAX_TILT", 8),
+			MaxBackgroundStdDev: getEnvFloat("COMPLIANCE_MAX_BACKGROUND_STDDEV", 25),
+			MinSharpness:        getEnvFloat("COMPLIANCE_MIN_SHARPNESS", 50),
+			MinBrightness:       getEnvFloat("COMPLIANCE_MIN_BRIGHTNESS", 70),
+			MaxBrightness:       getEnvFloat("COMPLIANCE_MAX_BRIGHTNESS", 200),
+		},
+	}
+
+	if err := queue.ValidateEqualize(config.WorkerConfig.Equalize); err != nil {
+		logrus.Fatalf("Invalid PREPROCESS_EQUALIZE: %v", err)
 	}
 
 	initMongo(config.MongoConfig.Uri)
@@ -69,18 +158,74 @@ func main() {
 
 	// handler composing
 	photoRepo := mongoRepo.NewPhotoRepository(MongoClient, &config.MongoConfig)
-	photoService := photo.NewService(*photoRepo)
+	if err := photoRepo.EnsureIndexes(context.Background()); err != nil {
+		logrus.Fatalf("Failed to create photo indexes: %v", err)
+	}
+	faceDetector, err := detector.New(&config.DetectorConfig)
+	if err != nil {
+		logrus.Fatalf("Failed to create face detector: %v", err)
+	}
+	defer faceDetector.Close()
+
+	faceEmbedder := embedding.NewLBPEmbedder()
+	faceIndex := index.NewBruteForceIndex()
+	if err := search.LoadIndex(context.Background(), photoRepo, faceIndex); err != nil {
+		logrus.Fatalf("Failed to load face index: %v", err)
+	}
+	logrus.Printf("Loaded %d faces into the search index", faceIndex.Len())
+	searchService := search.NewService(*photoRepo, faceDetector, faceEmbedder, faceIndex)
+	searchHandler := rest.NewSearchHandler(searchService)
+
+	personRepo := mongoRepo.NewPersonRepository(MongoClient, &config.MongoConfig)
+	faceGallery := recognition.NewGallery()
+	if err := person.LoadGallery(context.Background(), personRepo, faceGallery); err != nil {
+		logrus.Fatalf("Failed to load enrolled people: %v", err)
+	}
+	logrus.Printf("Loaded %d enrolled people", faceGallery.Len())
+	personService := person.NewService(*personRepo, *photoRepo, faceDetector, faceEmbedder, faceGallery)
+	personHandler := rest.NewPersonHandler(personService)
+
+	verifyService := verify.NewService(*photoRepo, faceDetector, faceEmbedder, config.VerifyConfig.Timeout)
+	verifyHandler := rest.NewVerifyHandler(verifyService)
+
+	clusterRepo := mongoRepo.NewClusterRepository(MongoClient, &config.MongoConfig)
+	clusterService := cluster.NewService(*clusterRepo, *photoRepo, personService, &config.ClusterConfig)
+	clusterHandler := rest.NewClusterHandler(clusterService)
+
+	photoService := photo.NewService(*photoRepo, faceIndex, clusterService)
 	photoProducer := queue.NewProducer(&config.RabbitMqConfig)
-	photoHandler := rest.NewPhotoHandler(photoService, photoProducer)
+	photoHandler := rest.NewPhotoHandler(photoService, photoProducer, faceDetector)
+
+	burstService := burst.NewService(*photoRepo)
+	burstHandler := rest.NewBurstHandler(burstService)
 
 	// route definitions
 	app.Post("/upload", photoHandler.Upload)
 	app.Get("/result/:id", photoHandler.CheckResult)
+	app.Get("/photos", photoHandler.ListPhotos)
 	app.Get("/photo/:id", photoHandler.GetPhoto)
+	app.Delete("/photo/:id", photoHandler.DeletePhoto)
+	app.Get("/photo/:id/original", photoHandler.GetOriginalPhoto)
+	app.Get("/photo/:id/annotated", photoHandler.GetAnnotatedPhoto)
+	app.Get("/photo/:id/redacted", photoHandler.GetRedactedPhoto)
+	app.Get("/photo/:id/faces/:index", photoHandler.GetFaceCrop)
+	app.Post("/search/faces", searchHandler.SearchFaces)
+	app.Post("/people", personHandler.Enroll)
+	app.Get("/people", personHandler.ListPeople)
+	app.Get("/people/:id", personHandler.GetPerson)
+	app.Get("/people/:id/photos", personHandler.GetPersonPhotos)
+	app.Post("/verify", verifyHandler.Verify)
+	app.Get("/clusters", clusterHandler.ListClusters)
+	app.Get("/clusters/:id", clusterHandler.GetCluster)
+	app.Post("/clusters/:id/merge", clusterHandler.MergeClusters)
+	app.Post("/clusters/:id/split", clusterHandler.SplitCluster)
+	app.Post("/clusters/:id/name", clusterHandler.NameCluster)
+	app.Get("/bursts/:id", burstHandler.GetBurst)
 
 	// consumer starting up
-	consumer := queue.NewConsumer(&config.RabbitMqConfig, photoRepo)
-	consumer.ReceiveFromQueue(context.Background())
+	consumer := queue.NewConsumer(&config.RabbitMqConfig, &config.WorkerConfig, &config.ComplianceConfig, photoRepo, faceDetector, faceEmbedder, faceIndex, faceGallery, clusterService)
+	go consumer.ReceiveFromQueue(context.Background())
+	go clusterService.Run(context.Background())
 
 	app.Listen(":8080")
 }
@@ -1,16 +1,116 @@
 package config
 
+import "time"
+
 type Config struct {
 	MongoConfig    MongoConfig
 	RabbitMqConfig RabbitMqConfig
+	DetectorConfig DetectorConfig
+	WorkerConfig   WorkerConfig
+	VerifyConfig   VerifyConfig
+	ClusterConfig  ClusterConfig
+	// ComplianceConfig holds the rules of the compliance profile.
+	ComplianceConfig ComplianceConfig
 }
 
 type MongoConfig struct {
 	Uri        string
 	Database   string
 	Collection string
+	// PeopleCollection holds the enrolled people.
+	PeopleCollection string
+	// ClustersCollection holds the face clusters.
+	ClustersCollection string
 }
 
 type RabbitMqConfig struct {
 	Uri string
 }
+
+// DetectorConfig selects and configures the face detection backend used by
+// the queue consumer.
+type DetectorConfig struct {
+	// Backend is the detector implementation: "pigo" (pure Go), "python"
+	// (embedded CPython + OpenCV, requires the "python" build tag) or
+	// "python-process" (OpenCV in supervised python child processes).
+	Backend string
+	// CascadePath is the path to the pigo facefinder cascade file. Empty
+	// uses the cascade embedded in the binary.
+	CascadePath string
+	// PoolSize is the number of OpenCV classifiers (python) or child
+	// processes (python-process) kept loaded, bounding how many detections
+	// run in parallel.
+	PoolSize int
+	// PythonPath is the interpreter used by the python-process backend.
+	PythonPath string
+	// Timeout bounds a single detection call of the python-process backend.
+	Timeout time.Duration
+}
+
+// WorkerConfig tunes the post-processing the queue consumer runs on every
+// photo after detection.
+type WorkerConfig struct {
+	// CropPadding is the margin added around each face crop, as a fraction
+	// of the face size.
+	CropPadding float64
+	// CropSize is the maximum side length in pixels of each face crop.
+	CropSize int
+	// Concurrency is the number of messages processed in parallel.
+	Concurrency int
+	// AutoOrient applies the EXIF orientation before detection.
+	AutoOrient bool
+	// MaxDimension downscales photos whose longest side exceeds it before
+	// detection, 0 disables downscaling.
+	MaxDimension int
+	// Equalize is the contrast enhancement applied before detection: ""
+	// (none), "hist" (histogram equalization) or "clahe".
+	Equalize string
+	// PersonMatchThreshold is the minimum cosine similarity between a face
+	// and an enrolled person for the person to be suggested on the face.
+	// With the LBP texture embedder a match means look-alike, not same
+	// identity, so faces are never assigned automatically.
+	PersonMatchThreshold float64
+}
+
+// VerifyConfig tunes the synchronous 1:1 face verification.
+type VerifyConfig struct {
+	// Timeout is the time budget of a single verification.
+	Timeout time.Duration
+}
+
+// ClusterConfig tunes the grouping of stored faces into identity clusters.
+type ClusterConfig struct {
+	// Distance is the maximum cosine distance, 1 - similarity, between
+	// neighbouring faces of a cluster, and between a new face and the
+	// centroid of the cluster it is assigned to.
+	Distance float64
+	// MinFaces is the minimum number of faces close to each other that
+	// start a cluster.
+	MinFaces int
+	// Interval is the time between two full clustering runs, 0 disables
+	// them.
+	Interval time.Duration
+}
+
+// ComplianceConfig holds the ID photo rules checked by the compliance
+// profile. Ratios are fractions of the upright photo size.
+type ComplianceConfig struct {
+	// MinFaceRatio and MaxFaceRatio bound the face height relative to the
+	// photo height.
+	MinFaceRatio float64
+	MaxFaceRatio float64
+	// MaxCenterOffset is the maximum horizontal distance between the face
+	// center and the photo center, relative to the photo width.
+	MaxCenterOffset float64
+	// MaxTilt is the maximum head roll in degrees.
+	MaxTilt float64
+	// MaxBackgroundStdDev is the maximum standard deviation of the luma
+	// around the subject for the background to count as uniform.
+	MaxBackgroundStdDev float64
+	// MinSharpness is the minimum variance of the Laplacian of the face.
+	MinSharpness float64
+	// MinBrightness and MaxBrightness bound the mean luma of the face, from
+	// 0 to 255.
+	MinBrightness float64
+	MaxBrightness float64
+}
@@ -1,17 +1,9 @@
-// Package docs GENERATED BY THE COMMAND ABOVE; DO NOT EDIT
-// This file was generated by swaggo/swag
+// Package docs Code generated by swaggo/swag. DO NOT EDIT
 package docs
 
-import (
-	"bytes"
-	"encoding/json"
-	"strings"
-	"text/template"
+import "github.com/swaggo/swag"
 
-	"github.com/swaggo/swag"
-)
-
-var doc = `{
+const docTemplate = `{
     "schemes": {{ marshal .Schemes }},
     "swagger": "2.0",
     "info": {
@@ -23,9 +15,132 @@ var doc = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/photo/{id}": {
+        "/bursts/{id}": {
             "get": {
-                "description": "get photo",
+                "description": "rank the photos uploaded with a burst id and recommend the best frame, once every photo is processed",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Face Detection"
+                ],
+                "summary": "get burst",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "burst id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Burst"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/clusters": {
+            "get": {
+                "description": "list every face cluster, largest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Clusters"
+                ],
+                "summary": "list clusters",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Cluster"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/clusters/{id}": {
+            "get": {
+                "description": "get a face cluster with all its faces",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Clusters"
+                ],
+                "summary": "get cluster",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "cluster id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Cluster"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/clusters/{id}/merge": {
+            "post": {
+                "description": "move every face of the given clusters into the cluster and delete them",
                 "consumes": [
                     "application/json"
                 ],
@@ -33,23 +148,44 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Face Detection"
+                    "Clusters"
                 ],
-                "summary": "get photo",
+                "summary": "merge clusters",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "photo id",
+                        "description": "cluster id",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "clusters to merge",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.ClusterMergeRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Photo"
+                            "$ref": "#/definitions/domain.Cluster"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
                         }
                     },
                     "500": {
@@ -61,9 +197,9 @@ var doc = `{
                 }
             }
         },
-        "/result/{id}": {
-            "get": {
-                "description": "check photo result",
+        "/clusters/{id}/name": {
+            "post": {
+                "description": "enroll the cluster as a named person and assign its faces to them",
                 "consumes": [
                     "application/json"
                 ],
@@ -71,23 +207,44 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Face Detection"
+                    "Clusters"
                 ],
-                "summary": "check photo result",
+                "summary": "name cluster",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "photo id",
+                        "description": "cluster id",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "person name",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.PersonRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Photo"
+                            "$ref": "#/definitions/domain.Cluster"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
                         }
                     },
                     "500": {
@@ -99,9 +256,9 @@ var doc = `{
                 }
             }
         },
-        "/upload": {
+        "/clusters/{id}/split": {
             "post": {
-                "description": "upload image for face detection",
+                "description": "move the given faces out of the cluster into a new cluster",
                 "consumes": [
                     "application/json"
                 ],
@@ -109,17 +266,24 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Face Detection"
+                    "Clusters"
                 ],
-                "summary": "upload image for face detection",
+                "summary": "split cluster",
                 "parameters": [
                     {
-                        "description": "photp data",
-                        "name": "photo",
+                        "type": "string",
+                        "description": "cluster id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "faces to move",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.Photo"
+                            "$ref": "#/definitions/domain.ClusterSplitRequest"
                         }
                     }
                 ],
@@ -127,7 +291,7 @@ var doc = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/domain.Photo"
+                            "$ref": "#/definitions/domain.Cluster"
                         }
                     },
                     "400": {
@@ -136,6 +300,12 @@ var doc = `{
                             "$ref": "#/definitions/rest.ResponseError"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -144,90 +314,1947 @@ var doc = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "domain.Photo": {
-            "type": "object",
-            "properties": {
-                "faces_detected": {
-                    "type": "integer"
-                },
-                "id": {
-                    "type": "integer"
-                },
-                "photo_url": {
-                    "type": "string"
-                },
-                "status": {
-                    "type": "string"
-                },
-                "timestamp": {
-                    "type": "string"
-                }
-            }
         },
-        "rest.ResponseError": {
-            "type": "object",
-            "properties": {
-                "message": {
-                    "type": "string"
+        "/people": {
+            "get": {
+                "description": "list every enrolled person",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "People"
+                ],
+                "summary": "list people",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Person"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "enroll a named person from one or more reference photos, new faces looking like them get the person as a person_suggestion with its look-alike score. Matching uses an LBP texture descriptor, not a face recognition model, so suggestions are not identifications and faces are never assigned automatically.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "People"
+                ],
+                "summary": "enroll a person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "person name",
+                        "name": "name",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "reference photos, the largest face of each is used",
+                        "name": "photos",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Person"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
                 }
             }
-        }
-    }
-}`
-
-type swaggerInfo struct {
-	Version     string
-	Host        string
-	BasePath    string
-	Schemes     []string
-	Title       string
-	Description string
-}
-
-// SwaggerInfo holds exported Swagger Info so clients can modify it
-var SwaggerInfo = swaggerInfo{
-	Version:     "",
-	Host:        "",
-	BasePath:    "",
-	Schemes:     []string{},
-	Title:       "",
-	Description: "",
-}
-
-type s struct{}
-
-func (s *s) ReadDoc() string {
-	sInfo := SwaggerInfo
-	sInfo.Description = strings.Replace(sInfo.Description, "\n", "\\n", -1)
-
-	t, err := template.New("swagger_info").Funcs(template.FuncMap{
-		"marshal": func(v interface{}) string {
-			a, _ := json.Marshal(v)
-			return string(a)
-		},
-		"escape": func(v interface{}) string {
-			// escape tabs
-			str := strings.Replace(v.(string), "\t", "\\t", -1)
-			// replace " with \", and if that results in \\", replace that with \\\"
-			str = strings.Replace(str, "\"", "\\\"", -1)
-			return strings.Replace(str, "\\\\\"", "\\\\\\\"", -1)
-		},
-	}).Parse(doc)
-	if err != nil {
-		return doc
-	}
-
-	var tpl bytes.Buffer
-	if err := t.Execute(&tpl, sInfo); err != nil {
-		return doc
-	}
+        },
+        "/people/{id}": {
+            "get": {
+                "description": "get an enrolled person",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "People"
+                ],
+                "summary": "get person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "person id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Person"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/people/{id}/photos": {
+            "get": {
+                "description": "list every photo with a face assigned to the person by naming a cluster, or suggested as the person because it looks alike, newest first. Suggestions carry a look-alike score and are not identity matches.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "People"
+                ],
+                "summary": "list photos of a person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "person id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "only list photos where the person's face is at least this sharp",
+                        "name": "min_sharpness",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "only list photos where the person's face is at least this bright, 0 to 255",
+                        "name": "min_brightness",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "only list photos where the person's face is at most this bright, 0 to 255",
+                        "name": "max_brightness",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "only list photos where the person's face has at least this luma standard deviation",
+                        "name": "min_contrast",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "only list photos where the smaller side of the person's face is at least this many pixels",
+                        "name": "min_face_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "only list photos where the person's face has no occlusion hints",
+                        "name": "unoccluded",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Photo"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/photo/{id}": {
+            "get": {
+                "description": "get photo",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Face Detection"
+                ],
+                "summary": "get photo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "photo id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Photo"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "delete the photo with its original, processed, redacted and face crop files, and drop it from search and clusters; a job still queued for it is skipped",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Face Detection"
+                ],
+                "summary": "delete photo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "photo id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.PhotoDeletion"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/photo/{id}/annotated": {
+            "get": {
+                "description": "stream the processed photo with the detected faces outlined, with ETag and Last-Modified validators, conditional GET and Range support",
+                "produces": [
+                    "image/jpeg",
+                    "image/png"
+                ],
+                "tags": [
+                    "Face Detection"
+                ],
+                "summary": "get annotated photo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "photo id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "byte range",
+                        "name": "Range",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a cached copy",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last-Modified of a cached copy",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "206": {
+                        "description": "Partial Content",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "304": {
+                        "description": "not modified"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "416": {
+                        "description": "range not satisfiable"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/photo/{id}/faces/{index}": {
+            "get": {
+                "description": "stream the thumbnail of a single detected face, with ETag and Last-Modified validators, conditional GET and Range support",
+                "produces": [
+                    "image/jpeg"
+                ],
+                "tags": [
+                    "Face Detection"
+                ],
+                "summary": "get face crop",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "photo id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "face index in the photo result",
+                        "name": "index",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "byte range",
+                        "name": "Range",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a cached copy",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last-Modified of a cached copy",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "206": {
+                        "description": "Partial Content",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "304": {
+                        "description": "not modified"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "416": {
+                        "description": "range not satisfiable"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/photo/{id}/original": {
+            "get": {
+                "description": "stream the uploaded photo, with ETag and Last-Modified validators, conditional GET and Range support",
+                "produces": [
+                    "image/jpeg",
+                    "image/png"
+                ],
+                "tags": [
+                    "Face Detection"
+                ],
+                "summary": "get original photo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "photo id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "byte range",
+                        "name": "Range",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a cached copy",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last-Modified of a cached copy",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "206": {
+                        "description": "Partial Content",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "304": {
+                        "description": "not modified"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "416": {
+                        "description": "range not satisfiable"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/photo/{id}/redacted": {
+            "get": {
+                "description": "stream the photo variant with every detected face obscured, with ETag and Last-Modified validators, conditional GET and Range support",
+                "produces": [
+                    "image/jpeg",
+                    "image/png"
+                ],
+                "tags": [
+                    "Face Detection"
+                ],
+                "summary": "get redacted photo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "photo id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "byte range",
+                        "name": "Range",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a cached copy",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last-Modified of a cached copy",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "206": {
+                        "description": "Partial Content",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "304": {
+                        "description": "not modified"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "416": {
+                        "description": "range not satisfiable"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/photos": {
+            "get": {
+                "description": "list photos page by page, newest first unless sorted otherwise, pass next_cursor as cursor to fetch the next page",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Face Detection"
+                ],
+                "summary": "list photos",
+                "parameters": [
+                    {
+                        "enum": [
+                            "pending",
+                            "processed",
+                            "error"
+                        ],
+                        "type": "string",
+                        "description": "job status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "uploaded at or after this RFC 3339 time",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "uploaded before this RFC 3339 time",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "minimum number of detected faces",
+                        "name": "min_faces",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "maximum number of detected faces",
+                        "name": "max_faces",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "upload tag",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "pigo",
+                            "python",
+                            "python-process"
+                        ],
+                        "type": "string",
+                        "description": "detector backend that processed the photo",
+                        "name": "detector",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "only list photos with a face at least this sharp",
+                        "name": "min_sharpness",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "only list photos with a face at least this bright, 0 to 255",
+                        "name": "min_brightness",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "only list photos with a face at most this bright, 0 to 255",
+                        "name": "max_brightness",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "only list photos with a face with at least this luma standard deviation",
+                        "name": "min_contrast",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "only list photos with a face whose smaller side is at least this many pixels",
+                        "name": "min_face_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "only list photos with a face without occlusion hints",
+                        "name": "unoccluded",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "timestamp",
+                            "faces_detected"
+                        ],
+                        "type": "string",
+                        "default": "timestamp",
+                        "description": "sort field",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "default": "desc",
+                        "description": "sort order",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "page size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.PhotoPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/result/{id}": {
+            "get": {
+                "description": "check photo result",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Face Detection"
+                ],
+                "summary": "check photo result",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "photo id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Photo"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/search/faces": {
+            "post": {
+                "description": "find the stored faces most similar to the largest face of an uploaded probe image, or to an existing face. Similarity comes from an LBP texture descriptor, not a face recognition model: it ranks look-alike faces and is not an identity match.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Face Search"
+                ],
+                "summary": "search similar faces",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "probe image, required unless photo_id is set",
+                        "name": "photo",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "photo of an existing probe face",
+                        "name": "photo_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "index of the probe face in the photo result",
+                        "name": "face_index",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "number of matches to return",
+                        "name": "k",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "number",
+                        "description": "only match faces at least this sharp",
+                        "name": "min_sharpness",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "number",
+                        "description": "only match faces at least this bright, 0 to 255",
+                        "name": "min_brightness",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "number",
+                        "description": "only match faces at most this bright, 0 to 255",
+                        "name": "max_brightness",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "number",
+                        "description": "only match faces with at least this luma standard deviation",
+                        "name": "min_contrast",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "only match faces whose smaller side is at least this many pixels",
+                        "name": "min_face_size",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "only match faces without occlusion hints",
+                        "name": "unoccluded",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.FaceSearchResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/upload": {
+            "post": {
+                "description": "queue a photo for face detection and return the job, poll its result link until the status is no longer pending",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Face Detection"
+                ],
+                "summary": "upload image for face detection",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "photo to scan, JPEG or PNG",
+                        "name": "photo",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "default": 1.1,
+                        "description": "cascade scale factor, greater than 1",
+                        "name": "scale_factor",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 4,
+                        "description": "minimum neighbouring detections per face",
+                        "name": "min_neighbors",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "minimum face size in pixels",
+                        "name": "min_size",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "maximum face size in pixels",
+                        "name": "max_size",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "scan the full resolution image in overlapping tiles, for large or crowded photos",
+                        "name": "tiled",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1024,
+                        "description": "tile side length in pixels",
+                        "name": "tile_size",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "number",
+                        "default": 0.25,
+                        "description": "fraction of a tile shared with its neighbours",
+                        "name": "tile_overlap",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "on_empty",
+                            "exhaustive"
+                        ],
+                        "type": "string",
+                        "description": "also scan rotated copies of the photo, when nothing is found or always",
+                        "name": "rotation_retry",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "include -15 and 15 degree tilts in rotation retries",
+                        "name": "rotation_tilt",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "number",
+                        "description": "drop faces whose estimated yaw exceeds this many degrees, 0 keeps every face",
+                        "name": "max_yaw",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "enum": [
+                                "frontal_default",
+                                "frontal_alt",
+                                "frontal_alt2",
+                                "profile",
+                                "eyes",
+                                "upper_body",
+                                "smile"
+                            ],
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "cascade models to run, the pigo backend only runs the frontal models; eyes, upper_body and smile detections are returned as regions, not faces",
+                        "name": "models",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "compliance"
+                        ],
+                        "type": "string",
+                        "description": "add a use case specific report to the result",
+                        "name": "profile",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "blur",
+                            "pixelate",
+                            "box"
+                        ],
+                        "type": "string",
+                        "description": "write a redacted variant instead of the annotated one",
+                        "name": "redact",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "delete the original once redacted, or when processing fails, requires redact",
+                        "name": "discard_original",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "group the photo with the other photos of a burst for best-shot selection",
+                        "name": "burst_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "labels to filter photo listings by",
+                        "name": "tags",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/domain.PhotoJob"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "status URL of the job"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/verify": {
+            "post": {
+                "description": "synchronously score how much two faces look alike. Each side is an uploaded image, whose largest face is used, or a face of a processed photo. look_alike_score comes from an LBP texture descriptor, not a face recognition model: it is not an identity decision and must not be used as one, such as matching a selfie to an ID document.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Face Search"
+                ],
+                "summary": "score how much two faces look alike",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "first image, required unless photo_id_a is set",
+                        "name": "photo_a",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "photo of the first face",
+                        "name": "photo_id_a",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "index of the first face in its photo result",
+                        "name": "face_index_a",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "file",
+                        "description": "second image, required unless photo_id_b is set",
+                        "name": "photo_b",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "photo of the second face",
+                        "name": "photo_id_b",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "index of the second face in its photo result",
+                        "name": "face_index_b",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.VerifyResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ResponseError"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "domain.Box": {
+            "type": "object",
+            "properties": {
+                "height": {
+                    "type": "integer"
+                },
+                "width": {
+                    "type": "integer"
+                },
+                "x": {
+                    "type": "integer"
+                },
+                "y": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.Burst": {
+            "type": "object",
+            "properties": {
+                "best_photo_id": {
+                    "description": "BestPhotoID is the recommended frame, set once the burst is processed\nand at least one photo succeeded.",
+                    "type": "string"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "frames": {
+                    "description": "Frames are the processed photos, best first. Empty while pending.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.BurstFrame"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "photos": {
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "Status is \"pending\" while any photo of the burst is still queued, and\n\"processed\" once every photo was processed or failed.",
+                    "type": "string"
+                }
+            }
+        },
+        "domain.BurstFrame": {
+            "type": "object",
+            "properties": {
+                "eyes_open": {
+                    "description": "EyesOpen is the fraction of faces whose eyes were found open. Faces\nwithout an eyes open cue are unknown and do not count as open. Empty\nwhen no face of the frame has the cue, the frame then gets no eyes\nopen credit.",
+                    "type": "number"
+                },
+                "face_size": {
+                    "description": "FaceSize is the mean face area relative to the frame with the largest\nfaces.",
+                    "type": "number"
+                },
+                "faces": {
+                    "description": "Faces is the face count relative to the frame with the most faces.",
+                    "type": "number"
+                },
+                "faces_detected": {
+                    "type": "integer"
+                },
+                "photo_id": {
+                    "type": "string"
+                },
+                "score": {
+                    "type": "number"
+                },
+                "sharpness": {
+                    "description": "Sharpness is the mean face sharpness relative to the sharpest frame.",
+                    "type": "number"
+                }
+            }
+        },
+        "domain.Cluster": {
+            "type": "object",
+            "properties": {
+                "faces": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.FaceRef"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "locked": {
+                    "description": "Locked clusters were merged, split or named by a user and are kept as\nthey are when the collection is clustered again.",
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "person_id": {
+                    "description": "PersonID and Name are set once the cluster is named.",
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ClusterMergeRequest": {
+            "type": "object",
+            "properties": {
+                "cluster_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "domain.ClusterSplitRequest": {
+            "type": "object",
+            "properties": {
+                "faces": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.FaceRef"
+                    }
+                }
+            }
+        },
+        "domain.ComplianceReport": {
+            "type": "object",
+            "properties": {
+                "passed": {
+                    "description": "Passed is true when every rule passed.",
+                    "type": "boolean"
+                },
+                "rules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ComplianceRule"
+                    }
+                }
+            }
+        },
+        "domain.ComplianceRule": {
+            "type": "object",
+            "properties": {
+                "max": {
+                    "type": "number"
+                },
+                "message": {
+                    "description": "Message explains why a rule could not be measured.",
+                    "type": "string"
+                },
+                "min": {
+                    "type": "number"
+                },
+                "passed": {
+                    "type": "boolean"
+                },
+                "rule": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "number"
+                }
+            }
+        },
+        "domain.DetectionParams": {
+            "type": "object",
+            "properties": {
+                "max_size": {
+                    "type": "integer"
+                },
+                "max_yaw": {
+                    "description": "MaxYaw drops faces whose estimated yaw exceeds it in degrees from the\nresults, such as extreme profile shots. Zero keeps every face.\nDropped faces are still redacted.",
+                    "type": "number"
+                },
+                "min_neighbors": {
+                    "type": "integer"
+                },
+                "min_size": {
+                    "type": "integer"
+                },
+                "models": {
+                    "description": "Models are the cascades to run, ModelFrontalDefault when empty.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "profile": {
+                    "description": "Profile adds a use case specific report to the result, empty for none.",
+                    "type": "string"
+                },
+                "rotation_retry": {
+                    "description": "RotationRetry also tries the image rotated by 90, 180 and 270\ndegrees, and by -15 and 15 degrees when RotationTilt is set, keeping\nthe orientation with the most detections. Empty disables retries.",
+                    "type": "string"
+                },
+                "rotation_tilt": {
+                    "type": "boolean"
+                },
+                "scale_factor": {
+                    "type": "number"
+                },
+                "tile_overlap": {
+                    "type": "number"
+                },
+                "tile_size": {
+                    "type": "integer"
+                },
+                "tiled": {
+                    "description": "Tiled scans the full resolution image in overlapping square tiles of\nTileSize pixels instead of a single downscaled pass. TileOverlap is\nthe fraction of a tile shared with its neighbours.",
+                    "type": "boolean"
+                }
+            }
+        },
+        "domain.ExpressionSummary": {
+            "type": "object",
+            "properties": {
+                "all_eyes_open": {
+                    "description": "AllEyesOpen is false when any face has its eyes closed.",
+                    "type": "boolean"
+                },
+                "everyone_smiling": {
+                    "description": "EveryoneSmiling is true when every face is smiling.",
+                    "type": "boolean"
+                },
+                "eyes_closed": {
+                    "type": "integer"
+                },
+                "faces": {
+                    "description": "Faces is the number of faces the cues were estimated for.",
+                    "type": "integer"
+                },
+                "smiling": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.Face": {
+            "type": "object",
+            "properties": {
+                "box": {
+                    "$ref": "#/definitions/domain.Box"
+                },
+                "confidence": {
+                    "description": "Confidence is the detector score of the face. Its scale depends on the\ndetector backend, higher is more confident.",
+                    "type": "number"
+                },
+                "crop_url": {
+                    "description": "CropURL serves the thumbnail, only set in API responses.",
+                    "type": "string"
+                },
+                "eyes_open": {
+                    "description": "EyesOpen and Smiling are estimated with the eye and smile cascades\ninside the box, with a confidence in the [0.5, 1] range. They are empty\nwhen the detector backend cannot run those cascades.",
+                    "type": "boolean"
+                },
+                "eyes_open_confidence": {
+                    "type": "number"
+                },
+                "landmarks": {
+                    "description": "Landmarks and Pose are estimated from the face image inside the box.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.Landmarks"
+                        }
+                    ]
+                },
+                "model": {
+                    "description": "Model is the cascade model that produced the detection.",
+                    "type": "string"
+                },
+                "normalized_box": {
+                    "$ref": "#/definitions/domain.NormalizedBox"
+                },
+                "person_id": {
+                    "description": "PersonID is the enrolled person the face was assigned to by naming\nits cluster, with its look-alike score to the person's references in\nPersonScore. Processing never sets them, see PersonSuggestion.",
+                    "type": "string"
+                },
+                "person_score": {
+                    "type": "number"
+                },
+                "person_suggestion": {
+                    "description": "PersonSuggestion is the enrolled person the face looks most alike,\nset during processing when the look-alike score reaches the match\nthreshold. It is a hint to review, not an identification.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.PersonSuggestion"
+                        }
+                    ]
+                },
+                "pose": {
+                    "$ref": "#/definitions/domain.HeadPose"
+                },
+                "quality": {
+                    "description": "Quality holds image quality metrics measured inside the box.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.FaceQuality"
+                        }
+                    ]
+                },
+                "smiling": {
+                    "type": "boolean"
+                },
+                "smiling_confidence": {
+                    "type": "number"
+                }
+            }
+        },
+        "domain.FaceMatch": {
+            "type": "object",
+            "properties": {
+                "face_index": {
+                    "type": "integer"
+                },
+                "photo_id": {
+                    "type": "string"
+                },
+                "score": {
+                    "description": "Score is the cosine similarity to the probe face, 1 for identical\nfaces.",
+                    "type": "number"
+                }
+            }
+        },
+        "domain.FaceQuality": {
+            "type": "object",
+            "properties": {
+                "brightness": {
+                    "description": "Brightness and Contrast are the mean and the standard deviation of the\nluma, from 0 to 255.",
+                    "type": "number"
+                },
+                "contrast": {
+                    "type": "number"
+                },
+                "occlusion": {
+                    "description": "Occlusion lists the parts of the face that look covered or cut off.\nThese are heuristic hints, not a detection.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "sharpness": {
+                    "description": "Sharpness is the variance of the Laplacian, higher is sharper.",
+                    "type": "number"
+                },
+                "size": {
+                    "description": "Size is the smaller side of the box in pixels.",
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.FaceRef": {
+            "type": "object",
+            "properties": {
+                "face_index": {
+                    "type": "integer"
+                },
+                "photo_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.FaceSearchResult": {
+            "type": "object",
+            "properties": {
+                "matches": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.FaceMatch"
+                    }
+                }
+            }
+        },
+        "domain.HeadPose": {
+            "type": "object",
+            "properties": {
+                "pitch": {
+                    "type": "number"
+                },
+                "roll": {
+                    "type": "number"
+                },
+                "yaw": {
+                    "type": "number"
+                }
+            }
+        },
+        "domain.Landmarks": {
+            "type": "object",
+            "properties": {
+                "left_eye": {
+                    "$ref": "#/definitions/domain.Point"
+                },
+                "mouth_left": {
+                    "$ref": "#/definitions/domain.Point"
+                },
+                "mouth_right": {
+                    "$ref": "#/definitions/domain.Point"
+                },
+                "nose_tip": {
+                    "$ref": "#/definitions/domain.Point"
+                },
+                "right_eye": {
+                    "$ref": "#/definitions/domain.Point"
+                }
+            }
+        },
+        "domain.NormalizedBox": {
+            "type": "object",
+            "properties": {
+                "height": {
+                    "type": "number"
+                },
+                "width": {
+                    "type": "number"
+                },
+                "x": {
+                    "type": "number"
+                },
+                "y": {
+                    "type": "number"
+                }
+            }
+        },
+        "domain.Person": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "references": {
+                    "description": "References is the number of reference faces the person was enrolled\nfrom, either uploaded photos or faces of a named cluster. Only their\nembeddings are kept.",
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.PersonRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.PersonSuggestion": {
+            "type": "object",
+            "properties": {
+                "person_id": {
+                    "type": "string"
+                },
+                "score": {
+                    "description": "Score is the cosine similarity of the face to the closest reference\nface of the person.",
+                    "type": "number"
+                }
+            }
+        },
+        "domain.Photo": {
+            "type": "object",
+            "properties": {
+                "annotated_url": {
+                    "type": "string"
+                },
+                "burst_id": {
+                    "description": "BurstID groups photos of the same burst for best-shot selection.",
+                    "type": "string"
+                },
+                "compliance": {
+                    "description": "Compliance is the report of the compliance profile, empty for other\nprofiles.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ComplianceReport"
+                        }
+                    ]
+                },
+                "detector": {
+                    "description": "Detector is the detector backend that processed the photo.",
+                    "type": "string"
+                },
+                "expressions": {
+                    "description": "Expressions summarizes the eyes open and smile cues of the faces.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ExpressionSummary"
+                        }
+                    ]
+                },
+                "expressions_unavailable": {
+                    "description": "ExpressionsUnavailable tells why no face has eyes open and smile cues,\nsuch as a detector backend without the eye and smile cascades. Empty\nwhen the cues were estimated.",
+                    "type": "string"
+                },
+                "faces": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Face"
+                    }
+                },
+                "faces_detected": {
+                    "type": "integer"
+                },
+                "height": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "orientation": {
+                    "description": "Orientation is the EXIF orientation of the upload. Face coordinates\nare in the stored pixel grid, before this orientation is applied.",
+                    "type": "integer"
+                },
+                "params": {
+                    "$ref": "#/definitions/domain.DetectionParams"
+                },
+                "photo_url": {
+                    "description": "PhotoURL, AnnotatedURL and RedactedURL serve the original, the copy\nwith the faces outlined and the redacted copy. They are only set in API\nresponses, for the files the photo has.",
+                    "type": "string"
+                },
+                "redacted_url": {
+                    "type": "string"
+                },
+                "redaction": {
+                    "$ref": "#/definitions/domain.RedactionOptions"
+                },
+                "regions": {
+                    "description": "Regions are the detections of the models that do not find faces,\nsuch as eyes or upper bodies. They are not counted, cropped or\nsearched as faces.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Region"
+                    }
+                },
+                "rotation": {
+                    "description": "Rotation is the clockwise rotation in degrees, applied on top of\nOrientation, under which the faces were found.",
+                    "type": "number"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "tags": {
+                    "description": "Tags are client labels used to filter photo listings.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "timestamp": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "description": "UpdatedAt is the time of the last status change.",
+                    "type": "string"
+                },
+                "width": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.PhotoDeletion": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "leftover": {
+                    "description": "Leftover lists the files that could not be removed once the photo was\ndeleted, they need to be cleaned up by hand.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "removed": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "domain.PhotoJob": {
+            "type": "object",
+            "properties": {
+                "burst_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "links": {
+                    "$ref": "#/definitions/domain.PhotoLinks"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.PhotoLinks": {
+            "type": "object",
+            "properties": {
+                "burst": {
+                    "type": "string"
+                },
+                "photo": {
+                    "type": "string"
+                },
+                "result": {
+                    "description": "Result is the status URL, polled until the job is processed.",
+                    "type": "string"
+                }
+            }
+        },
+        "domain.PhotoPage": {
+            "type": "object",
+            "properties": {
+                "next_cursor": {
+                    "description": "NextCursor fetches the next page, empty on the last page.",
+                    "type": "string"
+                },
+                "photos": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Photo"
+                    }
+                }
+            }
+        },
+        "domain.Point": {
+            "type": "object",
+            "properties": {
+                "x": {
+                    "type": "integer"
+                },
+                "y": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.RedactionOptions": {
+            "type": "object",
+            "properties": {
+                "discard_original": {
+                    "description": "DiscardOriginal removes the uploaded original once the redacted\nvariant has been written, or when processing fails.",
+                    "type": "boolean"
+                },
+                "method": {
+                    "description": "Method is one of RedactBlur, RedactPixelate or RedactBox. Empty\ndisables redaction.",
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Region": {
+            "type": "object",
+            "properties": {
+                "box": {
+                    "$ref": "#/definitions/domain.Box"
+                },
+                "confidence": {
+                    "type": "number"
+                },
+                "model": {
+                    "description": "Model is the cascade model that produced the detection.",
+                    "type": "string"
+                },
+                "normalized_box": {
+                    "$ref": "#/definitions/domain.NormalizedBox"
+                }
+            }
+        },
+        "domain.VerifyResult": {
+            "type": "object",
+            "properties": {
+                "look_alike_score": {
+                    "description": "LookAlikeScore is the cosine similarity of the two face descriptors.",
+                    "type": "number"
+                }
+            }
+        },
+        "rest.ResponseError": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        }
+    }
+}`
 
-	return tpl.String()
+// SwaggerInfo holds exported Swagger Info so clients can modify it
+var SwaggerInfo = &swag.Spec{
+	Version:          "",
+	Host:             "",
+	BasePath:         "",
+	Schemes:          []string{},
+	Title:            "",
+	Description:      "",
+	InfoInstanceName: "swagger",
+	SwaggerTemplate:  docTemplate,
+	LeftDelim:        "{{",
+	RightDelim:       "}}",
 }
 
 func init() {
-	swag.Register("swagger", &s{})
+	swag.Register(SwaggerInfo.InstanceName(), SwaggerInfo)
 }
@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"errors"
+	"regexp"
+)
+
+// MaxBurstIDLength bounds the length of a client supplied burst ID.
+const MaxBurstIDLength = 64
+
+var burstIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]*$`)
+
+// ValidateBurstID checks that a burst ID is at most MaxBurstIDLength letters,
+// digits, dashes or underscores. An empty ID uploads a single photo.
+//
+// Returns:
+// - error: An error object if the ID is invalid, otherwise nil.
+func ValidateBurstID(id string) error {
+	if len(id) > MaxBurstIDLength || !burstIDPattern.MatchString(id) {
+		return errors.New("burst_id must be at most 64 letters, digits, dashes or underscores")
+	}
+	return nil
+}
+
+// Burst is the best-shot ranking of a group of photos uploaded with the same
+// burst ID.
+type Burst struct {
+	ID string `json:"id"`
+	// Status is "pending" while any photo of the burst is still queued, and
+	// "processed" once every photo was processed or failed.
+	Status string `json:"status"`
+	Photos int    `json:"photos"`
+	Failed int    `json:"failed"`
+	// BestPhotoID is the recommended frame, set once the burst is processed
+	// and at least one photo succeeded.
+	BestPhotoID *PhotoID `json:"best_photo_id,omitempty" swaggertype:"string"`
+	// Frames are the processed photos, best first. Empty while pending.
+	Frames []BurstFrame `json:"frames"`
+}
+
+// BurstFrame is the ranking of a single photo of a burst. Every criterion is
+// relative to the best frame of the burst, in the [0, 1] range.
+type BurstFrame struct {
+	PhotoID       PhotoID `json:"photo_id" swaggertype:"string"`
+	Score         float64 `json:"score"`
+	FacesDetected int     `json:"faces_detected"`
+	// Faces is the face count relative to the frame with the most faces.
+	Faces float64 `json:"faces"`
+	// Sharpness is the mean face sharpness relative to the sharpest frame.
+	Sharpness float64 `json:"sharpness"`
+	// EyesOpen is the fraction of faces whose eyes were found open. Faces
+	// without an eyes open cue are unknown and do not count as open. Empty
+	// when no face of the frame has the cue, the frame then gets no eyes
+	// open credit.
+	EyesOpen *float64 `json:"eyes_open,omitempty"`
+	// FaceSize is the mean face area relative to the frame with the largest
+	// faces.
+	FaceSize float64 `json:"face_size"`
+}
@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// FaceRef identifies a face by its photo and its index in the photo result.
+type FaceRef struct {
+	PhotoID   PhotoID `json:"photo_id" bson:"photo_id" swaggertype:"string"`
+	FaceIndex int     `json:"face_index" bson:"face_index"`
+}
+
+// Cluster is a group of faces that likely show the same person.
+type Cluster struct {
+	ID    ClusterID `json:"id" bson:"_id" swaggertype:"string"`
+	Faces []FaceRef `json:"faces" bson:"faces"`
+	Size  int       `json:"size" bson:"size"`
+	// Locked clusters were merged, split or named by a user and are kept as
+	// they are when the collection is clustered again.
+	Locked bool `json:"locked" bson:"locked"`
+	// PersonID and Name are set once the cluster is named.
+	PersonID  *PersonID `json:"person_id,omitempty" bson:"person_id,omitempty" swaggertype:"string"`
+	Name      string    `json:"name,omitempty" bson:"name,omitempty"`
+	Centroid  []float32 `json:"-" bson:"centroid"`
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+// ClusterMergeRequest lists the clusters merged into another one.
+type ClusterMergeRequest struct {
+	ClusterIDs []ClusterID `json:"cluster_ids" swaggertype:"array,string"`
+}
+
+// Validate checks that at least one cluster is merged.
+//
+// Returns:
+// - error: An error object if the request is invalid, otherwise nil.
+func (r ClusterMergeRequest) Validate() error {
+	if len(r.ClusterIDs) == 0 {
+		return errors.New("cluster_ids must not be empty")
+	}
+	return nil
+}
+
+// ClusterSplitRequest lists the faces moved out of a cluster into a new one.
+type ClusterSplitRequest struct {
+	Faces []FaceRef `json:"faces"`
+}
+
+// Validate checks that at least one face is moved.
+//
+// Returns:
+// - error: An error object if the request is invalid, otherwise nil.
+func (r ClusterSplitRequest) Validate() error {
+	if len(r.Faces) == 0 {
+		return errors.New("faces must not be empty")
+	}
+	for _, f := range r.Faces {
+		if f.FaceIndex < 0 {
+			return fmt.Errorf("face_index of photo %s must not be negative", f.PhotoID)
+		}
+	}
+	return nil
+}
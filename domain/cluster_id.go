@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrInvalidClusterID is returned when a cluster ID cannot be parsed.
+var ErrInvalidClusterID = errors.New("invalid cluster id")
+
+// ClusterID identifies a face cluster. Like PhotoID it is a Mongo ObjectID,
+// unique across replicas and exchanged as 24 hex characters, which JSON
+// clients keep exact unlike large integers.
+type ClusterID primitive.ObjectID
+
+// NewClusterID generates the ID of a new cluster.
+//
+// Returns:
+// - ClusterID: A unique ID.
+func NewClusterID() ClusterID {
+	return ClusterID(primitive.NewObjectID())
+}
+
+// ParseClusterID parses the hex form of a cluster ID.
+//
+// Parameters:
+// - s: The 24 hex characters of the ID.
+//
+// Returns:
+// - ClusterID: The parsed ID.
+// - error: ErrInvalidClusterID if s is not a cluster ID, otherwise nil.
+func ParseClusterID(s string) (ClusterID, error) {
+	return parseObjectID[ClusterID](s, ErrInvalidClusterID)
+}
+
+// String returns the hex form of the ID.
+func (id ClusterID) String() string {
+	return objectIDHex(id)
+}
+
+// IsZero reports whether the ID is unset.
+func (id ClusterID) IsZero() bool {
+	return id == ClusterID{}
+}
+
+// MarshalText encodes the ID as hex, which is also its JSON form.
+func (id ClusterID) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+// UnmarshalText decodes the hex form of the ID.
+func (id *ClusterID) UnmarshalText(text []byte) error {
+	return unmarshalObjectIDText(id, text, ErrInvalidClusterID)
+}
+
+// MarshalBSONValue stores the ID as an ObjectID.
+func (id ClusterID) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	return marshalObjectIDBSON(id)
+}
+
+// UnmarshalBSONValue decodes an ObjectID.
+func (id *ClusterID) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	return unmarshalObjectIDBSON(id, t, data)
+}
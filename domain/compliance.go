@@ -0,0 +1,32 @@
+package domain
+
+// Compliance rules checked by the compliance profile.
+const (
+	RuleOneFace           = "one_face"
+	RuleFaceHeight        = "face_height"
+	RuleCentered          = "centered"
+	RuleTilt              = "tilt"
+	RuleUniformBackground = "uniform_background"
+	RuleSharpness         = "sharpness"
+	RuleBrightness        = "brightness"
+)
+
+// ComplianceReport is the outcome of the compliance profile. Rules about
+// the face are checked on the largest face.
+type ComplianceReport struct {
+	// Passed is true when every rule passed.
+	Passed bool             `json:"passed" bson:"passed"`
+	Rules  []ComplianceRule `json:"rules" bson:"rules"`
+}
+
+// ComplianceRule is the result of a single rule: the measured value and the
+// bounds it was checked against.
+type ComplianceRule struct {
+	Rule   string   `json:"rule" bson:"rule"`
+	Passed bool     `json:"passed" bson:"passed"`
+	Value  float64  `json:"value" bson:"value"`
+	Min    *float64 `json:"min,omitempty" bson:"min,omitempty"`
+	Max    *float64 `json:"max,omitempty" bson:"max,omitempty"`
+	// Message explains why a rule could not be measured.
+	Message string `json:"message,omitempty" bson:"message,omitempty"`
+}
@@ -0,0 +1,178 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Detection parameter defaults and limits.
+const (
+	DefaultScaleFactor  = 1.1
+	DefaultMinNeighbors = 4
+	MaxScaleFactor      = 2.0
+	MaxMinNeighbors     = 50
+	MaxFaceSize         = 10000
+	DefaultTileSize     = 1024
+	DefaultTileOverlap  = 0.25
+	MinTileSize         = 128
+	MaxTileSize         = 8192
+	MaxTileOverlap      = 0.75
+	MaxYawLimit         = 90.0
+)
+
+// Rotation retry modes.
+const (
+	// RotationRetryOnEmpty retries rotated copies when the upright pass
+	// finds nothing.
+	RotationRetryOnEmpty = "on_empty"
+	// RotationRetryExhaustive always tries every rotation.
+	RotationRetryExhaustive = "exhaustive"
+)
+
+// ProfileCompliance checks the photo against the ID photo compliance rules
+// on top of the regular detection.
+const ProfileCompliance = "compliance"
+
+// Cascade models a job can request.
+const (
+	ModelFrontalDefault = "frontal_default"
+	ModelFrontalAlt     = "frontal_alt"
+	ModelFrontalAlt2    = "frontal_alt2"
+	ModelProfile        = "profile"
+	ModelEyes           = "eyes"
+	ModelUpperBody      = "upper_body"
+	ModelSmile          = "smile"
+)
+
+// IsFaceModel reports whether the cascade model finds whole faces. The other
+// models find facial features or bodies, which are not faces.
+func IsFaceModel(model string) bool {
+	switch model {
+	case ModelFrontalDefault, ModelFrontalAlt, ModelFrontalAlt2, ModelProfile:
+		return true
+	default:
+		return false
+	}
+}
+
+// CascadeModels lists every supported cascade model.
+var CascadeModels = []string{
+	ModelFrontalDefault,
+	ModelFrontalAlt,
+	ModelFrontalAlt2,
+	ModelProfile,
+	ModelEyes,
+	ModelUpperBody,
+	ModelSmile,
+}
+
+// DetectionParams tunes a single face detection run. Sizes are face side
+// lengths in pixels, zero means no limit.
+type DetectionParams struct {
+	ScaleFactor  float64 `json:"scale_factor" bson:"scale_factor" form:"scale_factor"`
+	MinNeighbors int     `json:"min_neighbors" bson:"min_neighbors" form:"min_neighbors"`
+	MinSize      int     `json:"min_size" bson:"min_size" form:"min_size"`
+	MaxSize      int     `json:"max_size" bson:"max_size" form:"max_size"`
+	// Models are the cascades to run, ModelFrontalDefault when empty.
+	Models []string `json:"models,omitempty" bson:"models,omitempty" form:"models"`
+	// Tiled scans the full resolution image in overlapping square tiles of
+	// TileSize pixels instead of a single downscaled pass. TileOverlap is
+	// the fraction of a tile shared with its neighbours.
+	Tiled       bool    `json:"tiled" bson:"tiled" form:"tiled"`
+	TileSize    int     `json:"tile_size,omitempty" bson:"tile_size,omitempty" form:"tile_size"`
+	TileOverlap float64 `json:"tile_overlap,omitempty" bson:"tile_overlap,omitempty" form:"tile_overlap"`
+	// RotationRetry also tries the image rotated by 90, 180 and 270
+	// degrees, and by -15 and 15 degrees when RotationTilt is set, keeping
+	// the orientation with the most detections. Empty disables retries.
+	RotationRetry string `json:"rotation_retry,omitempty" bson:"rotation_retry,omitempty" form:"rotation_retry"`
+	RotationTilt  bool   `json:"rotation_tilt" bson:"rotation_tilt" form:"rotation_tilt"`
+	// MaxYaw drops faces whose estimated yaw exceeds it in degrees from the
+	// results, such as extreme profile shots. Zero keeps every face.
+	// Dropped faces are still redacted.
+	MaxYaw float64 `json:"max_yaw,omitempty" bson:"max_yaw,omitempty" form:"max_yaw"`
+	// Profile adds a use case specific report to the result, empty for none.
+	Profile string `json:"profile,omitempty" bson:"profile,omitempty" form:"profile"`
+}
+
+// DefaultDetectionParams returns the parameters used when a request does not
+// override them.
+func DefaultDetectionParams() DetectionParams {
+	return DetectionParams{
+		ScaleFactor:  DefaultScaleFactor,
+		MinNeighbors: DefaultMinNeighbors,
+		TileSize:     DefaultTileSize,
+		TileOverlap:  DefaultTileOverlap,
+	}
+}
+
+// Validate checks that the parameters are within the supported ranges.
+//
+// Returns:
+// - error: An error object describing the first invalid parameter, otherwise nil.
+func (p DetectionParams) Validate() error {
+	if p.ScaleFactor <= 1 || p.ScaleFactor > MaxScaleFactor {
+		return fmt.Errorf("scale_factor must be greater than 1 and at most %g", MaxScaleFactor)
+	}
+	if p.MinNeighbors < 0 || p.MinNeighbors > MaxMinNeighbors {
+		return fmt.Errorf("min_neighbors must be between 0 and %d", MaxMinNeighbors)
+	}
+	if p.MinSize < 0 || p.MinSize > MaxFaceSize {
+		return fmt.Errorf("min_size must be between 0 and %d", MaxFaceSize)
+	}
+	if p.MaxSize < 0 || p.MaxSize > MaxFaceSize {
+		return fmt.Errorf("max_size must be between 0 and %d", MaxFaceSize)
+	}
+	if p.MaxSize != 0 && p.MaxSize < p.MinSize {
+		return errors.New("max_size must not be smaller than min_size")
+	}
+	if p.Tiled {
+		if p.TileSize < MinTileSize || p.TileSize > MaxTileSize {
+			return fmt.Errorf("tile_size must be between %d and %d", MinTileSize, MaxTileSize)
+		}
+		if p.TileOverlap < 0 || p.TileOverlap > MaxTileOverlap {
+			return fmt.Errorf("tile_overlap must be between 0 and %g", MaxTileOverlap)
+		}
+	}
+	switch p.RotationRetry {
+	case "", RotationRetryOnEmpty, RotationRetryExhaustive:
+	default:
+		return fmt.Errorf("rotation_retry must be %q or %q", RotationRetryOnEmpty, RotationRetryExhaustive)
+	}
+	if p.RotationTilt && p.RotationRetry == "" {
+		return errors.New("rotation_tilt requires rotation_retry to be set")
+	}
+	if p.MaxYaw < 0 || p.MaxYaw > MaxYawLimit {
+		return fmt.Errorf("max_yaw must be between 0 and %g", MaxYawLimit)
+	}
+	switch p.Profile {
+	case "", ProfileCompliance:
+	default:
+		return fmt.Errorf("profile must be %q", ProfileCompliance)
+	}
+	for _, model := range p.ModelList() {
+		if !slices.Contains(CascadeModels, model) {
+			return fmt.Errorf("models must be a subset of %s", strings.Join(CascadeModels, ", "))
+		}
+	}
+	return nil
+}
+
+// ModelList returns the requested cascade models without duplicates,
+// accepting comma separated values, or ModelFrontalDefault when none are set.
+func (p DetectionParams) ModelList() []string {
+	var models []string
+	for _, value := range p.Models {
+		for _, model := range strings.Split(value, ",") {
+			model = strings.TrimSpace(model)
+			if model != "" && !slices.Contains(models, model) {
+				models = append(models, model)
+			}
+		}
+	}
+	if len(models) == 0 {
+		return []string{ModelFrontalDefault}
+	}
+	return models
+}
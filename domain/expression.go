@@ -0,0 +1,40 @@
+package domain
+
+// ExpressionSummary summarizes the expression cues of the faces of a photo,
+// for instance to flag group shots where someone blinked. Faces whose cues
+// could not be estimated are not counted.
+type ExpressionSummary struct {
+	// AllEyesOpen is false when any face has its eyes closed.
+	AllEyesOpen bool `json:"all_eyes_open" bson:"all_eyes_open"`
+	// EveryoneSmiling is true when every face is smiling.
+	EveryoneSmiling bool `json:"everyone_smiling" bson:"everyone_smiling"`
+	EyesClosed      int  `json:"eyes_closed" bson:"eyes_closed"`
+	Smiling         int  `json:"smiling" bson:"smiling"`
+	// Faces is the number of faces the cues were estimated for.
+	Faces int `json:"faces" bson:"faces"`
+}
+
+// SummarizeExpressions returns the expression summary of the faces, nil when
+// no face has expression cues.
+func SummarizeExpressions(faces []Face) *ExpressionSummary {
+	summary := &ExpressionSummary{AllEyesOpen: true, EveryoneSmiling: true}
+	for _, f := range faces {
+		if f.EyesOpen == nil || f.Smiling == nil {
+			continue
+		}
+		summary.Faces++
+		if !*f.EyesOpen {
+			summary.EyesClosed++
+			summary.AllEyesOpen = false
+		}
+		if *f.Smiling {
+			summary.Smiling++
+		} else {
+			summary.EveryoneSmiling = false
+		}
+	}
+	if summary.Faces == 0 {
+		return nil
+	}
+	return summary
+}
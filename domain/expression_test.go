@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSummarizeExpressions(t *testing.T) {
+	yes, no := true, false
+	face := func(eyesOpen, smiling *bool) Face {
+		return Face{EyesOpen: eyesOpen, Smiling: smiling}
+	}
+
+	tests := []struct {
+		name  string
+		faces []Face
+		want  *ExpressionSummary
+	}{
+		{name: "no faces", faces: nil, want: nil},
+		{name: "no cues", faces: []Face{face(nil, nil), face(nil, nil)}, want: nil},
+		{
+			name:  "everyone smiling with eyes open",
+			faces: []Face{face(&yes, &yes), face(&yes, &yes)},
+			want:  &ExpressionSummary{AllEyesOpen: true, EveryoneSmiling: true, Smiling: 2, Faces: 2},
+		},
+		{
+			name:  "someone blinked",
+			faces: []Face{face(&yes, &yes), face(&no, &yes)},
+			want:  &ExpressionSummary{AllEyesOpen: false, EveryoneSmiling: true, EyesClosed: 1, Smiling: 2, Faces: 2},
+		},
+		{
+			name:  "someone not smiling",
+			faces: []Face{face(&yes, &no), face(&yes, &yes)},
+			want:  &ExpressionSummary{AllEyesOpen: true, EveryoneSmiling: false, Smiling: 1, Faces: 2},
+		},
+		{
+			name:  "faces without cues are not counted",
+			faces: []Face{face(&yes, &yes), face(nil, nil), face(&no, nil)},
+			want:  &ExpressionSummary{AllEyesOpen: true, EveryoneSmiling: true, Smiling: 1, Faces: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SummarizeExpressions(tt.faces); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SummarizeExpressions() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
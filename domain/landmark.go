@@ -0,0 +1,27 @@
+package domain
+
+// Point is a position in pixels of the original photo.
+type Point struct {
+	X int `json:"x" bson:"x"`
+	Y int `json:"y" bson:"y"`
+}
+
+// Landmarks are facial feature positions in pixels of the original photo,
+// like the face box. Left and right refer to the photo as displayed upright,
+// not to the subject.
+type Landmarks struct {
+	LeftEye    Point `json:"left_eye" bson:"left_eye"`
+	RightEye   Point `json:"right_eye" bson:"right_eye"`
+	NoseTip    Point `json:"nose_tip" bson:"nose_tip"`
+	MouthLeft  Point `json:"mouth_left" bson:"mouth_left"`
+	MouthRight Point `json:"mouth_right" bson:"mouth_right"`
+}
+
+// HeadPose is the head orientation in degrees, 0 for a frontal face. Yaw is
+// positive when the face turns towards the right of the photo, pitch when it
+// looks up and roll when it tilts clockwise.
+type HeadPose struct {
+	Yaw   float64 `json:"yaw" bson:"yaw"`
+	Pitch float64 `json:"pitch" bson:"pitch"`
+	Roll  float64 `json:"roll" bson:"roll"`
+}
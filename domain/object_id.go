@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// objectID is implemented by the typed Mongo ObjectIDs of the domain, such
+// as PhotoID, which only add their own parse error on top of these helpers.
+type objectID interface {
+	~[12]byte
+}
+
+// parseObjectID parses the 24 hex characters of an ID, returning invalid
+// when s is not an ObjectID.
+func parseObjectID[T objectID](s string, invalid error) (T, error) {
+	oid, err := primitive.ObjectIDFromHex(s)
+	if err != nil {
+		return T{}, invalid
+	}
+	return T(oid), nil
+}
+
+// objectIDHex returns the hex form of an ID.
+func objectIDHex[T objectID](id T) string {
+	return primitive.ObjectID(id).Hex()
+}
+
+// unmarshalObjectIDText decodes the hex form of an ID into id.
+func unmarshalObjectIDText[T objectID](id *T, text []byte, invalid error) error {
+	parsed, err := parseObjectID[T](string(text), invalid)
+	if err != nil {
+		return err
+	}
+	*id = parsed
+	return nil
+}
+
+// marshalObjectIDBSON stores an ID as an ObjectID.
+func marshalObjectIDBSON[T objectID](id T) (bsontype.Type, []byte, error) {
+	return bson.MarshalValue(primitive.ObjectID(id))
+}
+
+// unmarshalObjectIDBSON decodes an ObjectID into id.
+func unmarshalObjectIDBSON[T objectID](id *T, t bsontype.Type, data []byte) error {
+	var oid primitive.ObjectID
+	if err := (bson.RawValue{Type: t, Value: data}).Unmarshal(&oid); err != nil {
+		return err
+	}
+	*id = T(oid)
+	return nil
+}
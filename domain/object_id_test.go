@@ -0,0 +1,138 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// objectIDCase runs the shared ObjectID checks against one typed ID.
+type objectIDCase struct {
+	name    string
+	invalid error
+	// parse parses s and returns the hex form of the ID.
+	parse func(s string) (string, error)
+	// roundTrip encodes the parsed ID to JSON and BSON and decodes it
+	// back, returning the hex forms and the BSON type it was stored as.
+	roundTrip func(s string) (fromJSON, fromBSON string, stored bsontype.Type, err error)
+}
+
+func objectIDCaseOf[T interface {
+	objectID
+	String() string
+}](name string, invalid error, parse func(string) (T, error)) objectIDCase {
+	return objectIDCase{
+		name:    name,
+		invalid: invalid,
+		parse: func(s string) (string, error) {
+			id, err := parse(s)
+			return id.String(), err
+		},
+		roundTrip: func(s string) (string, string, bsontype.Type, error) {
+			id, err := parse(s)
+			if err != nil {
+				return "", "", 0, err
+			}
+			var doc struct {
+				ID T `json:"id" bson:"_id"`
+			}
+			doc.ID = id
+			encoded, err := json.Marshal(doc)
+			if err != nil {
+				return "", "", 0, err
+			}
+			var fromJSON struct {
+				ID T `json:"id"`
+			}
+			if err := json.Unmarshal(encoded, &fromJSON); err != nil {
+				return "", "", 0, err
+			}
+			raw, err := bson.Marshal(doc)
+			if err != nil {
+				return "", "", 0, err
+			}
+			var fromBSON struct {
+				ID T `bson:"_id"`
+			}
+			if err := bson.Unmarshal(raw, &fromBSON); err != nil {
+				return "", "", 0, err
+			}
+			return fromJSON.ID.String(), fromBSON.ID.String(), bson.Raw(raw).Lookup("_id").Type, nil
+		},
+	}
+}
+
+func TestObjectIDs(t *testing.T) {
+	ids := []objectIDCase{
+		objectIDCaseOf("photo", ErrInvalidPhotoID, ParsePhotoID),
+		objectIDCaseOf("person", ErrInvalidPersonID, ParsePersonID),
+		objectIDCaseOf("cluster", ErrInvalidClusterID, ParseClusterID),
+	}
+	tests := []struct {
+		name    string
+		s       string
+		want    string
+		wantErr bool
+	}{
+		{name: "valid", s: testPhotoHex, want: testPhotoHex},
+		{name: "upper case", s: "65F1C2A4E13D9B0A7C4B2E10", want: testPhotoHex},
+		{name: "zero", s: "000000000000000000000000", want: "000000000000000000000000"},
+		{name: "empty", s: "", wantErr: true},
+		{name: "too short", s: testPhotoHex[:23], wantErr: true},
+		{name: "not hex", s: "zzf1c2a4e13d9b0a7c4b2e10", wantErr: true},
+	}
+	for _, id := range ids {
+		for _, tt := range tests {
+			t.Run(id.name+"/"+tt.name, func(t *testing.T) {
+				got, err := id.parse(tt.s)
+				if tt.wantErr {
+					if !errors.Is(err, id.invalid) {
+						t.Errorf("parse(%q) error = %v, want %v", tt.s, err, id.invalid)
+					}
+					return
+				}
+				if err != nil || got != tt.want {
+					t.Fatalf("parse(%q) = %s, %v, want %s", tt.s, got, err, tt.want)
+				}
+
+				fromJSON, fromBSON, stored, err := id.roundTrip(tt.s)
+				if err != nil {
+					t.Fatalf("round trip error = %v", err)
+				}
+				if fromJSON != tt.want || fromBSON != tt.want {
+					t.Errorf("round trip = %s (JSON), %s (BSON), want %s", fromJSON, fromBSON, tt.want)
+				}
+				if stored != bsontype.ObjectID {
+					t.Errorf("stored as BSON %s, want ObjectID", stored)
+				}
+			})
+		}
+	}
+}
+
+func TestObjectIDIsZero(t *testing.T) {
+	oid := primitive.NewObjectID()
+	tests := []struct {
+		name string
+		zero bool
+		id   interface{ IsZero() bool }
+	}{
+		{name: "zero photo", zero: true, id: PhotoID{}},
+		{name: "photo", id: PhotoID(oid)},
+		{name: "zero person", zero: true, id: PersonID{}},
+		{name: "person", id: NewPersonID()},
+		{name: "zero cluster", zero: true, id: ClusterID{}},
+		{name: "cluster", id: NewClusterID()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.id.IsZero(); got != tt.zero {
+				t.Errorf("IsZero() = %v, want %v", got, tt.zero)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// MaxReferencePhotos bounds the number of reference photos per enrollment.
+const MaxReferencePhotos = 10
+
+// Person is an enrolled person faces are assigned to or suggested as.
+type Person struct {
+	ID   PersonID `json:"id" bson:"_id" swaggertype:"string"`
+	Name string   `json:"name" bson:"name"`
+	// References is the number of reference faces the person was enrolled
+	// from, either uploaded photos or faces of a named cluster. Only their
+	// embeddings are kept.
+	References int         `json:"references" bson:"references"`
+	Embeddings [][]float32 `json:"-" bson:"embeddings"`
+	CreatedAt  time.Time   `json:"created_at" bson:"created_at"`
+}
+
+// PersonSuggestion is an enrolled person a face looks like. Embeddings come
+// from a texture descriptor, not a face recognition model, so the score
+// tells how alike the faces look and a suggestion is never an identity
+// match.
+type PersonSuggestion struct {
+	PersonID PersonID `json:"person_id" bson:"person_id" swaggertype:"string"`
+	// Score is the cosine similarity of the face to the closest reference
+	// face of the person.
+	Score float64 `json:"score" bson:"score"`
+}
+
+// PersonRequest holds the fields of a person enrollment besides the
+// reference photos.
+type PersonRequest struct {
+	Name string `json:"name" form:"name"`
+}
+
+// Validate checks that the person has a name.
+//
+// Returns:
+// - error: An error object if the request is invalid, otherwise nil.
+func (r PersonRequest) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrInvalidPersonID is returned when a person ID cannot be parsed.
+var ErrInvalidPersonID = errors.New("invalid person id")
+
+// PersonID identifies an enrolled person. Like PhotoID it is a Mongo
+// ObjectID, unique across replicas and exchanged as 24 hex characters, which
+// JSON clients keep exact unlike large integers.
+type PersonID primitive.ObjectID
+
+// NewPersonID generates the ID of a new person.
+//
+// Returns:
+// - PersonID: A unique ID.
+func NewPersonID() PersonID {
+	return PersonID(primitive.NewObjectID())
+}
+
+// ParsePersonID parses the hex form of a person ID.
+//
+// Parameters:
+// - s: The 24 hex characters of the ID.
+//
+// Returns:
+// - PersonID: The parsed ID.
+// - error: ErrInvalidPersonID if s is not a person ID, otherwise nil.
+func ParsePersonID(s string) (PersonID, error) {
+	return parseObjectID[PersonID](s, ErrInvalidPersonID)
+}
+
+// String returns the hex form of the ID.
+func (id PersonID) String() string {
+	return objectIDHex(id)
+}
+
+// IsZero reports whether the ID is unset.
+func (id PersonID) IsZero() bool {
+	return id == PersonID{}
+}
+
+// MarshalText encodes the ID as hex, which is also its JSON form.
+func (id PersonID) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+// UnmarshalText decodes the hex form of the ID.
+func (id *PersonID) UnmarshalText(text []byte) error {
+	return unmarshalObjectIDText(id, text, ErrInvalidPersonID)
+}
+
+// MarshalBSONValue stores the ID as an ObjectID.
+func (id PersonID) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	return marshalObjectIDBSON(id)
+}
+
+// UnmarshalBSONValue decodes an ObjectID.
+func (id *PersonID) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	return unmarshalObjectIDBSON(id, t, data)
+}
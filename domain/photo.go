@@ -3,9 +3,143 @@ package domain
 import "time"
 
 type Photo struct {
-	ID            int64     `json:"id" bson:"_id, omitempty"`
-	FilePath      string    `json:"photo_url" bson:"photo_url"`
-	TimeStamp     time.Time `json:"timestamp" bson:"timestamp"`
-	Status        string    `json:"status" bson:"status"`
-	FacesDetected int       `json:"faces_detected" bson:"faces_detected"`
+	ID PhotoID `json:"id" bson:"_id" swaggertype:"string"`
+	// FilePath is the stored original, empty once it was discarded.
+	FilePath string `json:"-" bson:"photo_url"`
+	// PhotoURL, AnnotatedURL and RedactedURL serve the original, the copy
+	// with the faces outlined and the redacted copy. They are only set in API
+	// responses, for the files the photo has.
+	PhotoURL     string    `json:"photo_url,omitempty" bson:"-"`
+	AnnotatedURL string    `json:"annotated_url,omitempty" bson:"-"`
+	RedactedURL  string    `json:"redacted_url,omitempty" bson:"-"`
+	TimeStamp    time.Time `json:"timestamp" bson:"timestamp"`
+	Status       string    `json:"status" bson:"status"`
+	// UpdatedAt is the time of the last status change.
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
+	// Tags are client labels used to filter photo listings.
+	Tags []string `json:"tags,omitempty" bson:"tags,omitempty"`
+	// Detector is the detector backend that processed the photo.
+	Detector string `json:"detector,omitempty" bson:"detector,omitempty"`
+	// BurstID groups photos of the same burst for best-shot selection.
+	BurstID      string           `json:"burst_id,omitempty" bson:"burst_id,omitempty"`
+	Params       DetectionParams  `json:"params" bson:"params"`
+	Redaction    RedactionOptions `json:"redaction" bson:"redaction"`
+	RedactedPath string           `json:"-" bson:"redacted_url,omitempty"`
+	Width        int              `json:"width" bson:"width"`
+	Height       int              `json:"height" bson:"height"`
+	// Orientation is the EXIF orientation of the upload. Face coordinates
+	// are in the stored pixel grid, before this orientation is applied.
+	Orientation int `json:"orientation" bson:"orientation"`
+	// Rotation is the clockwise rotation in degrees, applied on top of
+	// Orientation, under which the faces were found.
+	Rotation      float64 `json:"rotation" bson:"rotation"`
+	FacesDetected int     `json:"faces_detected" bson:"faces_detected"`
+	Faces         []Face  `json:"faces" bson:"faces"`
+	// Regions are the detections of the models that do not find faces,
+	// such as eyes or upper bodies. They are not counted, cropped or
+	// searched as faces.
+	Regions []Region `json:"regions,omitempty" bson:"regions,omitempty"`
+	// Expressions summarizes the eyes open and smile cues of the faces.
+	Expressions *ExpressionSummary `json:"expressions,omitempty" bson:"expressions,omitempty"`
+	// ExpressionsUnavailable tells why no face has eyes open and smile cues,
+	// such as a detector backend without the eye and smile cascades. Empty
+	// when the cues were estimated.
+	ExpressionsUnavailable string `json:"expressions_unavailable,omitempty" bson:"expressions_unavailable,omitempty"`
+	// Compliance is the report of the compliance profile, empty for other
+	// profiles.
+	Compliance *ComplianceReport `json:"compliance,omitempty" bson:"compliance,omitempty"`
+}
+
+// Region is a detection of a non-face cascade model on a photo.
+type Region struct {
+	Box        Box           `json:"box" bson:"box"`
+	Normalized NormalizedBox `json:"normalized_box" bson:"normalized_box"`
+	Confidence float64       `json:"confidence" bson:"confidence"`
+	// Model is the cascade model that produced the detection.
+	Model string `json:"model" bson:"model"`
+}
+
+// Face is a single face detected on a photo.
+type Face struct {
+	Box        Box           `json:"box" bson:"box"`
+	Normalized NormalizedBox `json:"normalized_box" bson:"normalized_box"`
+	// Confidence is the detector score of the face. Its scale depends on the
+	// detector backend, higher is more confident.
+	Confidence float64 `json:"confidence" bson:"confidence"`
+	// Model is the cascade model that produced the detection.
+	Model string `json:"model" bson:"model"`
+	// CropPath is the stored thumbnail of the face, empty when no crop was
+	// generated.
+	CropPath string `json:"-" bson:"crop_url,omitempty"`
+	// CropURL serves the thumbnail, only set in API responses.
+	CropURL string `json:"crop_url,omitempty" bson:"-"`
+	// Quality holds image quality metrics measured inside the box.
+	Quality FaceQuality `json:"quality" bson:"quality"`
+	// Landmarks and Pose are estimated from the face image inside the box.
+	Landmarks *Landmarks `json:"landmarks,omitempty" bson:"landmarks,omitempty"`
+	Pose      *HeadPose  `json:"pose,omitempty" bson:"pose,omitempty"`
+	// EyesOpen and Smiling are estimated with the eye and smile cascades
+	// inside the box, with a confidence in the [0.5, 1] range. They are empty
+	// when the detector backend cannot run those cascades.
+	EyesOpen           *bool   `json:"eyes_open,omitempty" bson:"eyes_open,omitempty"`
+	EyesOpenConfidence float64 `json:"eyes_open_confidence,omitempty" bson:"eyes_open_confidence,omitempty"`
+	Smiling            *bool   `json:"smiling,omitempty" bson:"smiling,omitempty"`
+	SmilingConfidence  float64 `json:"smiling_confidence,omitempty" bson:"smiling_confidence,omitempty"`
+	// Embedding describes the face for similarity search, it is empty for
+	// redacted photos.
+	Embedding []float32 `json:"-" bson:"embedding,omitempty"`
+	// PersonID is the enrolled person the face was assigned to by naming
+	// its cluster, with its look-alike score to the person's references in
+	// PersonScore. Processing never sets them, see PersonSuggestion.
+	PersonID    *PersonID `json:"person_id,omitempty" bson:"person_id,omitempty" swaggertype:"string"`
+	PersonScore float64   `json:"person_score,omitempty" bson:"person_score,omitempty"`
+	// PersonSuggestion is the enrolled person the face looks most alike,
+	// set during processing when the look-alike score reaches the match
+	// threshold. It is a hint to review, not an identification.
+	PersonSuggestion *PersonSuggestion `json:"person_suggestion,omitempty" bson:"person_suggestion,omitempty"`
+}
+
+// Box is a face bounding box in pixels of the original photo.
+type Box struct {
+	X      int `json:"x" bson:"x"`
+	Y      int `json:"y" bson:"y"`
+	Width  int `json:"width" bson:"width"`
+	Height int `json:"height" bson:"height"`
+}
+
+// NormalizedBox is a face bounding box relative to the photo size, every
+// value is in the [0, 1] range.
+type NormalizedBox struct {
+	X      float64 `json:"x" bson:"x"`
+	Y      float64 `json:"y" bson:"y"`
+	Width  float64 `json:"width" bson:"width"`
+	Height float64 `json:"height" bson:"height"`
+}
+
+// PhotoJob is the detection job created by an upload.
+type PhotoJob struct {
+	ID        PhotoID    `json:"id" swaggertype:"string"`
+	Status    string     `json:"status"`
+	BurstID   string     `json:"burst_id,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	Links     PhotoLinks `json:"links"`
+}
+
+// PhotoLinks are the URLs of the resources of a photo.
+type PhotoLinks struct {
+	// Result is the status URL, polled until the job is processed.
+	Result string `json:"result"`
+	Photo  string `json:"photo"`
+	Burst  string `json:"burst,omitempty"`
+}
+
+// PhotoDeletion reports the files removed with a deleted photo. Files are
+// named by kind: "original", "processed", "redacted" and "face_<index>".
+type PhotoDeletion struct {
+	ID      PhotoID  `json:"id" swaggertype:"string"`
+	Removed []string `json:"removed"`
+	// Leftover lists the files that could not be removed once the photo was
+	// deleted, they need to be cleaned up by hand.
+	Leftover []string `json:"leftover,omitempty"`
 }
@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"bytes"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrInvalidPhotoID is returned when a photo ID cannot be parsed.
+var ErrInvalidPhotoID = errors.New("invalid photo id")
+
+// PhotoID identifies a photo across the REST API, Mongo and the queue. It is
+// a Mongo ObjectID, unique across concurrent uploads and ordered by creation
+// time, stored as an ObjectID and exchanged as 24 hex characters.
+type PhotoID primitive.ObjectID
+
+// NewPhotoID generates the ID of a new photo.
+//
+// Returns:
+// - PhotoID: A unique ID ordered after every ID generated before it.
+func NewPhotoID() PhotoID {
+	return PhotoID(primitive.NewObjectID())
+}
+
+// ParsePhotoID parses the hex form of a photo ID.
+//
+// Parameters:
+// - s: The 24 hex characters of the ID.
+//
+// Returns:
+// - PhotoID: The parsed ID.
+// - error: ErrInvalidPhotoID if s is not a photo ID, otherwise nil.
+func ParsePhotoID(s string) (PhotoID, error) {
+	return parseObjectID[PhotoID](s, ErrInvalidPhotoID)
+}
+
+// String returns the hex form of the ID.
+func (id PhotoID) String() string {
+	return objectIDHex(id)
+}
+
+// IsZero reports whether the ID is unset.
+func (id PhotoID) IsZero() bool {
+	return id == PhotoID{}
+}
+
+// Compare returns -1, 0 or 1 when the ID was generated before, together
+// with or after other.
+func (id PhotoID) Compare(other PhotoID) int {
+	return bytes.Compare(id[:], other[:])
+}
+
+// MarshalText encodes the ID as hex, which is also its JSON form.
+func (id PhotoID) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+// UnmarshalText decodes the hex form of the ID.
+func (id *PhotoID) UnmarshalText(text []byte) error {
+	return unmarshalObjectIDText(id, text, ErrInvalidPhotoID)
+}
+
+// MarshalBSONValue stores the ID as an ObjectID.
+func (id PhotoID) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	return marshalObjectIDBSON(id)
+}
+
+// UnmarshalBSONValue decodes an ObjectID.
+func (id *PhotoID) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	return unmarshalObjectIDBSON(id, t, data)
+}
@@ -0,0 +1,170 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const testPhotoHex = "65f1c2a4e13d9b0a7c4b2e10"
+
+func TestParsePhotoID(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    string
+		wantErr bool
+	}{
+		{name: "valid", s: testPhotoHex, want: testPhotoHex},
+		{name: "upper case", s: "65F1C2A4E13D9B0A7C4B2E10", want: testPhotoHex},
+		{name: "empty", s: "", wantErr: true},
+		{name: "too short", s: testPhotoHex[:22], wantErr: true},
+		{name: "too long", s: testPhotoHex + "00", wantErr: true},
+		{name: "not hex", s: "zzf1c2a4e13d9b0a7c4b2e10", wantErr: true},
+		{name: "decimal id", s: "1712345678901234567", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePhotoID(tt.s)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidPhotoID) {
+					t.Errorf("ParsePhotoID(%q) error = %v, want %v", tt.s, err, ErrInvalidPhotoID)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParsePhotoID(%q) error = %v", tt.s, err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("ParsePhotoID(%q) = %s, want %s", tt.s, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPhotoIDText(t *testing.T) {
+	id, _ := ParsePhotoID(testPhotoHex)
+
+	type document struct {
+		ID       PhotoID  `json:"id"`
+		Optional *PhotoID `json:"optional,omitempty"`
+	}
+	tests := []struct {
+		name    string
+		json    string
+		want    document
+		wantErr bool
+	}{
+		{name: "hex string", json: `{"id":"` + testPhotoHex + `"}`, want: document{ID: id}},
+		{name: "optional set", json: `{"id":"` + testPhotoHex + `","optional":"` + testPhotoHex + `"}`, want: document{ID: id, Optional: &id}},
+		{name: "invalid hex", json: `{"id":"not-an-id"}`, wantErr: true},
+		{name: "number", json: `{"id":42}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got document
+			err := json.Unmarshal([]byte(tt.json), &got)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("json.Unmarshal(%s) = %+v, want an error", tt.json, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("json.Unmarshal(%s) error = %v", tt.json, err)
+			}
+			if got.ID != tt.want.ID || (got.Optional == nil) != (tt.want.Optional == nil) ||
+				(got.Optional != nil && *got.Optional != *tt.want.Optional) {
+				t.Errorf("json.Unmarshal(%s) = %+v, want %+v", tt.json, got, tt.want)
+			}
+
+			// Marshaling must give back the same document.
+			encoded, err := json.Marshal(got)
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+			if string(encoded) != tt.json {
+				t.Errorf("json.Marshal() = %s, want %s", encoded, tt.json)
+			}
+		})
+	}
+}
+
+func TestPhotoIDBSON(t *testing.T) {
+	id, _ := ParsePhotoID(testPhotoHex)
+	oid, _ := primitive.ObjectIDFromHex(testPhotoHex)
+
+	tests := []struct {
+		name    string
+		doc     bson.M
+		want    PhotoID
+		wantErr bool
+	}{
+		{name: "object id", doc: bson.M{"_id": oid}, want: id},
+		{name: "zero object id", doc: bson.M{"_id": primitive.NilObjectID}, want: PhotoID{}},
+		{name: "hex string", doc: bson.M{"_id": testPhotoHex}, want: id},
+		{name: "legacy number", doc: bson.M{"_id": int64(1712345678901234567)}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, err := bson.Marshal(tt.doc)
+			if err != nil {
+				t.Fatalf("bson.Marshal() error = %v", err)
+			}
+			var got struct {
+				ID PhotoID `bson:"_id"`
+			}
+			err = bson.Unmarshal(raw, &got)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("bson.Unmarshal() = %s, want an error", got.ID)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("bson.Unmarshal() error = %v", err)
+			}
+			if got.ID != tt.want {
+				t.Errorf("bson.Unmarshal() = %s, want %s", got.ID, tt.want)
+			}
+
+			// The ID must be stored as an ObjectID, not as a hex string.
+			encoded, err := bson.Marshal(got)
+			if err != nil {
+				t.Fatalf("bson.Marshal() error = %v", err)
+			}
+			value := bson.Raw(encoded).Lookup("_id")
+			if value.Type != bsontype.ObjectID || value.ObjectID() != primitive.ObjectID(tt.want) {
+				t.Errorf("bson.Marshal() stored %s %v, want ObjectID %s", value.Type, value, tt.want)
+			}
+		})
+	}
+}
+
+func TestPhotoIDCompare(t *testing.T) {
+	first, _ := ParsePhotoID("65f1c2a4e13d9b0a7c4b2e10")
+	second, _ := ParsePhotoID("65f1c2a4e13d9b0a7c4b2e11")
+	later, _ := ParsePhotoID("65f1c2a50000000000000000")
+	tests := []struct {
+		name string
+		a, b PhotoID
+		want int
+	}{
+		{name: "equal", a: first, b: first, want: 0},
+		{name: "before", a: first, b: second, want: -1},
+		{name: "after", a: second, b: first, want: 1},
+		{name: "later timestamp", a: later, b: second, want: 1},
+		{name: "zero first", a: PhotoID{}, b: first, want: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.Compare(tt.b); got != tt.want {
+				t.Errorf("%s.Compare(%s) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Photo listing defaults and limits.
+const (
+	DefaultPhotoListLimit = 20
+	MaxPhotoListLimit     = 100
+	MaxTags               = 20
+	MaxTagLength          = 64
+)
+
+// Photo listing sort fields and orders.
+const (
+	SortTimestamp     = "timestamp"
+	SortFacesDetected = "faces_detected"
+	OrderAsc          = "asc"
+	OrderDesc         = "desc"
+)
+
+// Photo statuses.
+var photoStatuses = []string{"pending", "processed", "error"}
+
+// ErrInvalidCursor is returned when a listing cursor cannot be decoded or
+// does not belong to the requested sort.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// TagList returns the tags without duplicates or surrounding spaces,
+// accepting comma separated values.
+//
+// Parameters:
+// - values: The tags as submitted.
+//
+// Returns:
+// - []string: The tags, nil when none are set.
+// - error: An error object if there are too many tags or a tag is too long, otherwise nil.
+func TagList(values []string) ([]string, error) {
+	var tags []string
+	for _, value := range values {
+		for _, tag := range strings.Split(value, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "" || slices.Contains(tags, tag) {
+				continue
+			}
+			if len(tag) > MaxTagLength {
+				return nil, fmt.Errorf("tags must be at most %d characters long", MaxTagLength)
+			}
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) > MaxTags {
+		return nil, fmt.Errorf("at most %d tags are allowed", MaxTags)
+	}
+	return tags, nil
+}
+
+// PhotoListRequest filters, sorts and pages a photo listing. Empty filters
+// match every photo.
+type PhotoListRequest struct {
+	Status string `query:"status"`
+	// From and To bound the upload time, in RFC 3339 format. From is
+	// inclusive, To exclusive.
+	From string `query:"from"`
+	To   string `query:"to"`
+	// MinFaces and MaxFaces bound the number of detected faces, a negative
+	// MaxFaces does not limit it.
+	MinFaces int    `query:"min_faces"`
+	MaxFaces int    `query:"max_faces"`
+	Tag      string `query:"tag"`
+	Detector string `query:"detector"`
+	Sort     string `query:"sort"`
+	Order    string `query:"order"`
+	Limit    int    `query:"limit"`
+	// Cursor is the next_cursor of the previous page, empty for the first
+	// page.
+	Cursor string `query:"cursor"`
+}
+
+// DefaultPhotoListRequest returns the listing of the newest photos.
+func DefaultPhotoListRequest() PhotoListRequest {
+	return PhotoListRequest{
+		MaxFaces: -1,
+		Sort:     SortTimestamp,
+		Order:    OrderDesc,
+		Limit:    DefaultPhotoListLimit,
+	}
+}
+
+// Validate checks the filters, the sort and the limit.
+//
+// Returns:
+// - error: An error object describing the first invalid field, otherwise nil.
+func (r PhotoListRequest) Validate() error {
+	if r.Status != "" && !slices.Contains(photoStatuses, r.Status) {
+		return fmt.Errorf("status must be one of %s", strings.Join(photoStatuses, ", "))
+	}
+	from, to, err := r.TimeRange()
+	if err != nil {
+		return err
+	}
+	if !from.IsZero() && !to.IsZero() && !to.After(from) {
+		return errors.New("to must be after from")
+	}
+	if r.MinFaces < 0 {
+		return errors.New("min_faces must not be negative")
+	}
+	if r.MaxFaces >= 0 && r.MaxFaces < r.MinFaces {
+		return errors.New("max_faces must not be smaller than min_faces")
+	}
+	if r.Sort != SortTimestamp && r.Sort != SortFacesDetected {
+		return fmt.Errorf("sort must be %q or %q", SortTimestamp, SortFacesDetected)
+	}
+	if r.Order != OrderAsc && r.Order != OrderDesc {
+		return fmt.Errorf("order must be %q or %q", OrderAsc, OrderDesc)
+	}
+	if r.Limit < 1 || r.Limit > MaxPhotoListLimit {
+		return fmt.Errorf("limit must be between 1 and %d", MaxPhotoListLimit)
+	}
+	return nil
+}
+
+// TimeRange parses From and To, zero when unset.
+//
+// Returns:
+// - from: The inclusive lower bound of the upload time.
+// - to: The exclusive upper bound of the upload time.
+// - error: An error object if a bound is not in RFC 3339 format, otherwise nil.
+func (r PhotoListRequest) TimeRange() (from, to time.Time, err error) {
+	if r.From != "" {
+		if from, err = time.Parse(time.RFC3339, r.From); err != nil {
+			return from, to, errors.New("from must be an RFC 3339 time")
+		}
+	}
+	if r.To != "" {
+		if to, err = time.Parse(time.RFC3339, r.To); err != nil {
+			return from, to, errors.New("to must be an RFC 3339 time")
+		}
+	}
+	return from, to, nil
+}
+
+// PhotoPage is a page of a photo listing.
+type PhotoPage struct {
+	Photos []Photo `json:"photos"`
+	// NextCursor fetches the next page, empty on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
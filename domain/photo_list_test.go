@@ -0,0 +1,61 @@
+package domain
+
+import "testing"
+
+func TestPhotoListRequestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(r *PhotoListRequest)
+		wantErr string
+	}{
+		{name: "defaults", modify: func(r *PhotoListRequest) {}},
+		{name: "every filter", modify: func(r *PhotoListRequest) {
+			r.Status = "processed"
+			r.From = "2024-01-01T00:00:00Z"
+			r.To = "2024-02-01T00:00:00+07:00"
+			r.MinFaces, r.MaxFaces = 1, 3
+			r.Tag = "wedding"
+			r.Sort, r.Order = SortFacesDetected, OrderAsc
+			r.Limit = MaxPhotoListLimit
+		}},
+		{name: "equal face bounds", modify: func(r *PhotoListRequest) { r.MinFaces, r.MaxFaces = 2, 2 }},
+		{name: "min faces without max", modify: func(r *PhotoListRequest) { r.MinFaces, r.MaxFaces = 5, -1 }},
+		{name: "unknown status", modify: func(r *PhotoListRequest) { r.Status = "done" },
+			wantErr: "status must be one of pending, processed, error"},
+		{name: "from not RFC 3339", modify: func(r *PhotoListRequest) { r.From = "2024-01-01" },
+			wantErr: "from must be an RFC 3339 time"},
+		{name: "to not RFC 3339", modify: func(r *PhotoListRequest) { r.To = "yesterday" },
+			wantErr: "to must be an RFC 3339 time"},
+		{name: "to equal to from", modify: func(r *PhotoListRequest) {
+			r.From, r.To = "2024-01-01T00:00:00Z", "2024-01-01T07:00:00+07:00"
+		}, wantErr: "to must be after from"},
+		{name: "to before from", modify: func(r *PhotoListRequest) {
+			r.From, r.To = "2024-02-01T00:00:00Z", "2024-01-01T00:00:00Z"
+		}, wantErr: "to must be after from"},
+		{name: "negative min faces", modify: func(r *PhotoListRequest) { r.MinFaces = -1 },
+			wantErr: "min_faces must not be negative"},
+		{name: "max faces below min faces", modify: func(r *PhotoListRequest) { r.MinFaces, r.MaxFaces = 3, 2 },
+			wantErr: "max_faces must not be smaller than min_faces"},
+		{name: "unknown sort", modify: func(r *PhotoListRequest) { r.Sort = "name" },
+			wantErr: `sort must be "timestamp" or "faces_detected"`},
+		{name: "unknown order", modify: func(r *PhotoListRequest) { r.Order = "up" },
+			wantErr: `order must be "asc" or "desc"`},
+		{name: "zero limit", modify: func(r *PhotoListRequest) { r.Limit = 0 },
+			wantErr: "limit must be between 1 and 100"},
+		{name: "limit too large", modify: func(r *PhotoListRequest) { r.Limit = MaxPhotoListLimit + 1 },
+			wantErr: "limit must be between 1 and 100"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := DefaultPhotoListRequest()
+			tt.modify(&r)
+			err := r.Validate()
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("Validate() error = %v, want nil", err)
+			case tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr):
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package domain
+
+import "errors"
+
+// Occlusion hints of a face.
+const (
+	// OcclusionEyes flags an unusually dark eye region, such as sunglasses.
+	OcclusionEyes = "eyes"
+	// OcclusionLowerFace flags a lower face without the texture of a mouth
+	// and nose, such as a mask or a hand.
+	OcclusionLowerFace = "lower_face"
+	// OcclusionEdge flags a face cut by the photo border.
+	OcclusionEdge = "edge"
+)
+
+// FaceQuality holds image quality metrics of a face, measured inside its
+// box.
+type FaceQuality struct {
+	// Sharpness is the variance of the Laplacian, higher is sharper.
+	Sharpness float64 `json:"sharpness" bson:"sharpness"`
+	// Brightness and Contrast are the mean and the standard deviation of the
+	// luma, from 0 to 255.
+	Brightness float64 `json:"brightness" bson:"brightness"`
+	Contrast   float64 `json:"contrast" bson:"contrast"`
+	// Size is the smaller side of the box in pixels.
+	Size int `json:"size" bson:"size"`
+	// Occlusion lists the parts of the face that look covered or cut off.
+	// These are heuristic hints, not a detection.
+	Occlusion []string `json:"occlusion,omitempty" bson:"occlusion,omitempty"`
+}
+
+// QualityFilter selects faces by their quality metrics. Zero values do not
+// filter.
+type QualityFilter struct {
+	MinSharpness  float64 `json:"min_sharpness" form:"min_sharpness" query:"min_sharpness"`
+	MinBrightness float64 `json:"min_brightness" form:"min_brightness" query:"min_brightness"`
+	MaxBrightness float64 `json:"max_brightness" form:"max_brightness" query:"max_brightness"`
+	MinContrast   float64 `json:"min_contrast" form:"min_contrast" query:"min_contrast"`
+	MinFaceSize   int     `json:"min_face_size" form:"min_face_size" query:"min_face_size"`
+	// Unoccluded keeps only faces without occlusion hints.
+	Unoccluded bool `json:"unoccluded" form:"unoccluded" query:"unoccluded"`
+}
+
+// Validate checks that the bounds are not negative and not crossed.
+//
+// Returns:
+// - error: An error object if the filter is invalid, otherwise nil.
+func (f QualityFilter) Validate() error {
+	if f.MinSharpness < 0 || f.MinContrast < 0 || f.MinFaceSize < 0 {
+		return errors.New("min_sharpness, min_contrast and min_face_size must not be negative")
+	}
+	if f.MinBrightness < 0 || f.MinBrightness > 255 || f.MaxBrightness < 0 || f.MaxBrightness > 255 {
+		return errors.New("min_brightness and max_brightness must be between 0 and 255")
+	}
+	if f.MaxBrightness != 0 && f.MaxBrightness < f.MinBrightness {
+		return errors.New("max_brightness must not be smaller than min_brightness")
+	}
+	return nil
+}
+
+// Empty reports whether the filter accepts every face.
+func (f QualityFilter) Empty() bool {
+	return f == QualityFilter{}
+}
+
+// Match reports whether a face with the given quality passes the filter.
+func (f QualityFilter) Match(q FaceQuality) bool {
+	return q.Sharpness >= f.MinSharpness &&
+		q.Brightness >= f.MinBrightness &&
+		(f.MaxBrightness == 0 || q.Brightness <= f.MaxBrightness) &&
+		q.Contrast >= f.MinContrast &&
+		q.Size >= f.MinFaceSize &&
+		(!f.Unoccluded || len(q.Occlusion) == 0)
+}
@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Redaction methods applied to every detected face.
+const (
+	RedactBlur     = "blur"
+	RedactPixelate = "pixelate"
+	RedactBox      = "box"
+)
+
+// RedactionOptions switches processing from the annotated overlay to a
+// redacted variant where every detected face is obscured.
+type RedactionOptions struct {
+	// Method is one of RedactBlur, RedactPixelate or RedactBox. Empty
+	// disables redaction.
+	Method string `json:"method,omitempty" bson:"method,omitempty" form:"redact"`
+	// DiscardOriginal removes the uploaded original once the redacted
+	// variant has been written, or when processing fails.
+	DiscardOriginal bool `json:"discard_original" bson:"discard_original" form:"discard_original"`
+}
+
+// Enabled reports whether a redacted variant should be produced.
+func (o RedactionOptions) Enabled() bool {
+	return o.Method != ""
+}
+
+// Validate checks that the redaction method is supported.
+//
+// Returns:
+// - error: An error object if the options are invalid, otherwise nil.
+func (o RedactionOptions) Validate() error {
+	switch o.Method {
+	case "":
+		if o.DiscardOriginal {
+			return errors.New("discard_original requires redact to be set")
+		}
+		return nil
+	case RedactBlur, RedactPixelate, RedactBox:
+		return nil
+	default:
+		return fmt.Errorf("redact must be one of %q, %q or %q", RedactBlur, RedactPixelate, RedactBox)
+	}
+}
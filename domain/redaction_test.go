@@ -0,0 +1,35 @@
+package domain
+
+import "testing"
+
+func TestRedactionOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        RedactionOptions
+		wantEnabled bool
+		wantErr     string
+	}{
+		{name: "disabled", opts: RedactionOptions{}},
+		{name: "blur", opts: RedactionOptions{Method: RedactBlur}, wantEnabled: true},
+		{name: "pixelate", opts: RedactionOptions{Method: RedactPixelate}, wantEnabled: true},
+		{name: "box discarding the original", opts: RedactionOptions{Method: RedactBox, DiscardOriginal: true}, wantEnabled: true},
+		{name: "unknown method", opts: RedactionOptions{Method: "smudge"}, wantEnabled: true,
+			wantErr: `redact must be one of "blur", "pixelate" or "box"`},
+		{name: "discard without redaction", opts: RedactionOptions{DiscardOriginal: true},
+			wantErr: "discard_original requires redact to be set"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.opts.Enabled(); got != tt.wantEnabled {
+				t.Errorf("Enabled() = %v, want %v", got, tt.wantEnabled)
+			}
+			err := tt.opts.Validate()
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("Validate() error = %v, want nil", err)
+			case tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr):
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
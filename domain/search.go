@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Face search defaults and limits.
+const (
+	DefaultSearchLimit = 10
+	MaxSearchLimit     = 100
+)
+
+// ErrFaceNotFound is returned when a referenced face does not exist or has no
+// embedding.
+var ErrFaceNotFound = errors.New("face not found or not searchable")
+
+// FaceSearchRequest selects the probe face of a search, either an uploaded
+// image or a face of an already processed photo.
+type FaceSearchRequest struct {
+	PhotoID   string `json:"photo_id" form:"photo_id"`
+	FaceIndex int    `json:"face_index" form:"face_index"`
+	// Limit is the number of matches to return.
+	Limit int `json:"k" form:"k"`
+}
+
+// Validate checks that the search limit is within range.
+//
+// Returns:
+// - error: An error object if the request is invalid, otherwise nil.
+func (r FaceSearchRequest) Validate() error {
+	if r.Limit < 1 || r.Limit > MaxSearchLimit {
+		return fmt.Errorf("k must be between 1 and %d", MaxSearchLimit)
+	}
+	if r.FaceIndex < 0 {
+		return errors.New("face_index must not be negative")
+	}
+	return nil
+}
+
+// FaceMatch is a stored face similar to the probe face.
+type FaceMatch struct {
+	PhotoID   PhotoID `json:"photo_id" swaggertype:"string"`
+	FaceIndex int     `json:"face_index"`
+	// Score is the cosine similarity to the probe face, 1 for identical
+	// faces.
+	Score float64 `json:"score"`
+}
+
+// FaceSearchResult lists the matches of a face search by descending score.
+type FaceSearchResult struct {
+	Matches []FaceMatch `json:"matches"`
+}
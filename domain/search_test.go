@@ -0,0 +1,29 @@
+package domain
+
+import "testing"
+
+func TestFaceSearchRequestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     FaceSearchRequest
+		wantErr string
+	}{
+		{name: "defaults", req: FaceSearchRequest{Limit: DefaultSearchLimit}},
+		{name: "face of a photo", req: FaceSearchRequest{PhotoID: "65f1c2a4e13d9b0a7c4b2e10", FaceIndex: 3, Limit: 1}},
+		{name: "largest limit", req: FaceSearchRequest{Limit: MaxSearchLimit}},
+		{name: "zero limit", req: FaceSearchRequest{}, wantErr: "k must be between 1 and 100"},
+		{name: "limit too large", req: FaceSearchRequest{Limit: MaxSearchLimit + 1}, wantErr: "k must be between 1 and 100"},
+		{name: "negative face index", req: FaceSearchRequest{FaceIndex: -1, Limit: 5}, wantErr: "face_index must not be negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("Validate() error = %v, want nil", err)
+			case tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr):
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package domain
+
+// VerifyRequest references stored faces for a verification. Each side is
+// either an uploaded image or a face of an already processed photo.
+type VerifyRequest struct {
+	PhotoIDA   string `json:"photo_id_a" form:"photo_id_a"`
+	FaceIndexA int    `json:"face_index_a" form:"face_index_a"`
+	PhotoIDB   string `json:"photo_id_b" form:"photo_id_b"`
+	FaceIndexB int    `json:"face_index_b" form:"face_index_b"`
+}
+
+// VerifyResult tells how much two faces look alike. The score comes from
+// the LBP texture embedder, not a face recognition model, so it is not an
+// identity decision and no threshold is applied to it.
+type VerifyResult struct {
+	// LookAlikeScore is the cosine similarity of the two face descriptors.
+	LookAlikeScore float64 `json:"look_alike_score"`
+}
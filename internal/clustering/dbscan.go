@@ -0,0 +1,123 @@
+package clustering
+
+import (
+	"context"
+	"math"
+
+	"github.com/anggi-susanto/go-face-detection-be/domain"
+	"github.com/anggi-susanto/go-face-detection-be/internal/embedding"
+)
+
+// Noise labels vectors that belong to no cluster.
+const Noise = -1
+
+const unvisited = -2
+
+// Assigner adds the faces of a newly processed photo to existing clusters.
+type Assigner interface {
+	Assign(ctx context.Context, photo *domain.Photo) error
+}
+
+// Remover drops the faces of a deleted photo from the clusters.
+type Remover interface {
+	RemovePhoto(ctx context.Context, id domain.PhotoID) error
+}
+
+// DBSCAN groups vectors by density: a vector with at least minPts vectors,
+// itself included, within cosine distance eps starts or extends a cluster.
+// It returns the cluster label of every vector, numbered from 0, or Noise.
+//
+// Parameters:
+// - vectors: The vectors to cluster.
+// - eps: The maximum cosine distance, 1 - similarity, between neighbours.
+// - minPts: The minimum neighbourhood size of a core vector.
+//
+// Returns:
+// - []int: The label of every vector.
+func DBSCAN(vectors [][]float32, eps float64, minPts int) []int {
+	labels := make([]int, len(vectors))
+	for i := range labels {
+		labels[i] = unvisited
+	}
+	neighbours := func(i int) []int {
+		var found []int
+		for j := range vectors {
+			if 1-embedding.Cosine(vectors[i], vectors[j]) <= eps {
+				found = append(found, j)
+			}
+		}
+		return found
+	}
+
+	next := 0
+	for i := range vectors {
+		if labels[i] != unvisited {
+			continue
+		}
+		queue := neighbours(i)
+		if len(queue) < minPts {
+			labels[i] = Noise
+			continue
+		}
+		labels[i] = next
+		for k := 0; k < len(queue); k++ {
+			j := queue[k]
+			if labels[j] == Noise {
+				// Border vector, reachable but not dense itself.
+				labels[j] = next
+			}
+			if labels[j] != unvisited {
+				continue
+			}
+			labels[j] = next
+			if found := neighbours(j); len(found) >= minPts {
+				queue = append(queue, found...)
+			}
+		}
+		next++
+	}
+	return labels
+}
+
+// Centroid returns the L2 normalized mean of the vectors.
+func Centroid(vectors [][]float32) []float32 {
+	if len(vectors) == 0 {
+		return nil
+	}
+	sum := make([]float64, len(vectors[0]))
+	for _, v := range vectors {
+		for i := range sum {
+			sum[i] += float64(v[i])
+		}
+	}
+	return normalize(sum)
+}
+
+// AddToCentroid moves the centroid of a cluster of size vectors towards v,
+// weighting the current centroid by the cluster size.
+func AddToCentroid(centroid []float32, size int, v []float32) []float32 {
+	if len(centroid) != len(v) {
+		return Centroid([][]float32{v})
+	}
+	sum := make([]float64, len(v))
+	for i := range sum {
+		sum[i] = float64(centroid[i])*float64(size) + float64(v[i])
+	}
+	return normalize(sum)
+}
+
+func normalize(sum []float64) []float32 {
+	var norm float64
+	for _, x := range sum {
+		norm += x * x
+	}
+	norm = math.Sqrt(norm)
+	out := make([]float32, len(sum))
+	if norm == 0 {
+		return out
+	}
+	for i, x := range sum {
+		out[i] = float32(x / norm)
+	}
+	return out
+}
@@ -0,0 +1,70 @@
+package clustering
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+// unit returns the 2D unit vector at the given angle in degrees.
+func unit(deg float64) []float32 {
+	sin, cos := math.Sincos(deg * math.Pi / 180)
+	return []float32{float32(cos), float32(sin)}
+}
+
+func TestDBSCAN(t *testing.T) {
+	tests := []struct {
+		name    string
+		vectors [][]float32
+		eps     float64
+		minPts  int
+		want    []int
+	}{
+		{name: "no vectors", vectors: nil, eps: 0.05, minPts: 2, want: []int{}},
+		{
+			name:    "two clusters and noise",
+			vectors: [][]float32{unit(0), unit(90), unit(5), unit(180), unit(95)},
+			eps:     0.05, minPts: 2,
+			want: []int{0, 1, 0, Noise, 1},
+		},
+		{
+			name:    "all noise",
+			vectors: [][]float32{unit(0), unit(90), unit(180)},
+			eps:     0.05, minPts: 2,
+			want: []int{Noise, Noise, Noise},
+		},
+		{
+			name:    "minimum of one makes every vector a cluster",
+			vectors: [][]float32{unit(0), unit(90), unit(180)},
+			eps:     0.05, minPts: 1,
+			want: []int{0, 1, 2},
+		},
+		{
+			// Only the middle vector has three neighbours, the first one is
+			// visited as noise before it is reached as a border vector.
+			name:    "border vectors join the cluster",
+			vectors: [][]float32{unit(0), unit(10), unit(20)},
+			eps:     0.02, minPts: 3,
+			want: []int{0, 0, 0},
+		},
+		{
+			name:    "chain of core vectors",
+			vectors: [][]float32{unit(0), unit(10), unit(20), unit(30), unit(40)},
+			eps:     0.02, minPts: 2,
+			want: []int{0, 0, 0, 0, 0},
+		},
+		{
+			name:    "eps wide enough for everything",
+			vectors: [][]float32{unit(0), unit(90), unit(180)},
+			eps:     2, minPts: 3,
+			want: []int{0, 0, 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DBSCAN(tt.vectors, tt.eps, tt.minPts); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DBSCAN() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
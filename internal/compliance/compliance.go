@@ -0,0 +1,146 @@
+package compliance
+
+import (
+	"image"
+	"math"
+
+	"github.com/anggi-susanto/go-face-detection-be/config"
+	"github.com/anggi-susanto/go-face-detection-be/domain"
+	"github.com/anggi-susanto/go-face-detection-be/internal/imaging"
+)
+
+const (
+	// subjectMargin is the width added on each side of the face box, and
+	// hairMargin the height added above it, as fractions of the face size,
+	// to cover the head and shoulders of the subject. Everything else is
+	// background.
+	subjectMargin = 0.5
+	hairMargin    = 0.6
+	// minBackground is the minimum fraction of the photo that must be
+	// background for the uniform background rule to be measured.
+	minBackground = 0.05
+)
+
+// Face is a detected face in the upright photo.
+type Face struct {
+	Box image.Rectangle
+	// Pose is nil when the face was too small to estimate it.
+	Pose *domain.HeadPose
+}
+
+// Check runs every compliance rule on an upright photo. Rules about the face
+// are checked on the largest face and fail when there is none.
+//
+// Parameters:
+// - rules: A pointer to a config.ComplianceConfig object holding the rule bounds.
+// - img: The upright photo.
+// - faces: The faces detected on the photo, in upright photo pixels.
+//
+// Returns:
+// - *domain.ComplianceReport: The per-rule report, passed when every rule passed.
+func Check(rules *config.ComplianceConfig, img image.Image, faces []Face) *domain.ComplianceReport {
+	bounds := img.Bounds()
+	w, h := float64(bounds.Dx()), float64(bounds.Dy())
+
+	results := []domain.ComplianceRule{
+		between(domain.RuleOneFace, float64(len(faces)), 1, 1),
+	}
+	if len(faces) == 0 {
+		for _, rule := range []string{domain.RuleFaceHeight, domain.RuleCentered, domain.RuleTilt, domain.RuleUniformBackground, domain.RuleSharpness, domain.RuleBrightness} {
+			results = append(results, domain.ComplianceRule{Rule: rule, Message: "no face detected"})
+		}
+		return newReport(results)
+	}
+
+	face := largest(faces)
+	box := face.Box.Add(bounds.Min).Intersect(bounds)
+	crop := imaging.Crop(img, box)
+	brightness, _ := imaging.LumaStats(crop)
+	center := float64(face.Box.Min.X+face.Box.Max.X) / 2
+
+	results = append(results,
+		between(domain.RuleFaceHeight, float64(face.Box.Dy())/h, rules.MinFaceRatio, rules.MaxFaceRatio),
+		atMost(domain.RuleCentered, math.Abs(center-w/2)/w, rules.MaxCenterOffset),
+	)
+	if face.Pose != nil {
+		results = append(results, atMost(domain.RuleTilt, math.Abs(face.Pose.Roll), rules.MaxTilt))
+	} else {
+		results = append(results, domain.ComplianceRule{Rule: domain.RuleTilt, Message: "face too small to estimate the head pose"})
+	}
+	if stddev, ok := backgroundStdDev(img, face.Box); ok {
+		results = append(results, atMost(domain.RuleUniformBackground, stddev, rules.MaxBackgroundStdDev))
+	} else {
+		results = append(results, domain.ComplianceRule{Rule: domain.RuleUniformBackground, Message: "not enough background around the subject"})
+	}
+	results = append(results,
+		atLeast(domain.RuleSharpness, imaging.Sharpness(crop), rules.MinSharpness),
+		between(domain.RuleBrightness, brightness, rules.MinBrightness, rules.MaxBrightness),
+	)
+	return newReport(results)
+}
+
+// newReport returns the report of the rule results.
+func newReport(results []domain.ComplianceRule) *domain.ComplianceReport {
+	report := &domain.ComplianceReport{Passed: true, Rules: results}
+	for _, r := range results {
+		report.Passed = report.Passed && r.Passed
+	}
+	return report
+}
+
+func between(rule string, value, min, max float64) domain.ComplianceRule {
+	return domain.ComplianceRule{Rule: rule, Passed: value >= min && value <= max, Value: value, Min: &min, Max: &max}
+}
+
+func atLeast(rule string, value, min float64) domain.ComplianceRule {
+	return domain.ComplianceRule{Rule: rule, Passed: value >= min, Value: value, Min: &min}
+}
+
+func atMost(rule string, value, max float64) domain.ComplianceRule {
+	return domain.ComplianceRule{Rule: rule, Passed: value <= max, Value: value, Max: &max}
+}
+
+// largest returns the face with the largest box.
+func largest(faces []Face) Face {
+	best := faces[0]
+	for _, f := range faces[1:] {
+		if f.Box.Dx()*f.Box.Dy() > best.Box.Dx()*best.Box.Dy() {
+			best = f
+		}
+	}
+	return best
+}
+
+// backgroundStdDev returns the standard deviation of the luma of the photo
+// outside the subject: above the hair and on both sides of the head and
+// shoulders. It reports false when too little background is visible.
+func backgroundStdDev(img image.Image, face image.Rectangle) (float64, bool) {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	marginX := int(subjectMargin * float64(face.Dx()))
+	top := face.Min.Y - int(hairMargin*float64(face.Dy()))
+	left, right := face.Min.X-marginX, face.Max.X+marginX
+
+	regions := []image.Rectangle{
+		image.Rect(0, 0, w, top),
+		image.Rect(0, max(top, 0), left, h),
+		image.Rect(right, max(top, 0), w, h),
+	}
+	var n, sum, sumSq float64
+	for _, r := range regions {
+		r = r.Add(bounds.Min).Intersect(bounds)
+		if r.Empty() {
+			continue
+		}
+		mean, stddev := imaging.LumaStats(imaging.Crop(img, r))
+		count := float64(r.Dx() * r.Dy())
+		n += count
+		sum += count * mean
+		sumSq += count * (stddev*stddev + mean*mean)
+	}
+	if n < minBackground*float64(w*h) {
+		return 0, false
+	}
+	mean := sum / n
+	return math.Sqrt(math.Max(0, sumSq/n-mean*mean)), true
+}
@@ -0,0 +1,143 @@
+package compliance
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+
+	"github.com/anggi-susanto/go-face-detection-be/config"
+	"github.com/anggi-susanto/go-face-detection-be/domain"
+)
+
+var testRules = &config.ComplianceConfig{
+	MinFaceRatio:        0.3,
+	MaxFaceRatio:        0.6,
+	MaxCenterOffset:     0.1,
+	MaxTilt:             10,
+	MaxBackgroundStdDev: 10,
+	MinSharpness:        50,
+	MinBrightness:       60,
+	MaxBrightness:       200,
+}
+
+// testPortrait returns a 200 x 300 photo with the background drawn by bg and
+// a checkerboard of the two luma values inside the face box.
+func testPortrait(face image.Rectangle, bg func(x, y int) uint8, dark, light uint8) image.Image {
+	img := image.NewGray(image.Rect(0, 0, 200, 300))
+	for y := 0; y < 300; y++ {
+		for x := 0; x < 200; x++ {
+			v := bg(x, y)
+			if image.Pt(x, y).In(face) {
+				v = dark
+				if (x+y)%2 == 0 {
+					v = light
+				}
+			}
+			img.SetGray(x, y, color.Gray{Y: v})
+		}
+	}
+	return img
+}
+
+func TestCheck(t *testing.T) {
+	centered := image.Rect(60, 80, 140, 200)
+	uniform := func(x, y int) uint8 { return 220 }
+	stripes := func(x, y int) uint8 { return uint8(x%2) * 255 }
+	level := &domain.HeadPose{}
+
+	tests := []struct {
+		name       string
+		img        image.Image
+		faces      []Face
+		wantFailed []string
+	}{
+		{
+			name:  "compliant",
+			img:   testPortrait(centered, uniform, 0, 255),
+			faces: []Face{{Box: centered, Pose: level}},
+		},
+		{
+			name: "no face",
+			img:  testPortrait(image.Rectangle{}, uniform, 0, 255),
+			wantFailed: []string{
+				domain.RuleOneFace, domain.RuleFaceHeight, domain.RuleCentered, domain.RuleTilt,
+				domain.RuleUniformBackground, domain.RuleSharpness, domain.RuleBrightness,
+			},
+		},
+		{
+			name:       "two faces checks the largest",
+			img:        testPortrait(centered, uniform, 0, 255),
+			faces:      []Face{{Box: image.Rect(0, 0, 10, 10)}, {Box: centered, Pose: level}},
+			wantFailed: []string{domain.RuleOneFace},
+		},
+		{
+			name:       "face too small",
+			img:        testPortrait(image.Rect(70, 100, 130, 160), uniform, 0, 255),
+			faces:      []Face{{Box: image.Rect(70, 100, 130, 160), Pose: level}},
+			wantFailed: []string{domain.RuleFaceHeight},
+		},
+		{
+			name:       "off center",
+			img:        testPortrait(centered.Add(image.Pt(40, 0)), uniform, 0, 255),
+			faces:      []Face{{Box: centered.Add(image.Pt(40, 0)), Pose: level}},
+			wantFailed: []string{domain.RuleCentered},
+		},
+		{
+			name:       "tilted",
+			img:        testPortrait(centered, uniform, 0, 255),
+			faces:      []Face{{Box: centered, Pose: &domain.HeadPose{Roll: -15}}},
+			wantFailed: []string{domain.RuleTilt},
+		},
+		{
+			name:       "unknown pose",
+			img:        testPortrait(centered, uniform, 0, 255),
+			faces:      []Face{{Box: centered}},
+			wantFailed: []string{domain.RuleTilt},
+		},
+		{
+			name:       "busy background",
+			img:        testPortrait(centered, stripes, 0, 255),
+			faces:      []Face{{Box: centered, Pose: level}},
+			wantFailed: []string{domain.RuleUniformBackground},
+		},
+		{
+			name:       "no background",
+			img:        testPortrait(image.Rect(0, 0, 200, 300), uniform, 0, 255),
+			faces:      []Face{{Box: image.Rect(0, 0, 200, 300), Pose: level}},
+			wantFailed: []string{domain.RuleFaceHeight, domain.RuleUniformBackground},
+		},
+		{
+			name:       "blurry",
+			img:        testPortrait(centered, uniform, 128, 128),
+			faces:      []Face{{Box: centered, Pose: level}},
+			wantFailed: []string{domain.RuleSharpness},
+		},
+		{
+			name:       "too dark",
+			img:        testPortrait(centered, uniform, 0, 60),
+			faces:      []Face{{Box: centered, Pose: level}},
+			wantFailed: []string{domain.RuleBrightness},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := Check(testRules, tt.img, tt.faces)
+			if len(report.Rules) != 7 {
+				t.Fatalf("Check() returned %d rules, want 7", len(report.Rules))
+			}
+			var failed []string
+			for _, r := range report.Rules {
+				if !r.Passed {
+					failed = append(failed, r.Rule)
+				}
+			}
+			if !reflect.DeepEqual(failed, tt.wantFailed) {
+				t.Errorf("Check() failed rules = %v, want %v", failed, tt.wantFailed)
+			}
+			if report.Passed != (len(tt.wantFailed) == 0) {
+				t.Errorf("Check() passed = %v with failed rules %v", report.Passed, failed)
+			}
+		})
+	}
+}
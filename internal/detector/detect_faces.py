@@ -0,0 +1,101 @@
+import base64
+import json
+import queue
+import sys
+
+import cv2
+import numpy as np
+
+CASCADE_FILES = {
+    'frontal_default': 'haarcascade_frontalface_default.xml',
+    'frontal_alt': 'haarcascade_frontalface_alt.xml',
+    'frontal_alt2': 'haarcascade_frontalface_alt2.xml',
+    'profile': 'haarcascade_profileface.xml',
+    'eyes': 'haarcascade_eye.xml',
+    'upper_body': 'haarcascade_upperbody.xml',
+    'smile': 'haarcascade_smile.xml',
+}
+
+# CascadeClassifier is not safe for concurrent use, every call borrows its
+# own set of classifiers from the pool. Models are loaded on first use.
+_pool = queue.Queue()
+
+
+def _classifier(classifiers, model):
+    if model not in classifiers:
+        if model not in CASCADE_FILES:
+            raise ValueError('unknown model ' + model)
+        path = cv2.data.haarcascades + CASCADE_FILES[model]
+        classifier = cv2.CascadeClassifier(path)
+        if classifier.empty():
+            raise RuntimeError('failed to load ' + path)
+        classifiers[model] = classifier
+    return classifiers[model]
+
+
+def init(pool_size):
+    for _ in range(max(1, pool_size)):
+        classifiers = {}
+        _classifier(classifiers, 'frontal_default')
+        _pool.put(classifiers)
+
+
+def _run(classifier, gray, params):
+    min_size = params.get('min_size') or 0
+    max_size = params.get('max_size') or 0
+    boxes, _, level_weights = classifier.detectMultiScale3(
+        gray,
+        scaleFactor=params.get('scale_factor') or 1.1,
+        minNeighbors=params.get('min_neighbors', 4),
+        minSize=(min_size, min_size),
+        maxSize=(max_size, max_size),
+        outputRejectLevels=True,
+    )
+    weights = np.ravel(level_weights)
+    return [(int(x), int(y), int(w), int(h), float(weights[i])) for i, (x, y, w, h) in enumerate(boxes)]
+
+
+def _detect(image_b64, params):
+    buf = np.frombuffer(base64.b64decode(image_b64), np.uint8)
+    gray = cv2.imdecode(buf, cv2.IMREAD_GRAYSCALE)
+    if gray is None:
+        raise ValueError('failed to decode image')
+
+    faces = []
+    classifiers = _pool.get()
+    try:
+        for model in params.get('models') or ['frontal_default']:
+            classifier = _classifier(classifiers, model)
+            for (x, y, w, h, score) in _run(classifier, gray, params):
+                faces.append({'x': x, 'y': y, 'w': w, 'h': h, 'score': score, 'model': model})
+            if model == 'profile':
+                # The profile cascade only finds faces turned one way, run
+                # it on the mirrored image as well for the other side.
+                width = gray.shape[1]
+                for (x, y, w, h, score) in _run(classifier, cv2.flip(gray, 1), params):
+                    faces.append({'x': width - x - w, 'y': y, 'w': w, 'h': h, 'score': score, 'model': model})
+    finally:
+        _pool.put(classifiers)
+    return faces
+
+
+def detect_faces(image_b64, params_json):
+    return json.dumps(_detect(image_b64, json.loads(params_json)))
+
+
+def serve():
+    """Answer line-delimited JSON requests {"id", "image", "params"} on stdin
+    with {"id", "faces"} or {"id", "error"} lines on stdout."""
+    init(1)
+    for line in sys.stdin:
+        request = json.loads(line)
+        try:
+            response = {'id': request['id'], 'faces': _detect(request['image'], request['params'])}
+        except Exception as e:
+            response = {'id': request.get('id'), 'error': str(e)}
+        sys.stdout.write(json.dumps(response) + '\n')
+        sys.stdout.flush()
+
+
+if __name__ == '__main__' and '--serve' in getattr(sys, 'argv', []):
+    serve()
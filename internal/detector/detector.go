@@ -0,0 +1,61 @@
+package detector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+
+	"github.com/anggi-susanto/go-face-detection-be/config"
+	"github.com/anggi-susanto/go-face-detection-be/domain"
+)
+
+const (
+	BackendPigo          = "pigo"
+	BackendPython        = "python"
+	BackendPythonProcess = "python-process"
+)
+
+// ErrUnsupportedModel is returned by backends that cannot run a requested
+// cascade model.
+var ErrUnsupportedModel = errors.New("model is not supported by the detector backend")
+
+// Detection is a single face found by a Detector, in pixel coordinates of
+// the image passed to Detect.
+type Detection struct {
+	Rect  image.Rectangle
+	Score float64
+	Model string
+}
+
+// Detector finds faces in an image.
+type Detector interface {
+	Detect(ctx context.Context, img image.Image, params domain.DetectionParams) ([]Detection, error)
+	// Name returns the backend name, such as BackendPigo.
+	Name() string
+	// Supports reports whether the backend can run the cascade model.
+	Supports(model string) bool
+	Close() error
+}
+
+// New creates the Detector selected by the given configuration. An empty
+// backend defaults to the pure-Go pigo detector.
+//
+// Parameters:
+// - config: A pointer to a config.DetectorConfig object selecting the backend.
+//
+// Returns:
+// - Detector: The configured detector.
+// - error: An error object if the backend is unknown or failed to initialize, otherwise nil.
+func New(config *config.DetectorConfig) (Detector, error) {
+	switch config.Backend {
+	case "", BackendPigo:
+		return NewPigoDetector(config.CascadePath)
+	case BackendPython:
+		return NewPythonDetector(config.PoolSize)
+	case BackendPythonProcess:
+		return NewProcessDetector(config.PythonPath, config.PoolSize, config.Timeout)
+	default:
+		return nil, fmt.Errorf("unknown detector backend %q", config.Backend)
+	}
+}
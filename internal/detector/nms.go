@@ -0,0 +1,46 @@
+package detector
+
+import (
+	"image"
+	"sort"
+)
+
+// DefaultNMSThreshold is the IoU above which two detections are considered
+// the same object.
+const DefaultNMSThreshold = 0.3
+
+// NonMaxSuppression keeps the highest scoring detection out of every group of
+// detections overlapping by more than iouThreshold.
+func NonMaxSuppression(dets []Detection, iouThreshold float64) []Detection {
+	sorted := make([]Detection, len(dets))
+	copy(sorted, dets)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Score > sorted[j].Score
+	})
+
+	kept := make([]Detection, 0, len(sorted))
+	for _, d := range sorted {
+		suppressed := false
+		for _, k := range kept {
+			if IoU(d.Rect, k.Rect) > iouThreshold {
+				suppressed = true
+				break
+			}
+		}
+		if !suppressed {
+			kept = append(kept, d)
+		}
+	}
+	return kept
+}
+
+// IoU returns the intersection over union of two rectangles.
+func IoU(a, b image.Rectangle) float64 {
+	inter := a.Intersect(b)
+	if inter.Empty() {
+		return 0
+	}
+	interArea := float64(inter.Dx() * inter.Dy())
+	union := float64(a.Dx()*a.Dy()+b.Dx()*b.Dy()) - interArea
+	return interArea / union
+}
@@ -0,0 +1,70 @@
+package detector
+
+import (
+	"image"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestIoU(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b image.Rectangle
+		want float64
+	}{
+		{name: "identical", a: image.Rect(0, 0, 10, 10), b: image.Rect(0, 0, 10, 10), want: 1},
+		{name: "disjoint", a: image.Rect(0, 0, 10, 10), b: image.Rect(20, 20, 30, 30), want: 0},
+		{name: "touching edges", a: image.Rect(0, 0, 10, 10), b: image.Rect(10, 0, 20, 10), want: 0},
+		{name: "half overlap", a: image.Rect(0, 0, 10, 10), b: image.Rect(5, 0, 15, 10), want: 50.0 / 150},
+		{name: "contained", a: image.Rect(0, 0, 10, 10), b: image.Rect(0, 0, 5, 5), want: 0.25},
+		{name: "empty", a: image.Rectangle{}, b: image.Rect(0, 0, 10, 10), want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IoU(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("IoU(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+			if got := IoU(tt.b, tt.a); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("IoU(%v, %v) = %v, want %v", tt.b, tt.a, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNonMaxSuppression(t *testing.T) {
+	low := Detection{Rect: image.Rect(2, 0, 12, 10), Score: 0.5}
+	high := Detection{Rect: image.Rect(0, 0, 10, 10), Score: 0.9}
+	apart := Detection{Rect: image.Rect(50, 50, 60, 60), Score: 0.7}
+	neighbor := Detection{Rect: image.Rect(5, 0, 15, 10), Score: 0.6}
+
+	tests := []struct {
+		name      string
+		dets      []Detection
+		threshold float64
+		want      []Detection
+	}{
+		{name: "no detections", dets: nil, threshold: DefaultNMSThreshold, want: []Detection{}},
+		{name: "single", dets: []Detection{low}, threshold: DefaultNMSThreshold, want: []Detection{low}},
+		{name: "keeps the highest score", dets: []Detection{low, high}, threshold: DefaultNMSThreshold, want: []Detection{high}},
+		{name: "keeps disjoint sorted by score", dets: []Detection{low, apart, high}, threshold: DefaultNMSThreshold, want: []Detection{high, apart}},
+		{name: "overlap below threshold kept", dets: []Detection{high, neighbor}, threshold: 0.5, want: []Detection{high, neighbor}},
+		{name: "overlap above threshold dropped", dets: []Detection{high, neighbor}, threshold: 0.3, want: []Detection{high}},
+		{name: "suppressed detection does not suppress others", dets: []Detection{
+			{Rect: image.Rect(0, 0, 10, 10), Score: 0.9},
+			{Rect: image.Rect(4, 0, 14, 10), Score: 0.8},
+			{Rect: image.Rect(8, 0, 18, 10), Score: 0.7},
+		}, threshold: 0.3, want: []Detection{
+			{Rect: image.Rect(0, 0, 10, 10), Score: 0.9},
+			{Rect: image.Rect(8, 0, 18, 10), Score: 0.7},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NonMaxSuppression(tt.dets, tt.threshold)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NonMaxSuppression() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,305 @@
+package detector
+
+import (
+	"context"
+	"embed"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"math"
+	"os"
+
+	"github.com/anggi-susanto/go-face-detection-be/domain"
+	"github.com/anggi-susanto/go-face-detection-be/internal/imaging"
+)
+
+// Default pigo cascade run parameters.
+const (
+	pigoMinSize        = 20
+	pigoShiftFactor    = 0.1
+	pigoIoUThreshold   = 0.2
+	pigoScoreThreshold = 5.0
+)
+
+var errInvalidCascade = errors.New("invalid pigo cascade file")
+
+// embeddedCascadeFS holds the cascades vendored with the binary.
+//
+//go:embed cascade
+var embeddedCascadeFS embed.FS
+
+// embeddedFacefinder is the default cascade, used when no cascade path is
+// configured.
+const embeddedFacefinder = "cascade/facefinder"
+
+// pigoCascade is an unpacked pixel intensity comparison (pico/pigo) cascade
+// of binary decision trees.
+type pigoCascade struct {
+	treeDepth     uint32
+	treeNum       uint32
+	treeCodes     []int8
+	treePred      []float32
+	treeThreshold []float32
+}
+
+type pigoDetector struct {
+	cascade *pigoCascade
+}
+
+// NewPigoDetector creates a pure-Go face detector from a pigo cascade file
+// such as "facefinder".
+//
+// Parameters:
+// - cascadePath: The path to the binary cascade file, empty for the embedded facefinder cascade.
+//
+// Returns:
+// - Detector: The pigo detector.
+// - error: An error object if the cascade could not be read or unpacked, otherwise nil.
+func NewPigoDetector(cascadePath string) (Detector, error) {
+	packet, err := readPigoCascade(cascadePath)
+	if err != nil {
+		return nil, err
+	}
+	cascade, err := unpackPigoCascade(packet)
+	if err != nil {
+		return nil, err
+	}
+	return &pigoDetector{cascade: cascade}, nil
+}
+
+// readPigoCascade reads the cascade file at cascadePath, or the embedded
+// facefinder cascade when cascadePath is empty.
+func readPigoCascade(cascadePath string) ([]byte, error) {
+	if cascadePath == "" {
+		packet, err := embeddedCascadeFS.ReadFile(embeddedFacefinder)
+		if err != nil {
+			return nil, fmt.Errorf("no pigo cascade embedded, run make cascade before building or set PIGO_CASCADE_PATH: %w", err)
+		}
+		return packet, nil
+	}
+	packet, err := os.ReadFile(cascadePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pigo cascade: %w", err)
+	}
+	return packet, nil
+}
+
+// Detect runs the cascade over the image. MinNeighbors is applied to the
+// number of raw detections merged into each face. pigo ships a single
+// frontal cascade, which serves every requested frontal model.
+func (d *pigoDetector) Detect(ctx context.Context, img image.Image, params domain.DetectionParams) ([]Detection, error) {
+	model := ""
+	for _, m := range params.ModelList() {
+		if !d.Supports(m) {
+			return nil, fmt.Errorf("%w: %q on pigo", ErrUnsupportedModel, m)
+		}
+		if model == "" {
+			model = m
+		}
+	}
+
+	gray := imaging.Grayscale(img)
+	bounds := img.Bounds()
+	rows, cols := bounds.Dy(), bounds.Dx()
+
+	minSize := pigoMinSize
+	if params.MinSize > 0 {
+		minSize = params.MinSize
+	}
+	maxSize := rows
+	if cols < maxSize {
+		maxSize = cols
+	}
+	if params.MaxSize > 0 && params.MaxSize < maxSize {
+		maxSize = params.MaxSize
+	}
+	scaleFactor := params.ScaleFactor
+	if scaleFactor <= 1 {
+		scaleFactor = domain.DefaultScaleFactor
+	}
+
+	var candidates []pigoDetection
+	for scale := minSize; scale <= maxSize; scale = nextPigoScale(scale, scaleFactor) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		step := int(math.Max(pigoShiftFactor*float64(scale), 1))
+		offset := scale/2 + 1
+		for row := offset; row <= rows-offset; row += step {
+			for col := offset; col <= cols-offset; col += step {
+				q := d.cascade.classifyRegion(row, col, scale, gray.Pix, gray.Stride)
+				if q > 0 {
+					candidates = append(candidates, pigoDetection{row: row, col: col, scale: scale, q: q})
+				}
+			}
+		}
+	}
+
+	var detections []Detection
+	for _, det := range clusterPigoDetections(candidates, pigoIoUThreshold) {
+		if det.q < pigoScoreThreshold || det.neighbors <= params.MinNeighbors {
+			continue
+		}
+		half := det.scale / 2
+		detections = append(detections, Detection{
+			Rect:  image.Rect(det.col-half, det.row-half, det.col-half+det.scale, det.row-half+det.scale).Add(bounds.Min).Intersect(bounds),
+			Score: float64(det.q),
+			Model: model,
+		})
+	}
+	return detections, nil
+}
+
+func (d *pigoDetector) Name() string {
+	return BackendPigo
+}
+
+// Supports reports whether the model is one of the frontal models, all
+// served by the frontal cascade.
+func (d *pigoDetector) Supports(model string) bool {
+	switch model {
+	case domain.ModelFrontalDefault, domain.ModelFrontalAlt, domain.ModelFrontalAlt2:
+		return true
+	default:
+		return false
+	}
+}
+
+func (d *pigoDetector) Close() error {
+	return nil
+}
+
+// unpackPigoCascade decodes the binary cascade format produced by the pico
+// training tools: an 8 byte header, the tree depth and count, then for every
+// tree its node codes, leaf predictions and rejection threshold.
+func unpackPigoCascade(packet []byte) (*pigoCascade, error) {
+	pos := 8
+	if len(packet) < pos+8 {
+		return nil, errInvalidCascade
+	}
+	treeDepth := binary.LittleEndian.Uint32(packet[pos:])
+	pos += 4
+	treeNum := binary.LittleEndian.Uint32(packet[pos:])
+	pos += 4
+	if treeDepth == 0 || treeDepth > 16 {
+		return nil, errInvalidCascade
+	}
+
+	leaves := 1 << treeDepth
+	codeLen := 4*leaves - 4
+	treeLen := codeLen + 4*leaves + 4
+	if uint64(len(packet)-pos) < uint64(treeNum)*uint64(treeLen) {
+		return nil, errInvalidCascade
+	}
+
+	cascade := &pigoCascade{
+		treeDepth:     treeDepth,
+		treeNum:       treeNum,
+		treeCodes:     make([]int8, 0, int(treeNum)*4*leaves),
+		treePred:      make([]float32, 0, int(treeNum)*leaves),
+		treeThreshold: make([]float32, 0, treeNum),
+	}
+	for t := 0; t < int(treeNum); t++ {
+		// The root of every tree is stored at index 1, pad index 0.
+		cascade.treeCodes = append(cascade.treeCodes, 0, 0, 0, 0)
+		for _, b := range packet[pos : pos+codeLen] {
+			cascade.treeCodes = append(cascade.treeCodes, int8(b))
+		}
+		pos += codeLen
+
+		for i := 0; i < leaves; i++ {
+			cascade.treePred = append(cascade.treePred, math.Float32frombits(binary.LittleEndian.Uint32(packet[pos:])))
+			pos += 4
+		}
+
+		cascade.treeThreshold = append(cascade.treeThreshold, math.Float32frombits(binary.LittleEndian.Uint32(packet[pos:])))
+		pos += 4
+	}
+	return cascade, nil
+}
+
+// classifyRegion runs the cascade on the square window of the given size
+// centered at (row, col). It returns a positive score when the window is
+// classified as a face and -1 when it is rejected.
+func (c *pigoCascade) classifyRegion(row, col, scale int, pixels []uint8, stride int) float32 {
+	if c.treeNum == 0 {
+		return 0
+	}
+
+	leaves := 1 << c.treeDepth
+	root := 0
+	r, cl := row*256, col*256
+	var out float32
+	for i := 0; i < int(c.treeNum); i++ {
+		idx := 1
+		for j := 0; j < int(c.treeDepth); j++ {
+			code := c.treeCodes[root+4*idx : root+4*idx+4]
+			x1 := ((r+int(code[0])*scale)>>8)*stride + ((cl + int(code[1])*scale) >> 8)
+			x2 := ((r+int(code[2])*scale)>>8)*stride + ((cl + int(code[3])*scale) >> 8)
+			idx = 2 * idx
+			if pixels[x1] <= pixels[x2] {
+				idx++
+			}
+		}
+		out += c.treePred[leaves*i+idx-leaves]
+		if out <= c.treeThreshold[i] {
+			return -1
+		}
+		root += 4 * leaves
+	}
+	return out - c.treeThreshold[c.treeNum-1]
+}
+
+type pigoDetection struct {
+	row, col, scale int
+	q               float32
+	neighbors       int
+}
+
+// nextPigoScale grows the window size by the scale factor, by at least one
+// pixel so that small factors still make progress.
+func nextPigoScale(scale int, scaleFactor float64) int {
+	next := int(float64(scale) * scaleFactor)
+	if next <= scale {
+		next = scale + 1
+	}
+	return next
+}
+
+// clusterPigoDetections merges overlapping raw detections into a single
+// detection with averaged position and size and accumulated score.
+func clusterPigoDetections(dets []pigoDetection, iouThreshold float64) []pigoDetection {
+	assigned := make([]bool, len(dets))
+	var clusters []pigoDetection
+	for i := range dets {
+		if assigned[i] {
+			continue
+		}
+		var row, col, scale, n int
+		var q float32
+		for j := i; j < len(dets); j++ {
+			if assigned[j] || pigoIoU(dets[i], dets[j]) <= iouThreshold {
+				continue
+			}
+			assigned[j] = true
+			row += dets[j].row
+			col += dets[j].col
+			scale += dets[j].scale
+			q += dets[j].q
+			n++
+		}
+		clusters = append(clusters, pigoDetection{row: row / n, col: col / n, scale: scale / n, q: q, neighbors: n})
+	}
+	return clusters
+}
+
+func pigoIoU(a, b pigoDetection) float64 {
+	overRow := math.Max(0, math.Min(float64(a.row)+float64(a.scale)/2, float64(b.row)+float64(b.scale)/2)-
+		math.Max(float64(a.row)-float64(a.scale)/2, float64(b.row)-float64(b.scale)/2))
+	overCol := math.Max(0, math.Min(float64(a.col)+float64(a.scale)/2, float64(b.col)+float64(b.scale)/2)-
+		math.Max(float64(a.col)-float64(a.scale)/2, float64(b.col)-float64(b.scale)/2))
+	inter := overRow * overCol
+	union := float64(a.scale*a.scale+b.scale*b.scale) - inter
+	return inter / union
+}
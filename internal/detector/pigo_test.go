@@ -0,0 +1,245 @@
+package detector
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/anggi-susanto/go-face-detection-be/domain"
+)
+
+func TestNextPigoScale(t *testing.T) {
+	tests := []struct {
+		name        string
+		scale       int
+		scaleFactor float64
+		want        int
+	}{
+		{name: "grows by the factor", scale: 100, scaleFactor: 1.1, want: 110},
+		{name: "truncates the grown size", scale: 25, scaleFactor: 1.1, want: 27},
+		{name: "small factor still grows", scale: 20, scaleFactor: 1.01, want: 21},
+		{name: "factor of one grows", scale: 20, scaleFactor: 1, want: 21},
+		{name: "shrinking factor grows", scale: 20, scaleFactor: 0.5, want: 21},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextPigoScale(tt.scale, tt.scaleFactor); got != tt.want {
+				t.Errorf("nextPigoScale(%d, %v) = %d, want %d", tt.scale, tt.scaleFactor, got, tt.want)
+			}
+		})
+	}
+}
+
+// pigoPacket builds a cascade packet with the given header and treeNum trees
+// of the given depth, or only the header when trees is false.
+func pigoPacket(treeDepth, treeNum uint32, trees bool) []byte {
+	packet := make([]byte, 16)
+	binary.LittleEndian.PutUint32(packet[8:], treeDepth)
+	binary.LittleEndian.PutUint32(packet[12:], treeNum)
+	if trees {
+		leaves := 1 << treeDepth
+		treeLen := 4*leaves - 4 + 4*leaves + 4
+		packet = append(packet, make([]byte, int(treeNum)*treeLen)...)
+	}
+	return packet
+}
+
+func TestUnpackPigoCascade(t *testing.T) {
+	tests := []struct {
+		name     string
+		packet   []byte
+		wantErr  bool
+		treeNum  uint32
+		wantPred int
+	}{
+		{name: "empty packet", packet: nil, wantErr: true},
+		{name: "truncated header", packet: make([]byte, 12), wantErr: true},
+		{name: "zero depth", packet: pigoPacket(0, 1, false), wantErr: true},
+		{name: "depth too large", packet: pigoPacket(17, 1, false), wantErr: true},
+		{name: "missing trees", packet: pigoPacket(2, 3, false), wantErr: true},
+		{name: "no trees", packet: pigoPacket(2, 0, true), treeNum: 0, wantPred: 0},
+		{name: "valid trees", packet: pigoPacket(2, 3, true), treeNum: 3, wantPred: 12},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cascade, err := unpackPigoCascade(tt.packet)
+			if tt.wantErr {
+				if !errors.Is(err, errInvalidCascade) {
+					t.Fatalf("unpackPigoCascade() error = %v, want %v", err, errInvalidCascade)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unpackPigoCascade() error = %v", err)
+			}
+			if cascade.treeNum != tt.treeNum || len(cascade.treePred) != tt.wantPred || len(cascade.treeThreshold) != int(tt.treeNum) {
+				t.Errorf("unpackPigoCascade() = %d trees, %d predictions, %d thresholds, want %d trees, %d predictions",
+					cascade.treeNum, len(cascade.treePred), len(cascade.treeThreshold), tt.treeNum, tt.wantPred)
+			}
+		})
+	}
+}
+
+func TestPigoSupports(t *testing.T) {
+	tests := []struct {
+		model string
+		want  bool
+	}{
+		{model: domain.ModelFrontalDefault, want: true},
+		{model: domain.ModelFrontalAlt, want: true},
+		{model: domain.ModelFrontalAlt2, want: true},
+		{model: domain.ModelProfile, want: false},
+		{model: domain.ModelEyes, want: false},
+		{model: "", want: false},
+	}
+	d := &pigoDetector{}
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			if got := d.Supports(tt.model); got != tt.want {
+				t.Errorf("Supports(%q) = %v, want %v", tt.model, got, tt.want)
+			}
+		})
+	}
+}
+
+// blobCascade returns a cascade of four depth one trees accepting windows
+// whose center is darker than the points above, below, left and right of it
+// at 3/8 of the window size, a dark blob on a bright background.
+func blobCascade(t *testing.T) *pigoCascade {
+	t.Helper()
+	packet := pigoPacket(1, 4, false)
+	for i, offset := range [][2]int8{{-96, 0}, {96, 0}, {0, -96}, {0, 96}} {
+		// The outer point is compared to the center: outer <= center takes
+		// the second leaf, which rejects the window.
+		packet = append(packet, byte(offset[0]), byte(offset[1]), 0, 0)
+		packet = binary.LittleEndian.AppendUint32(packet, math.Float32bits(5))
+		packet = binary.LittleEndian.AppendUint32(packet, math.Float32bits(-10))
+		packet = binary.LittleEndian.AppendUint32(packet, math.Float32bits(float32(5*i+1)))
+	}
+	cascade, err := unpackPigoCascade(packet)
+	if err != nil {
+		t.Fatalf("unpackPigoCascade() error = %v", err)
+	}
+	return cascade
+}
+
+// blobImage returns a bright 100 x 100 image with a dark square at blob.
+func blobImage(blob image.Rectangle) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, 100, 100))
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 100; x++ {
+			v := uint8(255)
+			if image.Pt(x, y).In(blob) {
+				v = 0
+			}
+			img.SetGray(x, y, color.Gray{Y: v})
+		}
+	}
+	return img
+}
+
+func TestPigoClassifyRegion(t *testing.T) {
+	cascade := blobCascade(t)
+	blob := blobImage(image.Rect(35, 35, 65, 65))
+	uniform := blobImage(image.Rectangle{})
+
+	tests := []struct {
+		name            string
+		img             *image.Gray
+		row, col, scale int
+		want            float32
+	}{
+		{name: "window on the blob", img: blob, row: 50, col: 50, scale: 60, want: 4},
+		{name: "window smaller than the blob", img: blob, row: 50, col: 50, scale: 30, want: -1},
+		{name: "window off the blob", img: blob, row: 20, col: 20, scale: 30, want: -1},
+		{name: "uniform image", img: uniform, row: 50, col: 50, scale: 60, want: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cascade.classifyRegion(tt.row, tt.col, tt.scale, tt.img.Pix, tt.img.Stride); got != tt.want {
+				t.Errorf("classifyRegion(%d, %d, %d) = %v, want %v", tt.row, tt.col, tt.scale, got, tt.want)
+			}
+		})
+	}
+
+	empty := &pigoCascade{}
+	if got := empty.classifyRegion(50, 50, 60, blob.Pix, blob.Stride); got != 0 {
+		t.Errorf("classifyRegion() without trees = %v, want 0", got)
+	}
+}
+
+func TestPigoDetect(t *testing.T) {
+	d := &pigoDetector{cascade: blobCascade(t)}
+
+	tests := []struct {
+		name    string
+		img     image.Image
+		params  func(p *domain.DetectionParams)
+		want    []image.Point
+		wantErr error
+	}{
+		{name: "blob in the center", img: blobImage(image.Rect(35, 35, 65, 65)), want: []image.Point{image.Pt(50, 50)}},
+		{name: "blob off center", img: blobImage(image.Rect(15, 45, 45, 75)), want: []image.Point{image.Pt(30, 60)}},
+		{name: "uniform image", img: blobImage(image.Rectangle{})},
+		{
+			name:   "too few neighbors",
+			img:    blobImage(image.Rect(35, 35, 65, 65)),
+			params: func(p *domain.DetectionParams) { p.MinNeighbors = 1000 },
+		},
+		{
+			name:   "windows smaller than the blob",
+			img:    blobImage(image.Rect(35, 35, 65, 65)),
+			params: func(p *domain.DetectionParams) { p.MaxSize = 35 },
+		},
+		{
+			name:    "unsupported model",
+			img:     blobImage(image.Rect(35, 35, 65, 65)),
+			params:  func(p *domain.DetectionParams) { p.Models = []string{domain.ModelProfile} },
+			wantErr: ErrUnsupportedModel,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := domain.DefaultDetectionParams()
+			params.MinNeighbors = 0
+			if tt.params != nil {
+				tt.params(&params)
+			}
+			got, err := d.Detect(context.Background(), tt.img, params)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Detect() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Detect() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Detect() = %v, want faces at %v", got, tt.want)
+			}
+			for i, det := range got {
+				center := image.Pt((det.Rect.Min.X+det.Rect.Max.X)/2, (det.Rect.Min.Y+det.Rect.Max.Y)/2)
+				if dx, dy := center.X-tt.want[i].X, center.Y-tt.want[i].Y; dx*dx+dy*dy > 25 {
+					t.Errorf("face %d centered at %v, want %v", i, center, tt.want[i])
+				}
+				if det.Model != domain.ModelFrontalDefault {
+					t.Errorf("face %d model = %q, want %q", i, det.Model, domain.ModelFrontalDefault)
+				}
+			}
+		})
+	}
+}
+
+func TestPigoDetectCanceled(t *testing.T) {
+	d := &pigoDetector{cascade: blobCascade(t)}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := d.Detect(ctx, blobImage(image.Rect(35, 35, 65, 65)), domain.DefaultDetectionParams()); !errors.Is(err, context.Canceled) {
+		t.Errorf("Detect() error = %v, want %v", err, context.Canceled)
+	}
+}
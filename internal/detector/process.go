@@ -0,0 +1,241 @@
+package detector
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"github.com/anggi-susanto/go-face-detection-be/domain"
+	"github.com/anggi-susanto/go-face-detection-be/internal/imaging"
+	"github.com/sirupsen/logrus"
+)
+
+const defaultProcessTimeout = 30 * time.Second
+
+// processRequest is a single line sent to a detection child process.
+type processRequest struct {
+	ID     uint64                 `json:"id"`
+	Image  string                 `json:"image"`
+	Params domain.DetectionParams `json:"params"`
+}
+
+// processResponse is a single line answered by a detection child process.
+type processResponse struct {
+	ID    uint64       `json:"id"`
+	Faces []pythonFace `json:"faces"`
+	Error string       `json:"error"`
+}
+
+// pythonProcess is a running detection child process.
+type pythonProcess struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+}
+
+// processDetector runs the detection script in supervised child processes,
+// so that a crash in OpenCV or the interpreter only fails the affected job.
+// Each slot holds at most one process, which is started lazily and replaced
+// after a crash or timeout; the number of slots bounds concurrency.
+type processDetector struct {
+	pythonPath string
+	timeout    time.Duration
+	slots      chan *pythonProcess
+	nextID     atomic.Uint64
+}
+
+// NewProcessDetector creates a detector that talks to python child processes
+// over a line-delimited JSON protocol on stdin and stdout.
+//
+// Parameters:
+// - pythonPath: The python executable, "python3" when empty.
+// - poolSize: The maximum number of child processes and concurrent detections.
+// - timeout: The maximum duration of a single detection call.
+//
+// Returns:
+// - Detector: The process detector.
+// - error: An error object if the first child process could not be started, otherwise nil.
+func NewProcessDetector(pythonPath string, poolSize int, timeout time.Duration) (Detector, error) {
+	if pythonPath == "" {
+		pythonPath = "python3"
+	}
+	if poolSize < 1 {
+		poolSize = 1
+	}
+	if timeout <= 0 {
+		timeout = defaultProcessTimeout
+	}
+	d := &processDetector{
+		pythonPath: pythonPath,
+		timeout:    timeout,
+		slots:      make(chan *pythonProcess, poolSize),
+	}
+
+	// Fail fast on a broken python setup, the other slots start on demand.
+	proc, err := d.start()
+	if err != nil {
+		return nil, err
+	}
+	d.slots <- proc
+	for i := 1; i < poolSize; i++ {
+		d.slots <- nil
+	}
+	return d, nil
+}
+
+func (d *processDetector) Detect(ctx context.Context, img image.Image, params domain.DetectionParams) ([]Detection, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, imaging.Grayscale(img)); err != nil {
+		return nil, err
+	}
+	req := processRequest{
+		ID:     d.nextID.Add(1),
+		Image:  base64.StdEncoding.EncodeToString(buf.Bytes()),
+		Params: params,
+	}
+
+	var proc *pythonProcess
+	select {
+	case proc = <-d.slots:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	if proc == nil {
+		var err error
+		if proc, err = d.start(); err != nil {
+			d.slots <- nil
+			return nil, err
+		}
+	}
+
+	resp, err := d.call(ctx, proc, req)
+	if err != nil {
+		// The process state is unknown after a failed exchange, replace it.
+		proc.kill()
+		d.slots <- nil
+		return nil, err
+	}
+	d.slots <- proc
+
+	if resp.Error != "" {
+		return nil, fmt.Errorf("detect_faces failed: %s", resp.Error)
+	}
+	return toDetections(resp.Faces, img.Bounds()), nil
+}
+
+// call sends one request to the process and waits for its response, the
+// call timeout or the context, whichever comes first.
+func (d *processDetector) call(ctx context.Context, proc *pythonProcess, req processRequest) (*processResponse, error) {
+	line, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	type result struct {
+		resp *processResponse
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		if _, err := proc.stdin.Write(append(line, '\n')); err != nil {
+			done <- result{err: fmt.Errorf("python worker crashed: %w", err)}
+			return
+		}
+		out, err := proc.stdout.ReadBytes('\n')
+		if err != nil {
+			done <- result{err: fmt.Errorf("python worker crashed: %w", err)}
+			return
+		}
+		var resp processResponse
+		if err := json.Unmarshal(out, &resp); err != nil {
+			done <- result{err: fmt.Errorf("invalid python worker response: %w", err)}
+			return
+		}
+		if resp.ID != req.ID {
+			done <- result{err: fmt.Errorf("python worker answered request %d instead of %d", resp.ID, req.ID)}
+			return
+		}
+		done <- result{resp: &resp}
+	}()
+
+	timer := time.NewTimer(d.timeout)
+	defer timer.Stop()
+	select {
+	case r := <-done:
+		return r.resp, r.err
+	case <-timer.C:
+		return nil, fmt.Errorf("python worker timed out after %s", d.timeout)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// start launches a new detection child process.
+func (d *processDetector) start() (*pythonProcess, error) {
+	cmd := exec.Command(d.pythonPath, "-u", "-c", detectFacesScript, "--serve")
+	cmd.Stderr = logrus.StandardLogger().WriterLevel(logrus.WarnLevel)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start python worker: %w", err)
+	}
+	logrus.Infof("Started python worker pid %d", cmd.Process.Pid)
+
+	return &pythonProcess{
+		cmd:    cmd,
+		stdin:  stdin,
+		stdout: bufio.NewReader(stdout),
+	}, nil
+}
+
+// kill stops the process and reaps it.
+func (p *pythonProcess) kill() {
+	p.stdin.Close()
+	if err := p.cmd.Process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		logrus.Errorf("Failed to kill python worker: %v", err)
+	}
+	go p.cmd.Wait()
+}
+
+func (d *processDetector) Name() string {
+	return BackendPythonProcess
+}
+
+// Supports reports whether the model is one of the OpenCV cascades loaded
+// by the script, which covers every cascade model.
+func (d *processDetector) Supports(model string) bool {
+	return slices.Contains(domain.CascadeModels, model)
+}
+
+// Close stops every child process once its running detection is done.
+func (d *processDetector) Close() error {
+	for i := 0; i < cap(d.slots); i++ {
+		if proc := <-d.slots; proc != nil {
+			proc.stdin.Close()
+			if err := proc.cmd.Wait(); err != nil {
+				logrus.Errorf("Python worker exited: %v", err)
+			}
+		}
+	}
+	return nil
+}
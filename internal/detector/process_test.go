@@ -0,0 +1,214 @@
+package detector
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/anggi-susanto/go-face-detection-be/domain"
+)
+
+// fakePythonEnv switches the test binary into a fake detection child
+// process, its value selects the behaviour of the child.
+const fakePythonEnv = "FAKE_PYTHON_WORKER"
+
+// fakePythonMarkerEnv names the file the "crash-once" child creates before
+// crashing, so that its replacement serves normally.
+const fakePythonMarkerEnv = "FAKE_PYTHON_MARKER"
+
+func TestMain(m *testing.M) {
+	if mode := os.Getenv(fakePythonEnv); mode != "" {
+		os.Exit(fakePythonWorker(mode))
+	}
+	os.Exit(m.Run())
+}
+
+// fakePythonWorker answers detection requests on stdin like the detect_faces
+// script does in serve mode, with one face at (10, 20) of size 30 x 40.
+func fakePythonWorker(mode string) int {
+	if mode == "crash-once" {
+		marker := os.Getenv(fakePythonMarkerEnv)
+		if _, err := os.Stat(marker); err != nil {
+			os.WriteFile(marker, nil, 0o644)
+			return 3
+		}
+		mode = "ok"
+	}
+	in := bufio.NewScanner(os.Stdin)
+	in.Buffer(make([]byte, 1<<20), 1<<24)
+	for in.Scan() {
+		var req processRequest
+		if err := json.Unmarshal(in.Bytes(), &req); err != nil {
+			return 2
+		}
+		resp := processResponse{ID: req.ID, Faces: []pythonFace{{X: 10, Y: 20, W: 30, H: 40, Score: 5, Model: domain.ModelFrontalDefault}}}
+		switch mode {
+		case "crash":
+			return 3
+		case "hang":
+			time.Sleep(time.Hour)
+		case "error":
+			resp = processResponse{ID: req.ID, Error: "cv2 exploded"}
+		case "wrong-id":
+			resp.ID++
+		case "garbage":
+			fmt.Println("not json")
+			continue
+		}
+		line, _ := json.Marshal(resp)
+		fmt.Println(string(line))
+	}
+	return 0
+}
+
+// newFakeProcessDetector starts a process detector whose children run the
+// test binary in the given mode.
+func newFakeProcessDetector(t *testing.T, mode string, poolSize int, timeout time.Duration) *processDetector {
+	t.Helper()
+	t.Setenv(fakePythonEnv, mode)
+	t.Setenv(fakePythonMarkerEnv, filepath.Join(t.TempDir(), "crashed"))
+	d, err := NewProcessDetector(os.Args[0], poolSize, timeout)
+	if err != nil {
+		t.Fatalf("NewProcessDetector() error = %v", err)
+	}
+	t.Cleanup(func() { d.Close() })
+	return d.(*processDetector)
+}
+
+func TestProcessDetectorDetect(t *testing.T) {
+	bounds := image.Rect(5, 5, 105, 105)
+	tests := []struct {
+		name    string
+		mode    string
+		want    []Detection
+		wantErr string
+	}{
+		{name: "faces", mode: "ok", want: []Detection{{Rect: image.Rect(15, 25, 45, 65), Score: 5, Model: domain.ModelFrontalDefault}}},
+		{name: "script error", mode: "error", wantErr: "detect_faces failed: cv2 exploded"},
+		{name: "crash", mode: "crash", wantErr: "python worker crashed"},
+		{name: "timeout", mode: "hang", wantErr: "python worker timed out"},
+		{name: "other request answered", mode: "wrong-id", wantErr: "instead of"},
+		{name: "invalid response", mode: "garbage", wantErr: "invalid python worker response"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newFakeProcessDetector(t, tt.mode, 1, 500*time.Millisecond)
+			got, err := d.Detect(context.Background(), image.NewGray(bounds), domain.DefaultDetectionParams())
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Detect() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Detect() error = %v", err)
+			}
+			if len(got) != len(tt.want) || got[0] != tt.want[0] {
+				t.Errorf("Detect() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessDetectorRestart(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     string
+		wantErrs []bool
+	}{
+		{name: "crash fails only the affected call", mode: "crash-once", wantErrs: []bool{true, false, false}},
+		{name: "every call crashes", mode: "crash", wantErrs: []bool{true, true}},
+		{name: "script errors keep the worker", mode: "error", wantErrs: []bool{true, true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newFakeProcessDetector(t, tt.mode, 1, 500*time.Millisecond)
+			for i, wantErr := range tt.wantErrs {
+				_, err := d.Detect(context.Background(), image.NewGray(image.Rect(0, 0, 8, 8)), domain.DefaultDetectionParams())
+				if (err != nil) != wantErr {
+					t.Errorf("Detect() call %d error = %v, want error %v", i, err, wantErr)
+				}
+			}
+		})
+	}
+}
+
+func TestProcessDetectorCanceled(t *testing.T) {
+	tests := []struct {
+		name string
+		busy bool
+		mode string
+	}{
+		{name: "waiting for a worker", busy: true, mode: "ok"},
+		{name: "waiting for the response", mode: "hang"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newFakeProcessDetector(t, tt.mode, 1, time.Minute)
+			if tt.busy {
+				// Hold the only worker so that Detect has to wait.
+				proc := <-d.slots
+				defer func() { d.slots <- proc }()
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			if _, err := d.Detect(ctx, image.NewGray(image.Rect(0, 0, 8, 8)), domain.DefaultDetectionParams()); !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("Detect() error = %v, want %v", err, context.DeadlineExceeded)
+			}
+		})
+	}
+}
+
+func TestProcessDetectorConcurrencyLimit(t *testing.T) {
+	tests := []struct {
+		name     string
+		poolSize int
+		calls    int
+	}{
+		{name: "single worker", poolSize: 1, calls: 4},
+		{name: "fewer calls than workers", poolSize: 4, calls: 2},
+		{name: "more calls than workers", poolSize: 2, calls: 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newFakeProcessDetector(t, "ok", tt.poolSize, 5*time.Second)
+			var wg sync.WaitGroup
+			errs := make([]error, tt.calls)
+			for i := 0; i < tt.calls; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					_, errs[i] = d.Detect(context.Background(), image.NewGray(image.Rect(0, 0, 8, 8)), domain.DefaultDetectionParams())
+				}(i)
+			}
+			wg.Wait()
+			for i, err := range errs {
+				if err != nil {
+					t.Errorf("Detect() call %d error = %v", i, err)
+				}
+			}
+			if got := cap(d.slots); got != tt.poolSize {
+				t.Errorf("worker slots = %d, want %d", got, tt.poolSize)
+			}
+			if got := len(d.slots); got != tt.poolSize {
+				t.Errorf("%d of %d workers returned to the pool", got, tt.poolSize)
+			}
+		})
+	}
+}
+
+func TestNewProcessDetectorFails(t *testing.T) {
+	if d, err := NewProcessDetector(filepath.Join(t.TempDir(), "no-python"), 2, time.Second); err == nil {
+		d.Close()
+		t.Fatal("NewProcessDetector() error = nil, want the start failure")
+	}
+}
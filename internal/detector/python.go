@@ -0,0 +1,183 @@
+//go:build python
+
+package detector
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"runtime"
+	"slices"
+
+	"github.com/anggi-susanto/go-face-detection-be/domain"
+	"github.com/anggi-susanto/go-face-detection-be/internal/imaging"
+
+	python3 "github.com/DataDog/go-python3"
+)
+
+// pythonDetector runs detection in a single long-lived embedded interpreter.
+// The interpreter is owned by a goroutine locked to its OS thread; Detect
+// calls take the GIL from their own locked thread, and OpenCV releases it
+// while the cascade runs, so up to poolSize detections proceed in parallel.
+type pythonDetector struct {
+	detectFaces *python3.PyObject
+	slots       chan struct{}
+	done        chan struct{}
+	closed      chan struct{}
+}
+
+// NewPythonDetector starts the embedded interpreter, loads the detection
+// script and a pool of OpenCV classifiers once, and returns a detector that
+// is safe for concurrent use.
+//
+// Parameters:
+// - poolSize: The number of classifiers to load and of concurrent detections.
+//
+// Returns:
+// - Detector: The python detector.
+// - error: An error object if the interpreter or the script failed to initialize, otherwise nil.
+func NewPythonDetector(poolSize int) (Detector, error) {
+	if poolSize < 1 {
+		poolSize = 1
+	}
+	d := &pythonDetector{
+		slots:  make(chan struct{}, poolSize),
+		done:   make(chan struct{}),
+		closed: make(chan struct{}),
+	}
+
+	ready := make(chan error)
+	go d.run(poolSize, ready)
+	if err := <-ready; err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// run owns the interpreter lifecycle. It must stay on one OS thread because
+// the main thread state is saved and restored there.
+func (d *pythonDetector) run(poolSize int, ready chan<- error) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	defer close(d.closed)
+
+	python3.Py_Initialize()
+	if err := d.load(poolSize); err != nil {
+		python3.Py_Finalize()
+		ready <- err
+		return
+	}
+
+	// Release the GIL so that Detect can acquire it from other threads.
+	state := python3.PyEval_SaveThread()
+	ready <- nil
+
+	<-d.done
+	python3.PyEval_RestoreThread(state)
+	d.detectFaces.DecRef()
+	python3.Py_Finalize()
+}
+
+func (d *pythonDetector) load(poolSize int) error {
+	if python3.PyRun_SimpleString(detectFacesScript) != 0 {
+		return errors.New("Failed to load detect_faces script")
+	}
+
+	pythonModule := python3.PyImport_AddModule("__main__")
+
+	initPool := pythonModule.GetAttrString("init")
+	if initPool == nil {
+		return errors.New("Failed to load function init")
+	}
+	defer initPool.DecRef()
+
+	args := python3.PyTuple_New(1)
+	defer args.DecRef()
+	python3.PyTuple_SetItem(args, 0, python3.PyLong_FromGoInt(poolSize))
+	result := initPool.CallObject(args)
+	if result == nil {
+		python3.PyErr_Print()
+		return errors.New("Failed to initialize classifiers")
+	}
+	result.DecRef()
+
+	d.detectFaces = pythonModule.GetAttrString("detect_faces")
+	if d.detectFaces == nil {
+		return errors.New("Failed to load function detect_faces")
+	}
+	return nil
+}
+
+func (d *pythonDetector) Detect(ctx context.Context, img image.Image, params domain.DetectionParams) ([]Detection, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, imaging.Grayscale(img)); err != nil {
+		return nil, err
+	}
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case d.slots <- struct{}{}:
+		defer func() { <-d.slots }()
+	case <-d.done:
+		return nil, errors.New("python detector closed")
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	out, err := d.call(base64.StdEncoding.EncodeToString(buf.Bytes()), string(paramsJSON))
+	if err != nil {
+		return nil, err
+	}
+	return decodePythonFaces(out, img.Bounds())
+}
+
+// call invokes detect_faces with the GIL held by the current OS thread.
+func (d *pythonDetector) call(imageB64, paramsJSON string) (string, error) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	gil := python3.PyGILState_Ensure()
+	defer python3.PyGILState_Release(gil)
+
+	args := python3.PyTuple_New(2)
+	defer args.DecRef()
+	python3.PyTuple_SetItem(args, 0, python3.PyUnicode_FromString(imageB64))
+	python3.PyTuple_SetItem(args, 1, python3.PyUnicode_FromString(paramsJSON))
+
+	result := d.detectFaces.CallObject(args)
+	if result == nil {
+		python3.PyErr_Print()
+		return "", fmt.Errorf("Failed to call function detect_faces")
+	}
+	defer result.DecRef()
+
+	return python3.PyUnicode_AsUTF8(result), nil
+}
+
+func (d *pythonDetector) Name() string {
+	return BackendPython
+}
+
+// Supports reports whether the model is one of the OpenCV cascades loaded
+// by the script, which covers every cascade model.
+func (d *pythonDetector) Supports(model string) bool {
+	return slices.Contains(domain.CascadeModels, model)
+}
+
+// Close waits for running detections and shuts the interpreter down.
+func (d *pythonDetector) Close() error {
+	for i := 0; i < cap(d.slots); i++ {
+		d.slots <- struct{}{}
+	}
+	close(d.done)
+	<-d.closed
+	return nil
+}
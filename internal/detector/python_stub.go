@@ -0,0 +1,10 @@
+//go:build !python
+
+package detector
+
+import "errors"
+
+// NewPythonDetector is unavailable in builds without the "python" tag.
+func NewPythonDetector(poolSize int) (Detector, error) {
+	return nil, errors.New("python detector backend not available: rebuild with -tags python")
+}
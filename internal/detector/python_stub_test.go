@@ -0,0 +1,13 @@
+//go:build !python
+
+package detector
+
+import "testing"
+
+func TestNewPythonDetectorUnavailable(t *testing.T) {
+	for _, poolSize := range []int{0, 1, 4} {
+		if d, err := NewPythonDetector(poolSize); err == nil || d != nil {
+			t.Errorf("NewPythonDetector(%d) = %v, %v, want an error without the python tag", poolSize, d, err)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+//go:build python
+
+package detector
+
+import (
+	"context"
+	"errors"
+	"image"
+	"sync"
+	"testing"
+
+	"github.com/anggi-susanto/go-face-detection-be/domain"
+)
+
+// newTestPythonDetector starts the embedded interpreter, skipping the test
+// when OpenCV is not installed.
+func newTestPythonDetector(t *testing.T, poolSize int) Detector {
+	t.Helper()
+	d, err := NewPythonDetector(poolSize)
+	if err != nil {
+		t.Skipf("python detector unavailable: %v", err)
+	}
+	return d
+}
+
+func TestPythonDetectorConcurrent(t *testing.T) {
+	tests := []struct {
+		name     string
+		poolSize int
+		calls    int
+	}{
+		{name: "single classifier", poolSize: 1, calls: 4},
+		{name: "more calls than classifiers", poolSize: 2, calls: 8},
+		{name: "pool size clamped", poolSize: 0, calls: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newTestPythonDetector(t, tt.poolSize)
+			defer d.Close()
+
+			blank := image.NewGray(image.Rect(0, 0, 64, 64))
+			var wg sync.WaitGroup
+			errs := make([]error, tt.calls)
+			found := make([][]Detection, tt.calls)
+			for i := 0; i < tt.calls; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					found[i], errs[i] = d.Detect(context.Background(), blank, domain.DefaultDetectionParams())
+				}(i)
+			}
+			wg.Wait()
+			for i := range errs {
+				if errs[i] != nil {
+					t.Fatalf("Detect() call %d error = %v", i, errs[i])
+				}
+				if len(found[i]) != 0 {
+					t.Errorf("Detect() call %d found %d faces on a blank image", i, len(found[i]))
+				}
+			}
+		})
+	}
+}
+
+func TestPythonDetectorUnavailable(t *testing.T) {
+	tests := []struct {
+		name    string
+		prepare func(d *pythonDetector) context.Context
+		wantErr error
+	}{
+		{name: "canceled while the pool is busy", prepare: func(d *pythonDetector) context.Context {
+			// Hold the only classifier so that Detect has to wait.
+			d.slots <- struct{}{}
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			return ctx
+		}, wantErr: context.Canceled},
+		{name: "closed", prepare: func(d *pythonDetector) context.Context {
+			d.Close()
+			// Close holds every slot, so Detect can only see done.
+			return context.Background()
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newTestPythonDetector(t, 1).(*pythonDetector)
+			ctx := tt.prepare(d)
+			_, err := d.Detect(ctx, image.NewGray(image.Rect(0, 0, 8, 8)), domain.DefaultDetectionParams())
+			if err == nil || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+				t.Errorf("Detect() error = %v, want %v", err, tt.wantErr)
+			}
+			select {
+			case <-d.done:
+			default:
+				<-d.slots
+				d.Close()
+			}
+		})
+	}
+}
+
+func TestPythonSupports(t *testing.T) {
+	d := newTestPythonDetector(t, 1)
+	defer d.Close()
+	for _, model := range domain.CascadeModels {
+		if !d.Supports(model) {
+			t.Errorf("Supports(%q) = false, want true", model)
+		}
+	}
+	if d.Supports("unknown") {
+		t.Error(`Supports("unknown") = true, want false`)
+	}
+}
@@ -0,0 +1,90 @@
+package detector
+
+import (
+	"context"
+	"image"
+
+	"github.com/anggi-susanto/go-face-detection-be/domain"
+	"github.com/anggi-susanto/go-face-detection-be/internal/imaging"
+)
+
+// Rotations tried after the upright pass, in degrees clockwise.
+var (
+	retryRotations = []float64{90, 180, 270}
+	retryTilts     = []float64{-15, 15}
+)
+
+// DetectFunc runs a detection pass over an image.
+type DetectFunc func(ctx context.Context, img image.Image) ([]Detection, error)
+
+// DetectRotated runs detect on the upright image and, depending on
+// params.RotationRetry, on rotated copies of it. The orientation with the
+// most detections wins, ties go to the highest total score. Detections are
+// mapped back to the coordinate space of img, which must have its origin at
+// (0, 0), and the chosen clockwise rotation in degrees is returned.
+//
+// Parameters:
+// - ctx: The context.Context object for the function.
+// - detect: The detection pass to run for every orientation.
+// - img: The image to scan.
+// - params: The detection parameters selecting the retry mode.
+//
+// Returns:
+// - []Detection: The detections of the best orientation.
+// - float64: The rotation of the best orientation.
+// - error: An error object if any pass failed, otherwise nil.
+func DetectRotated(ctx context.Context, detect DetectFunc, img image.Image, params domain.DetectionParams) ([]Detection, float64, error) {
+	best, err := detect(ctx, img)
+	if err != nil {
+		return nil, 0, err
+	}
+	bestAngle := 0.0
+
+	switch params.RotationRetry {
+	case domain.RotationRetryOnEmpty:
+		if len(best) > 0 {
+			return best, 0, nil
+		}
+	case domain.RotationRetryExhaustive:
+	default:
+		return best, 0, nil
+	}
+
+	angles := retryRotations
+	if params.RotationTilt {
+		angles = append(append([]float64{}, retryRotations...), retryTilts...)
+	}
+
+	bounds := img.Bounds()
+	for _, angle := range angles {
+		dets, err := detect(ctx, imaging.Rotate(img, angle))
+		if err != nil {
+			return nil, 0, err
+		}
+		if !better(dets, best) {
+			continue
+		}
+		for i := range dets {
+			dets[i].Rect = imaging.UnrotateRect(dets[i].Rect, angle, bounds.Dx(), bounds.Dy())
+		}
+		best, bestAngle = dets, angle
+	}
+	return best, bestAngle, nil
+}
+
+// better reports whether a finds more faces than b, or as many with a higher
+// total score.
+func better(a, b []Detection) bool {
+	if len(a) != len(b) {
+		return len(a) > len(b)
+	}
+	return totalScore(a) > totalScore(b)
+}
+
+func totalScore(dets []Detection) float64 {
+	var total float64
+	for _, d := range dets {
+		total += d.Score
+	}
+	return total
+}
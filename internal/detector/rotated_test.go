@@ -0,0 +1,140 @@
+package detector
+
+import (
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"slices"
+	"testing"
+
+	"github.com/anggi-susanto/go-face-detection-be/domain"
+)
+
+// markerDetector finds the bright marker pixel, but only while it lies in the
+// top left quadrant, like a cascade that only finds upright faces. It records
+// the size of every image it scans and fails the failAt-th scan, counted
+// from one, when failAt is set.
+type markerDetector struct {
+	scanned []image.Point
+	failAt  int
+}
+
+var errDetect = errors.New("detector failed")
+
+func (d *markerDetector) detect(ctx context.Context, img image.Image) ([]Detection, error) {
+	b := img.Bounds()
+	d.scanned = append(d.scanned, b.Size())
+	if len(d.scanned) == d.failAt {
+		return nil, errDetect
+	}
+	for y := b.Min.Y; y < b.Min.Y+b.Dy()/2; y++ {
+		for x := b.Min.X; x < b.Min.X+b.Dx()/2; x++ {
+			if r, _, _, _ := img.At(x, y).RGBA(); r > 0x8000 {
+				return []Detection{{Rect: image.Rect(x-2, y-2, x+3, y+3), Score: 1, Model: domain.ModelFrontalDefault}}, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// markerImage returns a 60 x 40 image with a bright marker at p.
+func markerImage(p image.Point) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, 60, 40))
+	img.SetGray(p.X, p.Y, color.Gray{Y: 255})
+	return img
+}
+
+func TestDetectRotated(t *testing.T) {
+	topLeft, topRight := image.Pt(10, 8), image.Pt(50, 8)
+	bottomLeft, bottomRight := image.Pt(10, 32), image.Pt(50, 32)
+	tests := []struct {
+		name      string
+		marker    image.Point
+		retry     string
+		tilt      bool
+		wantAngle float64
+		wantFound bool
+		wantScans int
+	}{
+		{name: "upright without retry", marker: topLeft, wantFound: true, wantScans: 1},
+		{name: "sideways without retry", marker: bottomRight, wantScans: 1},
+		{name: "upright on empty", marker: topLeft, retry: domain.RotationRetryOnEmpty, wantFound: true, wantScans: 1},
+		{name: "upside down on empty", marker: bottomRight, retry: domain.RotationRetryOnEmpty, wantAngle: 180, wantFound: true, wantScans: 4},
+		{name: "rotated left on empty", marker: bottomLeft, retry: domain.RotationRetryOnEmpty, wantAngle: 90, wantFound: true, wantScans: 4},
+		{name: "rotated right on empty", marker: topRight, retry: domain.RotationRetryOnEmpty, wantAngle: 270, wantFound: true, wantScans: 4},
+		{name: "tilts tried on empty", marker: bottomRight, retry: domain.RotationRetryOnEmpty, tilt: true, wantAngle: 180, wantFound: true, wantScans: 6},
+		{name: "exhaustive keeps upright on ties", marker: topLeft, retry: domain.RotationRetryExhaustive, wantFound: true, wantScans: 4},
+		{name: "exhaustive finds the rotation", marker: bottomRight, retry: domain.RotationRetryExhaustive, wantAngle: 180, wantFound: true, wantScans: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &markerDetector{}
+			params := domain.DefaultDetectionParams()
+			params.RotationRetry, params.RotationTilt = tt.retry, tt.tilt
+
+			got, angle, err := DetectRotated(context.Background(), d.detect, markerImage(tt.marker), params)
+			if err != nil {
+				t.Fatalf("DetectRotated() error = %v", err)
+			}
+			if len(d.scanned) != tt.wantScans {
+				t.Errorf("DetectRotated() scanned %d orientations, want %d", len(d.scanned), tt.wantScans)
+			}
+			if angle != tt.wantAngle {
+				t.Errorf("DetectRotated() rotation = %v, want %v", angle, tt.wantAngle)
+			}
+			if !tt.wantFound {
+				if len(got) != 0 {
+					t.Errorf("DetectRotated() = %+v, want no detections", got)
+				}
+				return
+			}
+			if len(got) != 1 {
+				t.Fatalf("DetectRotated() = %+v, want one detection", got)
+			}
+			// The detection must be mapped back onto the marker.
+			if !tt.marker.In(got[0].Rect) || !got[0].Rect.In(image.Rect(0, 0, 60, 40)) {
+				t.Errorf("DetectRotated() box = %v, want it around %v", got[0].Rect, tt.marker)
+			}
+		})
+	}
+}
+
+func TestDetectRotatedSizes(t *testing.T) {
+	d := &markerDetector{}
+	params := domain.DefaultDetectionParams()
+	params.RotationRetry, params.RotationTilt = domain.RotationRetryExhaustive, true
+	if _, _, err := DetectRotated(context.Background(), d.detect, markerImage(image.Pt(50, 32)), params); err != nil {
+		t.Fatalf("DetectRotated() error = %v", err)
+	}
+	// Upright, 90, 180 and 270 degrees, then the tilts on a larger canvas.
+	want := []image.Point{{60, 40}, {40, 60}, {60, 40}, {40, 60}, {69, 55}, {69, 55}}
+	if !slices.Equal(d.scanned, want) {
+		t.Errorf("DetectRotated() scanned sizes %v, want %v", d.scanned, want)
+	}
+}
+
+func TestDetectRotatedError(t *testing.T) {
+	tests := []struct {
+		name   string
+		retry  string
+		failAt int
+	}{
+		{name: "upright pass", failAt: 1},
+		{name: "upright pass before retries", retry: domain.RotationRetryExhaustive, failAt: 1},
+		{name: "retry pass", retry: domain.RotationRetryExhaustive, failAt: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &markerDetector{failAt: tt.failAt}
+			params := domain.DefaultDetectionParams()
+			params.RotationRetry = tt.retry
+			if _, _, err := DetectRotated(context.Background(), d.detect, markerImage(image.Pt(10, 8)), params); !errors.Is(err, errDetect) {
+				t.Errorf("DetectRotated() error = %v, want %v", err, errDetect)
+			}
+			if len(d.scanned) != tt.failAt {
+				t.Errorf("DetectRotated() scanned %d orientations, want it to stop after %d", len(d.scanned), tt.failAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package detector
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"image"
+)
+
+// detectFacesScript is the OpenCV detection script shared by the embedded
+// and the out-of-process python backends.
+//
+//go:embed detect_faces.py
+var detectFacesScript string
+
+// pythonFace is a face box as returned by the detect_faces script.
+type pythonFace struct {
+	X     int     `json:"x"`
+	Y     int     `json:"y"`
+	W     int     `json:"w"`
+	H     int     `json:"h"`
+	Score float64 `json:"score"`
+	Model string  `json:"model"`
+}
+
+// toDetections converts faces returned by the detect_faces script into
+// detections in the coordinate space of bounds, merging overlapping results
+// of different cascade models.
+func toDetections(faces []pythonFace, bounds image.Rectangle) []Detection {
+	detections := make([]Detection, 0, len(faces))
+	for _, f := range faces {
+		detections = append(detections, Detection{
+			Rect:  image.Rect(f.X, f.Y, f.X+f.W, f.Y+f.H).Add(bounds.Min),
+			Score: f.Score,
+			Model: f.Model,
+		})
+	}
+	return NonMaxSuppression(detections, DefaultNMSThreshold)
+}
+
+// decodePythonFaces converts the JSON output of detect_faces into detections
+// in the coordinate space of bounds.
+func decodePythonFaces(out string, bounds image.Rectangle) ([]Detection, error) {
+	var faces []pythonFace
+	if err := json.Unmarshal([]byte(out), &faces); err != nil {
+		return nil, fmt.Errorf("invalid detect_faces output: %w", err)
+	}
+	return toDetections(faces, bounds), nil
+}
@@ -0,0 +1,61 @@
+package detector
+
+import (
+	"image"
+	"testing"
+
+	"github.com/anggi-susanto/go-face-detection-be/domain"
+)
+
+func TestDecodePythonFaces(t *testing.T) {
+	tests := []struct {
+		name    string
+		out     string
+		bounds  image.Rectangle
+		want    []Detection
+		wantErr bool
+	}{
+		{name: "no faces", out: `[]`, bounds: image.Rect(0, 0, 100, 100), want: []Detection{}},
+		{
+			name:   "offset bounds",
+			out:    `[{"x":10,"y":20,"w":30,"h":40,"score":3,"model":"frontal_default"}]`,
+			bounds: image.Rect(5, 6, 105, 106),
+			want:   []Detection{{Rect: image.Rect(15, 26, 45, 66), Score: 3, Model: domain.ModelFrontalDefault}},
+		},
+		{
+			name: "overlapping models merged",
+			out: `[{"x":10,"y":10,"w":40,"h":40,"score":3,"model":"frontal_default"},
+				{"x":11,"y":11,"w":40,"h":40,"score":5,"model":"frontal_alt"},
+				{"x":60,"y":60,"w":20,"h":20,"score":1,"model":"eyes"}]`,
+			bounds: image.Rect(0, 0, 100, 100),
+			want: []Detection{
+				{Rect: image.Rect(11, 11, 51, 51), Score: 5, Model: domain.ModelFrontalAlt},
+				{Rect: image.Rect(60, 60, 80, 80), Score: 1, Model: domain.ModelEyes},
+			},
+		},
+		{name: "not json", out: `Traceback (most recent call last)`, wantErr: true},
+		{name: "object instead of list", out: `{"x":1}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodePythonFaces(tt.out, tt.bounds)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("decodePythonFaces() = %+v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decodePythonFaces() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("decodePythonFaces() = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("detection %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
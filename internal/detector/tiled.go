@@ -0,0 +1,120 @@
+package detector
+
+import (
+	"context"
+	"image"
+	"runtime"
+
+	"github.com/anggi-susanto/go-face-detection-be/domain"
+	"github.com/anggi-susanto/go-face-detection-be/internal/imaging"
+	"golang.org/x/sync/errgroup"
+)
+
+// tileContainment is the share of a detection's area inside a higher scoring
+// one above which it is treated as a face cut in half by a tile seam.
+const tileContainment = 0.6
+
+// DetectTiled splits the image into overlapping square tiles, runs the
+// detector on every tile in parallel and merges the detections across tile
+// seams. Detections are returned in the coordinate space of img.
+//
+// Parameters:
+// - ctx: The context.Context object for the function.
+// - d: The detector run on every tile.
+// - img: The image to scan.
+// - params: The detection parameters, TileSize and TileOverlap define the tiling.
+//
+// Returns:
+// - []Detection: The merged detections.
+// - error: An error object if any tile failed, otherwise nil.
+func DetectTiled(ctx context.Context, d Detector, img image.Image, params domain.DetectionParams) ([]Detection, error) {
+	tiles := Tiles(img.Bounds(), params.TileSize, params.TileOverlap)
+	results := make([][]Detection, len(tiles))
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(runtime.GOMAXPROCS(0))
+	for i, tile := range tiles {
+		i, tile := i, tile
+		g.Go(func() error {
+			dets, err := d.Detect(ctx, imaging.Crop(img, tile), params)
+			if err != nil {
+				return err
+			}
+			for j := range dets {
+				dets[j].Rect = dets[j].Rect.Add(tile.Min)
+			}
+			results[i] = dets
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	var all []Detection
+	for _, dets := range results {
+		all = append(all, dets...)
+	}
+	return mergeTiles(NonMaxSuppression(all, DefaultNMSThreshold)), nil
+}
+
+// Tiles covers bounds with size x size tiles overlapping by the given
+// fraction of their size. The last row and column are shifted inwards so
+// that every tile lies within bounds.
+func Tiles(bounds image.Rectangle, size int, overlap float64) []image.Rectangle {
+	if size <= 0 {
+		size = domain.DefaultTileSize
+	}
+	step := int(float64(size) * (1 - overlap))
+	if step < 1 {
+		step = 1
+	}
+
+	var tiles []image.Rectangle
+	for _, y := range tileStarts(bounds.Min.Y, bounds.Max.Y, size, step) {
+		for _, x := range tileStarts(bounds.Min.X, bounds.Max.X, size, step) {
+			tiles = append(tiles, image.Rect(x, y, x+size, y+size).Intersect(bounds))
+		}
+	}
+	return tiles
+}
+
+func tileStarts(lo, hi, size, step int) []int {
+	if hi-lo <= size {
+		return []int{lo}
+	}
+	var starts []int
+	for s := lo; ; s += step {
+		if s+size >= hi {
+			starts = append(starts, hi-size)
+			break
+		}
+		starts = append(starts, s)
+	}
+	return starts
+}
+
+// mergeTiles drops detections that mostly lie inside a higher scoring one of
+// the same model, which happens when a tile seam cuts through a face. dets
+// must be sorted by descending score, as returned by NonMaxSuppression.
+func mergeTiles(dets []Detection) []Detection {
+	kept := make([]Detection, 0, len(dets))
+	for _, d := range dets {
+		area := float64(d.Rect.Dx() * d.Rect.Dy())
+		contained := false
+		for _, k := range kept {
+			if k.Model != d.Model {
+				continue
+			}
+			inter := d.Rect.Intersect(k.Rect)
+			if area > 0 && float64(inter.Dx()*inter.Dy())/area > tileContainment {
+				contained = true
+				break
+			}
+		}
+		if !contained {
+			kept = append(kept, d)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,116 @@
+package detector
+
+import (
+	"image"
+	"reflect"
+	"testing"
+
+	"github.com/anggi-susanto/go-face-detection-be/domain"
+)
+
+func TestTileStarts(t *testing.T) {
+	tests := []struct {
+		name               string
+		lo, hi, size, step int
+		want               []int
+	}{
+		{name: "smaller than a tile", lo: 0, hi: 30, size: 40, step: 30, want: []int{0}},
+		{name: "exactly one tile", lo: 0, hi: 40, size: 40, step: 30, want: []int{0}},
+		{name: "last tile ends at the edge", lo: 0, hi: 100, size: 40, step: 30, want: []int{0, 30, 60}},
+		{name: "last tile shifted inwards", lo: 0, hi: 95, size: 40, step: 30, want: []int{0, 30, 55}},
+		{name: "offset bounds", lo: 10, hi: 110, size: 40, step: 30, want: []int{10, 40, 70}},
+		{name: "single pixel step", lo: 0, hi: 3, size: 2, step: 1, want: []int{0, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tileStarts(tt.lo, tt.hi, tt.size, tt.step); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tileStarts(%d, %d, %d, %d) = %v, want %v", tt.lo, tt.hi, tt.size, tt.step, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		bounds  image.Rectangle
+		size    int
+		overlap float64
+		want    []image.Rectangle
+	}{
+		{
+			name:   "smaller than a tile",
+			bounds: image.Rect(0, 0, 30, 20), size: 40, overlap: 0.25,
+			want: []image.Rectangle{image.Rect(0, 0, 30, 20)},
+		},
+		{
+			name:   "single row",
+			bounds: image.Rect(0, 0, 100, 40), size: 40, overlap: 0.25,
+			want: []image.Rectangle{image.Rect(0, 0, 40, 40), image.Rect(30, 0, 70, 40), image.Rect(60, 0, 100, 40)},
+		},
+		{
+			name:   "grid",
+			bounds: image.Rect(0, 0, 70, 70), size: 40, overlap: 0.25,
+			want: []image.Rectangle{
+				image.Rect(0, 0, 40, 40), image.Rect(30, 0, 70, 40),
+				image.Rect(0, 30, 40, 70), image.Rect(30, 30, 70, 70),
+			},
+		},
+		{
+			name:   "no overlap",
+			bounds: image.Rect(0, 0, 80, 40), size: 40, overlap: 0,
+			want: []image.Rectangle{image.Rect(0, 0, 40, 40), image.Rect(40, 0, 80, 40)},
+		},
+		{
+			name:   "default size",
+			bounds: image.Rect(0, 0, 2000, 500), size: 0, overlap: domain.DefaultTileOverlap,
+			want: []image.Rectangle{image.Rect(0, 0, 1024, 500), image.Rect(768, 0, 1792, 500), image.Rect(976, 0, 2000, 500)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Tiles(tt.bounds, tt.size, tt.overlap)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Tiles(%v, %d, %v) = %v, want %v", tt.bounds, tt.size, tt.overlap, got, tt.want)
+			}
+			var covered image.Rectangle
+			for _, tile := range got {
+				if !tile.In(tt.bounds) {
+					t.Errorf("tile %v outside %v", tile, tt.bounds)
+				}
+				covered = covered.Union(tile)
+			}
+			if covered != tt.bounds {
+				t.Errorf("tiles cover %v, want %v", covered, tt.bounds)
+			}
+		})
+	}
+}
+
+func TestMergeTiles(t *testing.T) {
+	face := Detection{Rect: image.Rect(0, 0, 20, 20), Score: 0.9, Model: domain.ModelFrontalDefault}
+	half := Detection{Rect: image.Rect(10, 0, 20, 20), Score: 0.8, Model: domain.ModelFrontalDefault}
+	otherModel := Detection{Rect: image.Rect(10, 0, 20, 20), Score: 0.8, Model: domain.ModelProfile}
+	neighbor := Detection{Rect: image.Rect(15, 0, 35, 20), Score: 0.7, Model: domain.ModelFrontalDefault}
+	empty := Detection{Rect: image.Rect(5, 5, 5, 5), Score: 0.6, Model: domain.ModelFrontalDefault}
+
+	tests := []struct {
+		name string
+		dets []Detection
+		want []Detection
+	}{
+		{name: "no detections", dets: nil, want: []Detection{}},
+		{name: "cut face dropped", dets: []Detection{face, half}, want: []Detection{face}},
+		{name: "other model kept", dets: []Detection{face, otherModel}, want: []Detection{face, otherModel}},
+		{name: "small overlap kept", dets: []Detection{face, neighbor}, want: []Detection{face, neighbor}},
+		{name: "empty rectangle kept", dets: []Detection{face, empty}, want: []Detection{face, empty}},
+		{name: "containing detection kept", dets: []Detection{half, face}, want: []Detection{half, face}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergeTiles(tt.dets); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mergeTiles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
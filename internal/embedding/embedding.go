@@ -0,0 +1,121 @@
+package embedding
+
+import (
+	"image"
+	"math"
+	"math/bits"
+
+	"github.com/anggi-susanto/go-face-detection-be/internal/imaging"
+)
+
+const (
+	// faceSize is the side length every face is resampled to.
+	faceSize = 64
+	// gridSize is the number of cells per side the face is split into.
+	gridSize = 4
+	// lbpBins holds the 58 uniform patterns plus one bin for the rest.
+	lbpBins = 59
+
+	// Dimension is the length of every embedding.
+	Dimension = gridSize * gridSize * lbpBins
+)
+
+// uniformBins maps every 8 bit local binary pattern to its histogram bin.
+var uniformBins = func() [256]uint8 {
+	var table [256]uint8
+	next := uint8(0)
+	for p := 0; p < 256; p++ {
+		// Circular bit transitions, at most two make a uniform pattern.
+		rotated := uint8(p)>>1 | uint8(p)<<7
+		if bits.OnesCount8(uint8(p)^rotated) <= 2 {
+			table[p] = next
+			next++
+		} else {
+			table[p] = lbpBins - 1
+		}
+	}
+	return table
+}()
+
+// Embedder turns a face image into a fixed-length vector compared by cosine
+// similarity. How well the similarity follows identity depends on the
+// implementation: the LBP embedder only measures how alike two faces look.
+type Embedder interface {
+	Embed(face image.Image) []float32
+}
+
+// lbpEmbedder describes a face by the uniform local binary pattern
+// histograms of a grid of cells over the equalized face, a classic texture
+// descriptor that needs no trained model. It is not a face recognition
+// model: lighting, pose and image quality move its similarity as much as
+// identity does, and since histograms are non-negative even unrelated faces
+// score well above zero. Its scores rank look-alike faces, they do not tell
+// whether two faces show the same person.
+type lbpEmbedder struct{}
+
+// NewLBPEmbedder creates the texture descriptor embedder, the only embedder
+// available until a trained face recognition model is wired in.
+//
+// Returns:
+// - Embedder: The embedder, producing vectors of Dimension values.
+func NewLBPEmbedder() Embedder {
+	return lbpEmbedder{}
+}
+
+// Embed returns the L2 normalized descriptor of the face image.
+func (lbpEmbedder) Embed(face image.Image) []float32 {
+	gray := imaging.EqualizeHistogram(imaging.Grayscale(imaging.Resize(face, faceSize, faceSize)))
+
+	hist := make([]float64, Dimension)
+	cellSize := (faceSize - 2) / gridSize
+	for y := 1; y < faceSize-1; y++ {
+		cy := min((y-1)/cellSize, gridSize-1)
+		for x := 1; x < faceSize-1; x++ {
+			cx := min((x-1)/cellSize, gridSize-1)
+			center := gray.Pix[gray.PixOffset(x, y)]
+			var pattern uint8
+			for i, n := range [8][2]int{{-1, -1}, {0, -1}, {1, -1}, {1, 0}, {1, 1}, {0, 1}, {-1, 1}, {-1, 0}} {
+				if gray.Pix[gray.PixOffset(x+n[0], y+n[1])] >= center {
+					pattern |= 1 << i
+				}
+			}
+			hist[(cy*gridSize+cx)*lbpBins+int(uniformBins[pattern])]++
+		}
+	}
+
+	// The square root turns the dot product of normalized histograms into
+	// the Hellinger kernel, which compares histograms far better.
+	var norm float64
+	for i, v := range hist {
+		hist[i] = math.Sqrt(v)
+		norm += hist[i] * hist[i]
+	}
+	norm = math.Sqrt(norm)
+
+	vec := make([]float32, Dimension)
+	if norm == 0 {
+		return vec
+	}
+	for i, v := range hist {
+		vec[i] = float32(v / norm)
+	}
+	return vec
+}
+
+// Cosine returns the cosine similarity of two vectors, 0 when their lengths
+// differ or either is zero.
+func Cosine(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(na*nb)
+}
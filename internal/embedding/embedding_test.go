@@ -0,0 +1,173 @@
+package embedding
+
+import (
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/anggi-susanto/go-face-detection-be/domain"
+	"github.com/anggi-susanto/go-face-detection-be/internal/detector"
+)
+
+func TestCosine(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []float32
+		want float64
+	}{
+		{name: "identical", a: []float32{1, 2, 3}, b: []float32{1, 2, 3}, want: 1},
+		{name: "scaled", a: []float32{1, 2, 3}, b: []float32{2, 4, 6}, want: 1},
+		{name: "orthogonal", a: []float32{1, 0}, b: []float32{0, 1}, want: 0},
+		{name: "opposite", a: []float32{1, 1}, b: []float32{-1, -1}, want: -1},
+		{name: "45 degrees", a: []float32{1, 0}, b: []float32{1, 1}, want: math.Sqrt2 / 2},
+		{name: "different lengths", a: []float32{1, 0}, b: []float32{1, 0, 0}, want: 0},
+		{name: "zero vector", a: []float32{0, 0}, b: []float32{1, 0}, want: 0},
+		{name: "empty", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Cosine(tt.a, tt.b); math.Abs(got-tt.want) > 1e-6 {
+				t.Errorf("Cosine(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+// testFace returns a w x h image drawn by pixel.
+func testFace(w, h int, pixel func(x, y int) uint8) image.Image {
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetGray(x, y, color.Gray{Y: pixel(x, y)})
+		}
+	}
+	return img
+}
+
+func TestLBPEmbedder(t *testing.T) {
+	stripes := func(x, y int) uint8 { return uint8(x / 4 % 2 * 255) }
+	rings := func(x, y int) uint8 {
+		dx, dy := float64(x-32), float64(y-32)
+		return uint8(int(math.Sqrt(dx*dx+dy*dy))/5%2) * 255
+	}
+	tests := []struct {
+		name string
+		a, b image.Image
+		// minScore and maxScore bound the similarity of the two faces.
+		minScore, maxScore float64
+	}{
+		{name: "same face", a: testFace(64, 64, rings), b: testFace(64, 64, rings), minScore: 0.9999, maxScore: 1.0001},
+		{name: "same face resized", a: testFace(64, 64, rings), b: testFace(128, 128, func(x, y int) uint8 { return rings(x/2, y/2) }), minScore: 0.99, maxScore: 1.0001},
+		// Histograms are non-negative, so unrelated textures still score
+		// well above zero, only below the same face.
+		{name: "different textures", a: testFace(64, 64, rings), b: testFace(64, 64, stripes), minScore: 0.5, maxScore: 0.9},
+	}
+	e := NewLBPEmbedder()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			va, vb := e.Embed(tt.a), e.Embed(tt.b)
+			for _, v := range [][]float32{va, vb} {
+				if len(v) != Dimension {
+					t.Fatalf("Embed() returned %d values, want %d", len(v), Dimension)
+				}
+				var norm float64
+				for _, x := range v {
+					norm += float64(x) * float64(x)
+				}
+				if math.Abs(norm-1) > 1e-4 {
+					t.Errorf("Embed() norm = %v, want 1", math.Sqrt(norm))
+				}
+			}
+			if got := Cosine(va, vb); got < tt.minScore || got > tt.maxScore {
+				t.Errorf("Cosine() = %v, want between %v and %v", got, tt.minScore, tt.maxScore)
+			}
+		})
+	}
+}
+
+// probeDetector returns fixed detections and records the scanned size.
+type probeDetector struct {
+	detections []detector.Detection
+	err        error
+	scanned    image.Rectangle
+}
+
+func (d *probeDetector) Detect(ctx context.Context, img image.Image, params domain.DetectionParams) ([]detector.Detection, error) {
+	d.scanned = img.Bounds()
+	return d.detections, d.err
+}
+
+func (d *probeDetector) Name() string { return "probe" }
+
+func (d *probeDetector) Supports(model string) bool { return true }
+
+func (d *probeDetector) Close() error { return nil }
+
+// cropEmbedder records the size of the embedded face.
+type cropEmbedder struct {
+	embedded image.Point
+}
+
+func (e *cropEmbedder) Embed(face image.Image) []float32 {
+	e.embedded = face.Bounds().Size()
+	return []float32{1}
+}
+
+func TestEmbedLargestFace(t *testing.T) {
+	errDetect := errors.New("detector failed")
+	tests := []struct {
+		name       string
+		size       image.Point
+		detections []detector.Detection
+		err        error
+		wantErr    error
+		wantScan   image.Point
+		wantCrop   image.Point
+	}{
+		{name: "no face", size: image.Pt(200, 100), wantErr: ErrNoFace, wantScan: image.Pt(200, 100)},
+		{name: "detector error", size: image.Pt(200, 100), err: errDetect, wantErr: errDetect, wantScan: image.Pt(200, 100)},
+		{
+			name: "largest face",
+			size: image.Pt(200, 100),
+			detections: []detector.Detection{
+				{Rect: image.Rect(0, 0, 20, 20)},
+				{Rect: image.Rect(100, 10, 160, 70)},
+				{Rect: image.Rect(50, 50, 90, 90)},
+			},
+			wantScan: image.Pt(200, 100),
+			wantCrop: image.Pt(60, 60),
+		},
+		{
+			name:       "downscaled probe",
+			size:       image.Pt(2048, 1024),
+			detections: []detector.Detection{{Rect: image.Rect(100, 50, 200, 150)}},
+			wantScan:   image.Pt(1024, 512),
+			wantCrop:   image.Pt(200, 200),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &probeDetector{detections: tt.detections, err: tt.err}
+			e := &cropEmbedder{}
+			_, err := EmbedLargestFace(context.Background(), d, e, image.NewGray(image.Rectangle{Max: tt.size}))
+			if d.scanned.Size() != tt.wantScan {
+				t.Errorf("EmbedLargestFace() scanned %v, want %v", d.scanned.Size(), tt.wantScan)
+			}
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("EmbedLargestFace() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("EmbedLargestFace() error = %v", err)
+			}
+			if e.embedded != tt.wantCrop {
+				t.Errorf("EmbedLargestFace() embedded a %v crop, want %v", e.embedded, tt.wantCrop)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package embedding
+
+import (
+	"context"
+	"errors"
+	"image"
+
+	"github.com/anggi-susanto/go-face-detection-be/domain"
+	"github.com/anggi-susanto/go-face-detection-be/internal/detector"
+	"github.com/anggi-susanto/go-face-detection-be/internal/imaging"
+)
+
+// probeMaxDimension bounds the size of probe images before detection.
+const probeMaxDimension = 1024
+
+// ErrNoFace is returned when no face is found in a probe image.
+var ErrNoFace = errors.New("no face found in the image")
+
+// EmbedLargestFace detects the faces of an upright image, such as a search
+// probe or an enrollment photo, and embeds the largest one.
+//
+// Parameters:
+// - ctx: The context.Context object for the function.
+// - d: The detector used to find the faces.
+// - e: The embedder used to describe the face.
+// - img: The upright image.
+//
+// Returns:
+// - []float32: The embedding of the largest face.
+// - error: ErrNoFace if the image has no face, the detector error if detection failed, otherwise nil.
+func EmbedLargestFace(ctx context.Context, d detector.Detector, e Embedder, img image.Image) ([]float32, error) {
+	scaled, scale := imaging.Downscale(img, probeMaxDimension)
+	detections, err := d.Detect(ctx, scaled, domain.DefaultDetectionParams())
+	if err != nil {
+		return nil, err
+	}
+	if len(detections) == 0 {
+		return nil, ErrNoFace
+	}
+
+	largest := detections[0].Rect
+	for _, det := range detections[1:] {
+		if det.Rect.Dx()*det.Rect.Dy() > largest.Dx()*largest.Dy() {
+			largest = det.Rect
+		}
+	}
+	rect := image.Rect(
+		int(float64(largest.Min.X)*scale), int(float64(largest.Min.Y)*scale),
+		int(float64(largest.Max.X)*scale), int(float64(largest.Max.Y)*scale),
+	).Add(img.Bounds().Min)
+	return e.Embed(imaging.Crop(img, rect)), nil
+}
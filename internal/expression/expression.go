@@ -0,0 +1,118 @@
+package expression
+
+import (
+	"context"
+	"image"
+	"math"
+
+	"github.com/anggi-susanto/go-face-detection-be/domain"
+	"github.com/anggi-susanto/go-face-detection-be/internal/detector"
+	"github.com/anggi-susanto/go-face-detection-be/internal/imaging"
+)
+
+const (
+	// eyeNeighbors and smileNeighbors are the cascade min_neighbors. The
+	// smile cascade fires on many mouth shapes and needs far more agreement.
+	eyeNeighbors   = 5
+	smileNeighbors = 20
+
+	// missingScore is the strength of an eye or a smile the cascade did not
+	// find, so that a single missed detection is not a confident "no".
+	missingScore = 0.25
+)
+
+// window is a search region in fractions of the face size.
+type window struct{ x0, y0, x1, y1 float64 }
+
+var (
+	eyesWindow  = window{0, 0.15, 1, 0.6}
+	smileWindow = window{0.1, 0.55, 0.9, 1}
+)
+
+func (w window) rect(bounds image.Rectangle) image.Rectangle {
+	fw, fh := float64(bounds.Dx()), float64(bounds.Dy())
+	return image.Rect(int(w.x0*fw), int(w.y0*fh), int(w.x1*fw), int(w.y1*fh)).Add(bounds.Min)
+}
+
+// Cues are the expression estimates of a single face. Confidences are in the
+// [0.5, 1] range and apply to the reported value.
+type Cues struct {
+	EyesOpen           bool
+	EyesOpenConfidence float64
+	Smiling            bool
+	SmilingConfidence  float64
+}
+
+// Supported reports whether the detector can run the eye and smile cascades
+// Analyze needs.
+func Supported(d detector.Detector) bool {
+	return d.Supports(domain.ModelEyes) && d.Supports(domain.ModelSmile)
+}
+
+// Analyze runs the eye cascade over the upper part and the smile cascade
+// over the lower part of an upright face image.
+//
+// Parameters:
+// - ctx: The context.Context object for the detector calls.
+// - d: The detector running the cascades.
+// - face: The upright face image.
+//
+// Returns:
+// - Cues: The eyes open and smile estimates.
+// - error: An error object if a cascade failed, wrapping detector.ErrUnsupportedModel when the backend lacks one, otherwise nil.
+func Analyze(ctx context.Context, d detector.Detector, face image.Image) (Cues, error) {
+	var cues Cues
+	size := face.Bounds().Dx()
+
+	eyes, err := d.Detect(ctx, imaging.Crop(face, eyesWindow.rect(face.Bounds())), cascadeParams(domain.ModelEyes, eyeNeighbors, size/8, size/2))
+	if err != nil {
+		return cues, err
+	}
+	// The strongest eye on each half of the face, so that duplicate
+	// detections of one eye do not count as two open eyes.
+	left, right := missingScore, missingScore
+	for _, e := range eyes {
+		s := strength(e.Score)
+		if (e.Rect.Min.X+e.Rect.Max.X)/2 < size/2 {
+			left = math.Max(left, s)
+		} else {
+			right = math.Max(right, s)
+		}
+	}
+	cues.EyesOpen, cues.EyesOpenConfidence = decide((left + right) / 2)
+
+	smiles, err := d.Detect(ctx, imaging.Crop(face, smileWindow.rect(face.Bounds())), cascadeParams(domain.ModelSmile, smileNeighbors, size/5, 0))
+	if err != nil {
+		return cues, err
+	}
+	smile := missingScore
+	for _, s := range smiles {
+		smile = math.Max(smile, strength(s.Score))
+	}
+	cues.Smiling, cues.SmilingConfidence = decide(smile)
+	return cues, nil
+}
+
+// cascadeParams returns the detection parameters of a single cue cascade.
+func cascadeParams(model string, minNeighbors, minSize, maxSize int) domain.DetectionParams {
+	params := domain.DefaultDetectionParams()
+	params.Models = []string{model}
+	params.MinNeighbors = minNeighbors
+	params.MinSize = minSize
+	params.MaxSize = maxSize
+	return params
+}
+
+// strength maps a cascade score to the (0, 1) range.
+func strength(score float64) float64 {
+	return 1 / (1 + math.Exp(-score))
+}
+
+// decide turns the strength of a cue into its value and the confidence of
+// that value.
+func decide(p float64) (bool, float64) {
+	if p >= 0.5 {
+		return true, p
+	}
+	return false, 1 - p
+}
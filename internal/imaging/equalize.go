@@ -0,0 +1,110 @@
+package imaging
+
+import "image"
+
+// EqualizeHistogram spreads the luma histogram of a grayscale image over the
+// full 0-255 range.
+func EqualizeHistogram(gray *image.Gray) *image.Gray {
+	bounds := gray.Bounds()
+	var hist [256]int
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for _, v := range gray.Pix[gray.PixOffset(bounds.Min.X, y):gray.PixOffset(bounds.Max.X, y)] {
+			hist[v]++
+		}
+	}
+	lut := equalizationLUT(hist, bounds.Dx()*bounds.Dy())
+
+	dst := image.NewGray(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			dst.Pix[dst.PixOffset(x, y)] = lut[gray.Pix[gray.PixOffset(x, y)]]
+		}
+	}
+	return dst
+}
+
+// CLAHE applies contrast limited adaptive histogram equalization: the image
+// is split into tiles x tiles regions, each equalized with its histogram
+// clipped at clipLimit times the mean bin count, and the per tile mappings
+// are bilinearly interpolated to avoid seams.
+func CLAHE(gray *image.Gray, tiles int, clipLimit float64) *image.Gray {
+	bounds := gray.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if tiles < 1 {
+		tiles = 1
+	}
+	tilesX, tilesY := min(tiles, w), min(tiles, h)
+	if tilesX < 1 || tilesY < 1 {
+		return gray
+	}
+	tileW, tileH := (w+tilesX-1)/tilesX, (h+tilesY-1)/tilesY
+	// Rounding the tile size up may leave trailing tiles empty, drop them.
+	tilesX, tilesY = (w+tileW-1)/tileW, (h+tileH-1)/tileH
+
+	luts := make([][256]uint8, tilesX*tilesY)
+	for ty := 0; ty < tilesY; ty++ {
+		for tx := 0; tx < tilesX; tx++ {
+			tile := image.Rect(tx*tileW, ty*tileH, (tx+1)*tileW, (ty+1)*tileH).Intersect(image.Rect(0, 0, w, h))
+			var hist [256]int
+			for y := tile.Min.Y; y < tile.Max.Y; y++ {
+				for x := tile.Min.X; x < tile.Max.X; x++ {
+					hist[gray.Pix[gray.PixOffset(bounds.Min.X+x, bounds.Min.Y+y)]]++
+				}
+			}
+			n := tile.Dx() * tile.Dy()
+			clipHistogram(&hist, int(clipLimit*float64(n)/256)+1)
+			luts[ty*tilesX+tx] = equalizationLUT(hist, n)
+		}
+	}
+
+	dst := image.NewGray(bounds)
+	for y := 0; y < h; y++ {
+		// Position relative to the tile centers.
+		fy := (float64(y)+0.5)/float64(tileH) - 0.5
+		y0, wy := splitCoord(fy, tilesY)
+		y1 := min(y0+1, tilesY-1)
+		for x := 0; x < w; x++ {
+			fx := (float64(x)+0.5)/float64(tileW) - 0.5
+			x0, wx := splitCoord(fx, tilesX)
+			x1 := min(x0+1, tilesX-1)
+
+			v := gray.Pix[gray.PixOffset(bounds.Min.X+x, bounds.Min.Y+y)]
+			top := float64(luts[y0*tilesX+x0][v])*(1-wx) + float64(luts[y0*tilesX+x1][v])*wx
+			bottom := float64(luts[y1*tilesX+x0][v])*(1-wx) + float64(luts[y1*tilesX+x1][v])*wx
+			dst.Pix[dst.PixOffset(bounds.Min.X+x, bounds.Min.Y+y)] = uint8(top*(1-wy) + bottom*wy + 0.5)
+		}
+	}
+	return dst
+}
+
+// clipHistogram caps every bin at limit and spreads the excess evenly.
+func clipHistogram(hist *[256]int, limit int) {
+	excess := 0
+	for i, v := range hist {
+		if v > limit {
+			excess += v - limit
+			hist[i] = limit
+		}
+	}
+	for i := range hist {
+		hist[i] += excess / 256
+	}
+	for i := 0; i < excess%256; i++ {
+		hist[i]++
+	}
+}
+
+// equalizationLUT builds the cumulative distribution mapping of a histogram
+// over n pixels.
+func equalizationLUT(hist [256]int, n int) [256]uint8 {
+	var lut [256]uint8
+	if n == 0 {
+		return lut
+	}
+	cdf := 0
+	for i, v := range hist {
+		cdf += v
+		lut[i] = uint8((cdf*255 + n/2) / n)
+	}
+	return lut
+}
@@ -0,0 +1,214 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+
+	// register decoders for the formats accepted by upload
+	_ "image/gif"
+)
+
+// Load decodes the image stored at the given path.
+//
+// Parameters:
+// - path: The path of the image file.
+//
+// Returns:
+// - image.Image: The decoded image.
+// - error: An error object if the file could not be opened or decoded, otherwise nil.
+func Load(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, err
+	}
+	return img, nil
+}
+
+// Save encodes the image to the given path, using PNG for ".png" files and
+// JPEG for everything else.
+//
+// Parameters:
+// - path: The destination path.
+// - img: The image to encode.
+//
+// Returns:
+// - error: An error object if the file could not be created or encoded, otherwise nil.
+func Save(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".png") {
+		err = png.Encode(f, img)
+	} else {
+		err = jpeg.Encode(f, img, &jpeg.Options{Quality: 90})
+	}
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Grayscale converts the image to 8-bit luma. The returned image always has
+// its origin at (0, 0).
+func Grayscale(img image.Image) *image.Gray {
+	bounds := img.Bounds()
+	gray := image.NewGray(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(gray, gray.Bounds(), img, bounds.Min, draw.Src)
+	return gray
+}
+
+// ToRGBA returns a mutable RGBA copy of the image.
+func ToRGBA(img image.Image) *image.RGBA {
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(bounds)
+	draw.Draw(rgba, bounds, img, bounds.Min, draw.Src)
+	return rgba
+}
+
+// DrawRect draws the outline of r onto img with the given color and line
+// thickness in pixels.
+func DrawRect(img draw.Image, r image.Rectangle, c color.Color, thickness int) {
+	src := image.NewUniform(c)
+	for i := 0; i < thickness; i++ {
+		inner := r.Inset(i)
+		if inner.Empty() {
+			return
+		}
+		draw.Draw(img, image.Rect(inner.Min.X, inner.Min.Y, inner.Max.X, inner.Min.Y+1), src, image.Point{}, draw.Src)
+		draw.Draw(img, image.Rect(inner.Min.X, inner.Max.Y-1, inner.Max.X, inner.Max.Y), src, image.Point{}, draw.Src)
+		draw.Draw(img, image.Rect(inner.Min.X, inner.Min.Y, inner.Min.X+1, inner.Max.Y), src, image.Point{}, draw.Src)
+		draw.Draw(img, image.Rect(inner.Max.X-1, inner.Min.Y, inner.Max.X, inner.Max.Y), src, image.Point{}, draw.Src)
+	}
+}
+
+// Resize scales the image to w x h pixels using bilinear interpolation.
+func Resize(img image.Image, w, h int) *image.RGBA {
+	src := ToRGBA(img)
+	bounds := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	if bounds.Empty() || w <= 0 || h <= 0 {
+		return dst
+	}
+
+	sx := float64(bounds.Dx()) / float64(w)
+	sy := float64(bounds.Dy()) / float64(h)
+	for y := 0; y < h; y++ {
+		fy := (float64(y)+0.5)*sy - 0.5
+		y0, wy := splitCoord(fy, bounds.Dy())
+		for x := 0; x < w; x++ {
+			fx := (float64(x)+0.5)*sx - 0.5
+			x0, wx := splitCoord(fx, bounds.Dx())
+			x1, y1 := min(x0+1, bounds.Dx()-1), min(y0+1, bounds.Dy()-1)
+
+			p00 := src.Pix[src.PixOffset(bounds.Min.X+x0, bounds.Min.Y+y0):]
+			p10 := src.Pix[src.PixOffset(bounds.Min.X+x1, bounds.Min.Y+y0):]
+			p01 := src.Pix[src.PixOffset(bounds.Min.X+x0, bounds.Min.Y+y1):]
+			p11 := src.Pix[src.PixOffset(bounds.Min.X+x1, bounds.Min.Y+y1):]
+			out := dst.Pix[dst.PixOffset(x, y):]
+			for c := 0; c < 4; c++ {
+				top := float64(p00[c])*(1-wx) + float64(p10[c])*wx
+				bottom := float64(p01[c])*(1-wx) + float64(p11[c])*wx
+				out[c] = uint8(top*(1-wy) + bottom*wy + 0.5)
+			}
+		}
+	}
+	return dst
+}
+
+// Thumbnail scales the image down so that its longest side is at most size
+// pixels, keeping the aspect ratio. Smaller images are returned unscaled.
+func Thumbnail(img image.Image, size int) image.Image {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if size <= 0 || (w <= size && h <= size) {
+		return img
+	}
+	if w >= h {
+		return Resize(img, size, max(1, h*size/w))
+	}
+	return Resize(img, max(1, w*size/h), size)
+}
+
+// Downscale shrinks the image so that its longest side is at most maxDim
+// pixels. Large reductions first average whole pixel blocks to avoid
+// aliasing. It returns the scaled image and the factor from the returned to
+// the original pixel space, 1 when the image was not scaled.
+func Downscale(img image.Image, maxDim int) (image.Image, float64) {
+	bounds := img.Bounds()
+	longest := max(bounds.Dx(), bounds.Dy())
+	if maxDim <= 0 || longest <= maxDim {
+		return img, 1
+	}
+
+	scale := float64(longest) / float64(maxDim)
+	w := max(1, int(float64(bounds.Dx())/scale))
+	h := max(1, int(float64(bounds.Dy())/scale))
+	if block := int(scale); block >= 2 {
+		img = shrink(img, block)
+	}
+	return Resize(img, w, h), scale
+}
+
+// shrink averages every block x block pixel square into one pixel.
+func shrink(img image.Image, block int) *image.RGBA {
+	src := ToRGBA(img)
+	bounds := src.Bounds()
+	w, h := bounds.Dx()/block, bounds.Dy()/block
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	n := block * block
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var sum [4]int
+			for by := 0; by < block; by++ {
+				row := src.Pix[src.PixOffset(bounds.Min.X+x*block, bounds.Min.Y+y*block+by):]
+				for bx := 0; bx < block*4; bx += 4 {
+					sum[0] += int(row[bx])
+					sum[1] += int(row[bx+1])
+					sum[2] += int(row[bx+2])
+					sum[3] += int(row[bx+3])
+				}
+			}
+			out := dst.Pix[dst.PixOffset(x, y):]
+			for c := 0; c < 4; c++ {
+				out[c] = uint8(sum[c] / n)
+			}
+		}
+	}
+	return dst
+}
+
+// Crop returns the r region of img, clipped to the image bounds.
+func Crop(img image.Image, r image.Rectangle) image.Image {
+	r = r.Intersect(img.Bounds())
+	dst := image.NewRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, r.Min, draw.Src)
+	return dst
+}
+
+// splitCoord splits a sampling coordinate into its clamped integer pixel and
+// the fractional weight of the next pixel.
+func splitCoord(f float64, n int) (int, float64) {
+	if f <= 0 {
+		return 0, 0
+	}
+	i := int(f)
+	if i >= n-1 {
+		return n - 1, 0
+	}
+	return i, f - float64(i)
+}
@@ -0,0 +1,318 @@
+package imaging
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"image"
+	"io"
+	"math"
+	"os"
+)
+
+// EXIF orientation values, see the TIFF 6.0 Orientation tag.
+const (
+	OrientationNormal     = 1
+	OrientationFlipH      = 2
+	OrientationRotate180  = 3
+	OrientationFlipV      = 4
+	OrientationTranspose  = 5
+	OrientationRotate90   = 6
+	OrientationTransverse = 7
+	OrientationRotate270  = 8
+)
+
+const exifOrientationTag = 0x0112
+
+// ReadOrientation returns the EXIF orientation of the JPEG stored at path, or
+// OrientationNormal when the file has none or is not a JPEG.
+func ReadOrientation(path string) int {
+	f, err := os.Open(path)
+	if err != nil {
+		return OrientationNormal
+	}
+	defer f.Close()
+	return DecodeOrientation(f)
+}
+
+// DecodeOrientation returns the EXIF orientation of the JPEG read from r, or
+// OrientationNormal when it has none or is not a JPEG.
+func DecodeOrientation(r io.Reader) int {
+	orientation, err := jpegOrientation(bufio.NewReader(r))
+	if err != nil || orientation < OrientationNormal || orientation > OrientationRotate270 {
+		return OrientationNormal
+	}
+	return orientation
+}
+
+// jpegOrientation walks the JPEG markers up to the first APP1 Exif segment
+// and reads the orientation tag of its first IFD.
+func jpegOrientation(r *bufio.Reader) (int, error) {
+	var soi [2]byte
+	if _, err := io.ReadFull(r, soi[:]); err != nil || soi != [2]byte{0xFF, 0xD8} {
+		return 0, io.ErrUnexpectedEOF
+	}
+	for {
+		var marker [4]byte
+		if _, err := io.ReadFull(r, marker[:]); err != nil {
+			return 0, err
+		}
+		if marker[0] != 0xFF {
+			return 0, io.ErrUnexpectedEOF
+		}
+		// Start of scan, no metadata follows.
+		if marker[1] == 0xDA {
+			return OrientationNormal, nil
+		}
+		size := int(binary.BigEndian.Uint16(marker[2:])) - 2
+		if size < 0 {
+			return 0, io.ErrUnexpectedEOF
+		}
+		segment := make([]byte, size)
+		if _, err := io.ReadFull(r, segment); err != nil {
+			return 0, err
+		}
+		if marker[1] == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return tiffOrientation(segment[6:])
+		}
+	}
+}
+
+func tiffOrientation(tiff []byte) (int, error) {
+	if len(tiff) < 8 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0, io.ErrUnexpectedEOF
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) {
+		return 0, io.ErrUnexpectedEOF
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < entries; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 0, io.ErrUnexpectedEOF
+		}
+		if order.Uint16(tiff[entry:]) == exifOrientationTag {
+			return int(order.Uint16(tiff[entry+8:])), nil
+		}
+	}
+	return OrientationNormal, nil
+}
+
+// Orient transforms an image stored with the given EXIF orientation into its
+// upright form.
+func Orient(img image.Image, orientation int) image.Image {
+	if orientation <= OrientationNormal || orientation > OrientationRotate270 {
+		return img
+	}
+	src := ToRGBA(img)
+	bounds := src.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	dstW, dstH := w, h
+	if orientation >= OrientationTranspose {
+		dstW, dstH = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			dx, dy := orientPoint(x, y, orientation, w, h)
+			copy(dst.Pix[dst.PixOffset(dx, dy):dst.PixOffset(dx, dy)+4], src.Pix[src.PixOffset(bounds.Min.X+x, bounds.Min.Y+y):])
+		}
+	}
+	return dst
+}
+
+// orientPoint maps the pixel (x, y) of a w x h image stored with the given
+// orientation to its position in the upright image.
+func orientPoint(x, y, orientation, w, h int) (int, int) {
+	switch orientation {
+	case OrientationFlipH:
+		return w - 1 - x, y
+	case OrientationRotate180:
+		return w - 1 - x, h - 1 - y
+	case OrientationFlipV:
+		return x, h - 1 - y
+	case OrientationTranspose:
+		return y, x
+	case OrientationRotate90:
+		return h - 1 - y, x
+	case OrientationTransverse:
+		return h - 1 - y, w - 1 - x
+	case OrientationRotate270:
+		return y, w - 1 - x
+	default:
+		return x, y
+	}
+}
+
+// OrientRect maps r, given in a w x h image stored with the given
+// orientation, to the upright image. Use InverseOrientation to map back.
+func OrientRect(r image.Rectangle, orientation, w, h int) image.Rectangle {
+	switch orientation {
+	case OrientationFlipH:
+		return image.Rect(w-r.Max.X, r.Min.Y, w-r.Min.X, r.Max.Y)
+	case OrientationRotate180:
+		return image.Rect(w-r.Max.X, h-r.Max.Y, w-r.Min.X, h-r.Min.Y)
+	case OrientationFlipV:
+		return image.Rect(r.Min.X, h-r.Max.Y, r.Max.X, h-r.Min.Y)
+	case OrientationTranspose:
+		return image.Rect(r.Min.Y, r.Min.X, r.Max.Y, r.Max.X)
+	case OrientationRotate90:
+		return image.Rect(h-r.Max.Y, r.Min.X, h-r.Min.Y, r.Max.X)
+	case OrientationTransverse:
+		return image.Rect(h-r.Max.Y, w-r.Max.X, h-r.Min.Y, w-r.Min.X)
+	case OrientationRotate270:
+		return image.Rect(r.Min.Y, w-r.Max.X, r.Max.Y, w-r.Min.X)
+	default:
+		return r
+	}
+}
+
+// OrientPoint maps the pixel p of a w x h image stored with the given
+// orientation to the upright image.
+func OrientPoint(p image.Point, orientation, w, h int) image.Point {
+	x, y := orientPoint(p.X, p.Y, orientation, w, h)
+	return image.Pt(x, y)
+}
+
+// InverseOrientation returns the orientation that undoes the given one.
+func InverseOrientation(orientation int) int {
+	switch orientation {
+	case OrientationRotate90:
+		return OrientationRotate270
+	case OrientationRotate270:
+		return OrientationRotate90
+	default:
+		return orientation
+	}
+}
+
+// rightAngleOrientations maps clockwise right angle rotations to the EXIF
+// orientation that applies them.
+var rightAngleOrientations = map[int]int{
+	0:   OrientationNormal,
+	90:  OrientationRotate90,
+	180: OrientationRotate180,
+	270: OrientationRotate270,
+}
+
+// Rotate rotates the image clockwise by deg degrees around its center onto a
+// canvas just large enough to hold it. Right angles are exact, other angles
+// are bilinearly sampled with uncovered corners left transparent.
+func Rotate(img image.Image, deg float64) image.Image {
+	if orientation, ok := rightAngleOrientations[normalizeAngle(deg)]; ok && deg == math.Trunc(deg) {
+		return Orient(img, orientation)
+	}
+
+	src := ToRGBA(img)
+	bounds := src.Bounds()
+	w, h := float64(bounds.Dx()), float64(bounds.Dy())
+	sin, cos := math.Sincos(deg * math.Pi / 180)
+	dstW, dstH := rotatedSize(bounds.Dx(), bounds.Dy(), deg)
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+
+	cx, cy := w/2, h/2
+	dcx, dcy := float64(dstW)/2, float64(dstH)/2
+	for y := 0; y < dstH; y++ {
+		for x := 0; x < dstW; x++ {
+			// Inverse rotation of the destination pixel center.
+			dx, dy := float64(x)+0.5-dcx, float64(y)+0.5-dcy
+			sx := cx + dx*cos + dy*sin - 0.5
+			sy := cy - dx*sin + dy*cos - 0.5
+			if sx < -0.5 || sy < -0.5 || sx > w-0.5 || sy > h-0.5 {
+				continue
+			}
+			x0, wx := splitCoord(sx, bounds.Dx())
+			y0, wy := splitCoord(sy, bounds.Dy())
+			x1, y1 := min(x0+1, bounds.Dx()-1), min(y0+1, bounds.Dy()-1)
+			p00 := src.Pix[src.PixOffset(bounds.Min.X+x0, bounds.Min.Y+y0):]
+			p10 := src.Pix[src.PixOffset(bounds.Min.X+x1, bounds.Min.Y+y0):]
+			p01 := src.Pix[src.PixOffset(bounds.Min.X+x0, bounds.Min.Y+y1):]
+			p11 := src.Pix[src.PixOffset(bounds.Min.X+x1, bounds.Min.Y+y1):]
+			out := dst.Pix[dst.PixOffset(x, y):]
+			for c := 0; c < 4; c++ {
+				top := float64(p00[c])*(1-wx) + float64(p10[c])*wx
+				bottom := float64(p01[c])*(1-wx) + float64(p11[c])*wx
+				out[c] = uint8(top*(1-wy) + bottom*wy + 0.5)
+			}
+		}
+	}
+	return dst
+}
+
+// UnrotateRect maps r, found on a w x h image rotated with Rotate by deg
+// degrees, back to the unrotated image. For angles other than right angles
+// the result is the bounding box of the rotated corners.
+func UnrotateRect(r image.Rectangle, deg float64, w, h int) image.Rectangle {
+	if orientation, ok := rightAngleOrientations[normalizeAngle(deg)]; ok && deg == math.Trunc(deg) {
+		rw, rh := w, h
+		if orientation == OrientationRotate90 || orientation == OrientationRotate270 {
+			rw, rh = h, w
+		}
+		return OrientRect(r, InverseOrientation(orientation), rw, rh)
+	}
+
+	sin, cos := math.Sincos(deg * math.Pi / 180)
+	fw, fh := float64(w), float64(h)
+	rw, rh := rotatedSize(w, h, deg)
+	dstW, dstH := float64(rw), float64(rh)
+
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, p := range [][2]float64{
+		{float64(r.Min.X), float64(r.Min.Y)},
+		{float64(r.Max.X), float64(r.Min.Y)},
+		{float64(r.Min.X), float64(r.Max.Y)},
+		{float64(r.Max.X), float64(r.Max.Y)},
+	} {
+		dx, dy := p[0]-dstW/2, p[1]-dstH/2
+		x := fw/2 + dx*cos + dy*sin
+		y := fh/2 - dx*sin + dy*cos
+		minX, maxX = math.Min(minX, x), math.Max(maxX, x)
+		minY, maxY = math.Min(minY, y), math.Max(maxY, y)
+	}
+	return image.Rect(int(math.Floor(minX)), int(math.Floor(minY)), int(math.Ceil(maxX)), int(math.Ceil(maxY))).
+		Intersect(image.Rect(0, 0, w, h))
+}
+
+// UnrotatePoint maps p, found on a w x h image rotated with Rotate by deg
+// degrees, back to the unrotated image.
+func UnrotatePoint(p image.Point, deg float64, w, h int) image.Point {
+	sin, cos := math.Sincos(deg * math.Pi / 180)
+	rw, rh := rotatedSize(w, h, deg)
+	// Rotate around pixel centers, as Rotate samples them.
+	dx, dy := float64(p.X)+0.5-float64(rw)/2, float64(p.Y)+0.5-float64(rh)/2
+	x := float64(w)/2 + dx*cos + dy*sin
+	y := float64(h)/2 - dx*sin + dy*cos
+	return image.Pt(int(math.Floor(x+1e-9)), int(math.Floor(y+1e-9)))
+}
+
+// rotatedSize returns the size of the canvas Rotate draws a w x h image
+// rotated by deg degrees on.
+func rotatedSize(w, h int, deg float64) (int, int) {
+	if orientation, ok := rightAngleOrientations[normalizeAngle(deg)]; ok && deg == math.Trunc(deg) {
+		if orientation == OrientationRotate90 || orientation == OrientationRotate270 {
+			return h, w
+		}
+		return w, h
+	}
+	sin, cos := math.Sincos(deg * math.Pi / 180)
+	fw, fh := float64(w), float64(h)
+	return int(math.Ceil(math.Abs(fw*cos) + math.Abs(fh*sin))), int(math.Ceil(math.Abs(fw*sin) + math.Abs(fh*cos)))
+}
+
+// normalizeAngle returns the whole degrees of deg in the [0, 360) range.
+func normalizeAngle(deg float64) int {
+	return ((int(deg) % 360) + 360) % 360
+}
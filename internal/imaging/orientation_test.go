@@ -0,0 +1,145 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestOrientRect(t *testing.T) {
+	const w, h = 40, 30
+	r := image.Rect(1, 2, 11, 7)
+	tests := []struct {
+		name        string
+		orientation int
+		want        image.Rectangle
+	}{
+		{name: "normal", orientation: OrientationNormal, want: image.Rect(1, 2, 11, 7)},
+		{name: "flip horizontal", orientation: OrientationFlipH, want: image.Rect(29, 2, 39, 7)},
+		{name: "rotate 180", orientation: OrientationRotate180, want: image.Rect(29, 23, 39, 28)},
+		{name: "flip vertical", orientation: OrientationFlipV, want: image.Rect(1, 23, 11, 28)},
+		{name: "transpose", orientation: OrientationTranspose, want: image.Rect(2, 1, 7, 11)},
+		{name: "rotate 90", orientation: OrientationRotate90, want: image.Rect(23, 1, 28, 11)},
+		{name: "transverse", orientation: OrientationTransverse, want: image.Rect(23, 29, 28, 39)},
+		{name: "rotate 270", orientation: OrientationRotate270, want: image.Rect(2, 29, 7, 39)},
+		{name: "unknown", orientation: 9, want: image.Rect(1, 2, 11, 7)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := OrientRect(r, tt.orientation, w, h)
+			if got != tt.want {
+				t.Fatalf("OrientRect(%v, %d) = %v, want %v", r, tt.orientation, got, tt.want)
+			}
+
+			// The rectangle must cover exactly the pixels Orient moves r to.
+			var pixels image.Rectangle
+			for y := r.Min.Y; y < r.Max.Y; y++ {
+				for x := r.Min.X; x < r.Max.X; x++ {
+					p := OrientPoint(image.Pt(x, y), tt.orientation, w, h)
+					pixels = pixels.Union(image.Rect(p.X, p.Y, p.X+1, p.Y+1))
+				}
+			}
+			if got != pixels {
+				t.Errorf("OrientRect(%v, %d) = %v, pixels moved to %v", r, tt.orientation, got, pixels)
+			}
+		})
+	}
+}
+
+func TestInverseOrientation(t *testing.T) {
+	const w, h = 40, 30
+	r := image.Rect(1, 2, 11, 7)
+	tests := []struct {
+		orientation int
+		want        int
+	}{
+		{orientation: OrientationNormal, want: OrientationNormal},
+		{orientation: OrientationFlipH, want: OrientationFlipH},
+		{orientation: OrientationRotate180, want: OrientationRotate180},
+		{orientation: OrientationFlipV, want: OrientationFlipV},
+		{orientation: OrientationTranspose, want: OrientationTranspose},
+		{orientation: OrientationRotate90, want: OrientationRotate270},
+		{orientation: OrientationTransverse, want: OrientationTransverse},
+		{orientation: OrientationRotate270, want: OrientationRotate90},
+	}
+	for _, tt := range tests {
+		got := InverseOrientation(tt.orientation)
+		if got != tt.want {
+			t.Errorf("InverseOrientation(%d) = %d, want %d", tt.orientation, got, tt.want)
+			continue
+		}
+
+		// Mapping upright and back must return the original rectangle.
+		uw, uh := w, h
+		if tt.orientation >= OrientationTranspose {
+			uw, uh = h, w
+		}
+		upright := OrientRect(r, tt.orientation, w, h)
+		if back := OrientRect(upright, got, uw, uh); back != r {
+			t.Errorf("orientation %d: round trip of %v through %v = %v", tt.orientation, r, upright, back)
+		}
+	}
+}
+
+// brightest returns the brightest pixel of img.
+func brightest(img image.Image) image.Point {
+	var best image.Point
+	var bestLuma uint32
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			r, g, bl, _ := img.At(x, y).RGBA()
+			if luma := r + g + bl; luma > bestLuma {
+				best, bestLuma = image.Pt(x, y), luma
+			}
+		}
+	}
+	return best
+}
+
+func TestRotate(t *testing.T) {
+	marker := image.Pt(5, 3)
+	tests := []struct {
+		name     string
+		deg      float64
+		wantSize image.Point
+		// wantMarker is where the marker lands on the rotated image, only
+		// checked for right angles.
+		wantMarker *image.Point
+	}{
+		{name: "upright", deg: 0, wantSize: image.Pt(40, 20), wantMarker: &image.Point{X: 5, Y: 3}},
+		{name: "90 clockwise", deg: 90, wantSize: image.Pt(20, 40), wantMarker: &image.Point{X: 16, Y: 5}},
+		{name: "180", deg: 180, wantSize: image.Pt(40, 20), wantMarker: &image.Point{X: 34, Y: 16}},
+		{name: "270 clockwise", deg: 270, wantSize: image.Pt(20, 40), wantMarker: &image.Point{X: 3, Y: 34}},
+		{name: "90 counterclockwise", deg: -90, wantSize: image.Pt(20, 40), wantMarker: &image.Point{X: 3, Y: 34}},
+		{name: "15 degree tilt", deg: 15, wantSize: image.Pt(44, 30)},
+		{name: "-15 degree tilt", deg: -15, wantSize: image.Pt(44, 30)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img := image.NewGray(image.Rect(0, 0, 40, 20))
+			img.SetGray(marker.X, marker.Y, color.Gray{Y: 255})
+
+			rotated := Rotate(img, tt.deg)
+			if got := rotated.Bounds().Size(); got != tt.wantSize {
+				t.Fatalf("Rotate() size = %v, want %v", got, tt.wantSize)
+			}
+			found := brightest(rotated)
+			if tt.wantMarker != nil && found != *tt.wantMarker {
+				t.Errorf("Rotate() moved the marker to %v, want %v", found, *tt.wantMarker)
+			}
+
+			back := UnrotatePoint(found, tt.deg, 40, 20)
+			if d := back.Sub(marker); d.X*d.X+d.Y*d.Y > 2 {
+				t.Errorf("UnrotatePoint(%v) = %v, want near %v", found, back, marker)
+			}
+			box := UnrotateRect(image.Rect(found.X-1, found.Y-1, found.X+2, found.Y+2), tt.deg, 40, 20)
+			if !marker.In(box) {
+				t.Errorf("UnrotateRect() = %v, want it to contain %v", box, marker)
+			}
+			if !box.In(image.Rect(0, 0, 40, 20)) {
+				t.Errorf("UnrotateRect() = %v outside the unrotated image", box)
+			}
+		})
+	}
+}
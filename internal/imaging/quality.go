@@ -0,0 +1,49 @@
+package imaging
+
+import (
+	"image"
+	"math"
+)
+
+// Sharpness returns the variance of the Laplacian of the grayscale image, a
+// focus measure that drops for blurred images. Images smaller than 3x3
+// pixels have no sharpness.
+func Sharpness(img image.Image) float64 {
+	gray := Grayscale(img)
+	b := gray.Bounds()
+	if b.Dx() < 3 || b.Dy() < 3 {
+		return 0
+	}
+
+	var sum, sumSq float64
+	n := 0
+	for y := b.Min.Y + 1; y < b.Max.Y-1; y++ {
+		for x := b.Min.X + 1; x < b.Max.X-1; x++ {
+			lap := 4*float64(gray.GrayAt(x, y).Y) -
+				float64(gray.GrayAt(x-1, y).Y) - float64(gray.GrayAt(x+1, y).Y) -
+				float64(gray.GrayAt(x, y-1).Y) - float64(gray.GrayAt(x, y+1).Y)
+			sum += lap
+			sumSq += lap * lap
+			n++
+		}
+	}
+	mean := sum / float64(n)
+	return sumSq/float64(n) - mean*mean
+}
+
+// LumaStats returns the mean and the standard deviation of the luma of the
+// image, from 0 to 255.
+func LumaStats(img image.Image) (mean, stddev float64) {
+	gray := Grayscale(img)
+	var sum, sumSq float64
+	for _, v := range gray.Pix {
+		sum += float64(v)
+		sumSq += float64(v) * float64(v)
+	}
+	n := float64(len(gray.Pix))
+	if n == 0 {
+		return 0, 0
+	}
+	mean = sum / n
+	return mean, math.Sqrt(math.Max(0, sumSq/n-mean*mean))
+}
@@ -0,0 +1,99 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// Blur applies a Gaussian blur with the given radius to the r region of img.
+// The Gaussian is approximated by three successive box blur passes.
+func Blur(img *image.RGBA, r image.Rectangle, radius int) {
+	r = r.Intersect(img.Bounds())
+	if r.Empty() || radius < 1 {
+		return
+	}
+	for i := 0; i < 3; i++ {
+		boxBlur(img, r, radius, true)
+		boxBlur(img, r, radius, false)
+	}
+}
+
+// boxBlur averages every pixel of r with its neighbours within radius along
+// one axis, using a running sum so the cost does not depend on the radius.
+func boxBlur(img *image.RGBA, r image.Rectangle, radius int, horizontal bool) {
+	outer, inner := r.Dy(), r.Dx()
+	if !horizontal {
+		outer, inner = inner, outer
+	}
+	offset := func(o, i int) int {
+		if horizontal {
+			return img.PixOffset(r.Min.X+i, r.Min.Y+o)
+		}
+		return img.PixOffset(r.Min.X+o, r.Min.Y+i)
+	}
+
+	line := make([]uint8, inner*4)
+	for o := 0; o < outer; o++ {
+		for i := 0; i < inner; i++ {
+			copy(line[i*4:i*4+4], img.Pix[offset(o, i):])
+		}
+		var sum [4]int
+		at := func(i int) []uint8 {
+			if i < 0 {
+				i = 0
+			} else if i >= inner {
+				i = inner - 1
+			}
+			return line[i*4 : i*4+4]
+		}
+		for i := -radius; i <= radius; i++ {
+			px := at(i)
+			for c := 0; c < 4; c++ {
+				sum[c] += int(px[c])
+			}
+		}
+		window := 2*radius + 1
+		for i := 0; i < inner; i++ {
+			dst := img.Pix[offset(o, i):]
+			for c := 0; c < 4; c++ {
+				dst[c] = uint8(sum[c] / window)
+			}
+			add, sub := at(i+radius+1), at(i-radius)
+			for c := 0; c < 4; c++ {
+				sum[c] += int(add[c]) - int(sub[c])
+			}
+		}
+	}
+}
+
+// Pixelate replaces the r region of img with blocks of the given size, each
+// filled with its average color.
+func Pixelate(img *image.RGBA, r image.Rectangle, block int) {
+	r = r.Intersect(img.Bounds())
+	if r.Empty() || block < 1 {
+		return
+	}
+	for y := r.Min.Y; y < r.Max.Y; y += block {
+		for x := r.Min.X; x < r.Max.X; x += block {
+			cell := image.Rect(x, y, x+block, y+block).Intersect(r)
+			var sum [4]int
+			for cy := cell.Min.Y; cy < cell.Max.Y; cy++ {
+				for cx := cell.Min.X; cx < cell.Max.X; cx++ {
+					px := img.Pix[img.PixOffset(cx, cy):]
+					for c := 0; c < 4; c++ {
+						sum[c] += int(px[c])
+					}
+				}
+			}
+			n := cell.Dx() * cell.Dy()
+			avg := color.RGBA{uint8(sum[0] / n), uint8(sum[1] / n), uint8(sum[2] / n), uint8(sum[3] / n)}
+			draw.Draw(img, cell, image.NewUniform(avg), image.Point{}, draw.Src)
+		}
+	}
+}
+
+// Fill paints the r region of img with a solid color.
+func Fill(img draw.Image, r image.Rectangle, c color.Color) {
+	draw.Draw(img, r, image.NewUniform(c), image.Point{}, draw.Src)
+}
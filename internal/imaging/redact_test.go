@@ -0,0 +1,88 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// checkerboard returns a w x h image of alternating black and white pixels.
+func checkerboard(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			v := uint8(0)
+			if (x+y)%2 == 0 {
+				v = 255
+			}
+			img.SetRGBA(x, y, color.RGBA{R: v, G: v, B: v, A: 255})
+		}
+	}
+	return img
+}
+
+func TestRedact(t *testing.T) {
+	region := image.Rect(8, 8, 24, 24)
+	tests := []struct {
+		name   string
+		redact func(img *image.RGBA, r image.Rectangle)
+		r      image.Rectangle
+		// maxSpread is the largest luma difference left between
+		// neighbouring pixels of the region, -1 when the region must be
+		// unchanged.
+		maxSpread int
+		want      *color.RGBA
+	}{
+		{name: "blur", redact: func(img *image.RGBA, r image.Rectangle) { Blur(img, r, 3) }, r: region, maxSpread: 40},
+		{name: "pixelate", redact: func(img *image.RGBA, r image.Rectangle) { Pixelate(img, r, 4) }, r: region, maxSpread: 0},
+		{name: "box", redact: func(img *image.RGBA, r image.Rectangle) { Fill(img, r, color.Black) }, r: region, maxSpread: 0, want: &color.RGBA{A: 255}},
+		{name: "blur without radius", redact: func(img *image.RGBA, r image.Rectangle) { Blur(img, r, 0) }, r: region, maxSpread: -1},
+		{name: "pixelate without block", redact: func(img *image.RGBA, r image.Rectangle) { Pixelate(img, r, 0) }, r: region, maxSpread: -1},
+		{name: "region outside the image", redact: func(img *image.RGBA, r image.Rectangle) { Blur(img, r, 3) }, r: image.Rect(40, 40, 50, 50), maxSpread: -1},
+		{name: "region clipped to the image", redact: func(img *image.RGBA, r image.Rectangle) { Pixelate(img, r, 4) }, r: image.Rect(24, 24, 40, 40), maxSpread: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img := checkerboard(32, 32)
+			orig := checkerboard(32, 32)
+			tt.redact(img, tt.r)
+
+			inside := tt.r.Intersect(img.Bounds())
+			for y := 0; y < 32; y++ {
+				for x := 0; x < 32; x++ {
+					got, was := img.RGBAAt(x, y), orig.RGBAAt(x, y)
+					if !image.Pt(x, y).In(inside) || tt.maxSpread < 0 {
+						if got != was {
+							t.Fatalf("pixel (%d, %d) outside the region changed from %v to %v", x, y, was, got)
+						}
+						continue
+					}
+					if tt.want != nil && got != *tt.want {
+						t.Fatalf("pixel (%d, %d) = %v, want %v", x, y, got, *tt.want)
+					}
+				}
+			}
+			if tt.maxSpread < 0 {
+				return
+			}
+
+			// Within pixelate cells and filled boxes neighbours are equal, a
+			// blur leaves only small differences of the checkerboard.
+			cell := 1
+			if tt.name != "blur" {
+				cell = 4
+			}
+			for y := inside.Min.Y; y < inside.Max.Y; y++ {
+				for x := inside.Min.X; x+1 < inside.Max.X; x++ {
+					if (x+1-inside.Min.X)%cell == 0 && cell > 1 {
+						continue
+					}
+					a, b := int(img.RGBAAt(x, y).R), int(img.RGBAAt(x+1, y).R)
+					if d := a - b; d > tt.maxSpread || -d > tt.maxSpread {
+						t.Fatalf("pixels (%d, %d) and (%d, %d) differ by %d, want at most %d", x, y, x+1, y, d, tt.maxSpread)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package index
+
+import (
+	"container/heap"
+	"sync"
+
+	"github.com/anggi-susanto/go-face-detection-be/domain"
+	"github.com/anggi-susanto/go-face-detection-be/internal/embedding"
+)
+
+// Entry is a single face embedding stored in an Index.
+type Entry struct {
+	PhotoID   domain.PhotoID
+	FaceIndex int
+	Vector    []float32
+	// Quality lets searches skip low quality faces.
+	Quality domain.FaceQuality
+}
+
+// Match is an entry returned by a search with its cosine similarity to the
+// query vector.
+type Match struct {
+	PhotoID   domain.PhotoID
+	FaceIndex int
+	Score     float64
+}
+
+// Index finds the face embeddings most similar to a query vector. It is the
+// extension point for approximate nearest neighbour backends.
+type Index interface {
+	// Add stores the entries, replacing any earlier entries of their photos.
+	Add(entries ...Entry)
+	// Remove drops every entry of the photo.
+	Remove(photoID domain.PhotoID)
+	// Search returns at most k matches ordered by descending score among
+	// the entries accepted by filter, every entry when filter is nil.
+	Search(vector []float32, k int, filter func(Entry) bool) []Match
+	// Len returns the number of stored entries.
+	Len() int
+}
+
+// bruteForceIndex compares the query against every stored vector. It is
+// exact and fast enough for collections up to a few hundred thousand faces.
+type bruteForceIndex struct {
+	mu      sync.RWMutex
+	entries map[domain.PhotoID][]Entry
+	size    int
+}
+
+// NewBruteForceIndex creates an exact in-memory index.
+//
+// Returns:
+// - Index: The empty index.
+func NewBruteForceIndex() Index {
+	return &bruteForceIndex{entries: make(map[domain.PhotoID][]Entry)}
+}
+
+func (b *bruteForceIndex) Add(entries ...Entry) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	replaced := make(map[domain.PhotoID]bool)
+	for _, e := range entries {
+		if !replaced[e.PhotoID] {
+			b.size -= len(b.entries[e.PhotoID])
+			delete(b.entries, e.PhotoID)
+			replaced[e.PhotoID] = true
+		}
+		b.entries[e.PhotoID] = append(b.entries[e.PhotoID], e)
+		b.size++
+	}
+}
+
+func (b *bruteForceIndex) Remove(photoID domain.PhotoID) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.size -= len(b.entries[photoID])
+	delete(b.entries, photoID)
+}
+
+func (b *bruteForceIndex) Search(vector []float32, k int, filter func(Entry) bool) []Match {
+	if k <= 0 {
+		return nil
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	// Keep the k best matches in a min-heap on score.
+	top := make(matchHeap, 0, k)
+	for _, entries := range b.entries {
+		for _, e := range entries {
+			if filter != nil && !filter(e) {
+				continue
+			}
+			m := Match{PhotoID: e.PhotoID, FaceIndex: e.FaceIndex, Score: embedding.Cosine(vector, e.Vector)}
+			if len(top) < k {
+				heap.Push(&top, m)
+			} else if m.Score > top[0].Score {
+				top[0] = m
+				heap.Fix(&top, 0)
+			}
+		}
+	}
+
+	matches := make([]Match, len(top))
+	for i := len(top) - 1; i >= 0; i-- {
+		matches[i] = heap.Pop(&top).(Match)
+	}
+	return matches
+}
+
+func (b *bruteForceIndex) Len() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.size
+}
+
+// matchHeap is a min-heap of matches ordered by score.
+type matchHeap []Match
+
+func (h matchHeap) Len() int           { return len(h) }
+func (h matchHeap) Less(i, j int) bool { return h[i].Score < h[j].Score }
+func (h matchHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *matchHeap) Push(x any)        { *h = append(*h, x.(Match)) }
+func (h *matchHeap) Pop() any {
+	old := *h
+	m := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return m
+}
+
+// PhotoEntries returns an entry for every face of the photo that has an
+// embedding.
+func PhotoEntries(photo *domain.Photo) []Entry {
+	var entries []Entry
+	for i, face := range photo.Faces {
+		if len(face.Embedding) == 0 {
+			continue
+		}
+		entries = append(entries, Entry{PhotoID: photo.ID, FaceIndex: i, Vector: face.Embedding, Quality: face.Quality})
+	}
+	return entries
+}
@@ -0,0 +1,150 @@
+package index
+
+import (
+	"math"
+	"testing"
+
+	"github.com/anggi-susanto/go-face-detection-be/domain"
+)
+
+func TestBruteForceIndex(t *testing.T) {
+	a, b, c := domain.NewPhotoID(), domain.NewPhotoID(), domain.NewPhotoID()
+	// Vectors at 0, 30, 60 and 90 degrees from the query.
+	at := func(deg float64) []float32 {
+		sin, cos := math.Sincos(deg * math.Pi / 180)
+		return []float32{float32(cos), float32(sin)}
+	}
+	query := at(0)
+
+	type match struct {
+		photo domain.PhotoID
+		face  int
+	}
+	tests := []struct {
+		name    string
+		build   func(idx Index)
+		k       int
+		filter  func(Entry) bool
+		want    []match
+		wantLen int
+	}{
+		{name: "empty", build: func(idx Index) {}, k: 5, want: nil},
+		{
+			name: "ordered by score",
+			build: func(idx Index) {
+				idx.Add(Entry{PhotoID: a, FaceIndex: 0, Vector: at(60)}, Entry{PhotoID: a, FaceIndex: 1, Vector: at(0)})
+				idx.Add(Entry{PhotoID: b, FaceIndex: 0, Vector: at(30)}, Entry{PhotoID: b, FaceIndex: 1, Vector: at(90)})
+			},
+			k:       5,
+			want:    []match{{a, 1}, {b, 0}, {a, 0}, {b, 1}},
+			wantLen: 4,
+		},
+		{
+			name: "top k",
+			build: func(idx Index) {
+				idx.Add(Entry{PhotoID: a, Vector: at(90)}, Entry{PhotoID: a, FaceIndex: 1, Vector: at(30)})
+				idx.Add(Entry{PhotoID: b, Vector: at(60)}, Entry{PhotoID: b, FaceIndex: 1, Vector: at(0)})
+			},
+			k:       2,
+			want:    []match{{b, 1}, {a, 1}},
+			wantLen: 4,
+		},
+		{
+			name:  "zero k",
+			build: func(idx Index) { idx.Add(Entry{PhotoID: a, Vector: at(0)}) },
+			k:     0, want: nil, wantLen: 1,
+		},
+		{
+			name: "add replaces the entries of a photo",
+			build: func(idx Index) {
+				idx.Add(Entry{PhotoID: a, Vector: at(0)}, Entry{PhotoID: a, FaceIndex: 1, Vector: at(10)})
+				idx.Add(Entry{PhotoID: a, FaceIndex: 2, Vector: at(60)})
+			},
+			k:       5,
+			want:    []match{{a, 2}},
+			wantLen: 1,
+		},
+		{
+			name: "remove drops every entry of a photo",
+			build: func(idx Index) {
+				idx.Add(Entry{PhotoID: a, Vector: at(0)}, Entry{PhotoID: a, FaceIndex: 1, Vector: at(10)})
+				idx.Add(Entry{PhotoID: b, Vector: at(60)})
+				idx.Remove(a)
+				idx.Remove(c)
+			},
+			k:       5,
+			want:    []match{{b, 0}},
+			wantLen: 1,
+		},
+		{
+			name: "filter",
+			build: func(idx Index) {
+				idx.Add(Entry{PhotoID: a, Vector: at(0), Quality: domain.FaceQuality{Size: 20}})
+				idx.Add(Entry{PhotoID: b, Vector: at(30), Quality: domain.FaceQuality{Size: 80}})
+			},
+			k:       5,
+			filter:  func(e Entry) bool { return e.Quality.Size >= 40 },
+			want:    []match{{b, 0}},
+			wantLen: 2,
+		},
+		{
+			name:    "other dimension scores zero",
+			build:   func(idx Index) { idx.Add(Entry{PhotoID: a, Vector: []float32{1, 0, 0}}) },
+			k:       5,
+			want:    []match{{a, 0}},
+			wantLen: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idx := NewBruteForceIndex()
+			tt.build(idx)
+			if got := idx.Len(); got != tt.wantLen {
+				t.Errorf("Len() = %d, want %d", got, tt.wantLen)
+			}
+			got := idx.Search(query, tt.k, tt.filter)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Search() = %+v, want %+v", got, tt.want)
+			}
+			for i, m := range got {
+				if m.PhotoID != tt.want[i].photo || m.FaceIndex != tt.want[i].face {
+					t.Errorf("match %d = %s/%d, want %s/%d", i, m.PhotoID, m.FaceIndex, tt.want[i].photo, tt.want[i].face)
+				}
+				if i > 0 && m.Score > got[i-1].Score {
+					t.Errorf("match %d scores %v above match %d with %v", i, m.Score, i-1, got[i-1].Score)
+				}
+			}
+		})
+	}
+}
+
+func TestPhotoEntries(t *testing.T) {
+	id := domain.NewPhotoID()
+	tests := []struct {
+		name  string
+		faces []domain.Face
+		want  []int
+	}{
+		{name: "no faces"},
+		{name: "faces without embeddings", faces: []domain.Face{{}, {}}},
+		{
+			name:  "only embedded faces",
+			faces: []domain.Face{{Embedding: []float32{1}}, {}, {Embedding: []float32{0, 1}, Quality: domain.FaceQuality{Size: 50}}},
+			want:  []int{0, 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := PhotoEntries(&domain.Photo{ID: id, Faces: tt.faces})
+			if len(got) != len(tt.want) {
+				t.Fatalf("PhotoEntries() = %+v, want faces %v", got, tt.want)
+			}
+			for i, e := range got {
+				face := tt.faces[tt.want[i]]
+				if e.PhotoID != id || e.FaceIndex != tt.want[i] || len(e.Vector) != len(face.Embedding) || e.Quality.Size != face.Quality.Size {
+					t.Errorf("entry %d = %+v, want face %d of %s", i, e, tt.want[i], id)
+				}
+			}
+		})
+	}
+}
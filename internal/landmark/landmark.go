@@ -0,0 +1,270 @@
+package landmark
+
+import (
+	"image"
+	"math"
+	"sort"
+
+	"github.com/anggi-susanto/go-face-detection-be/domain"
+	"github.com/anggi-susanto/go-face-detection-be/internal/imaging"
+)
+
+// MinFaceSize is the smallest face side length in pixels landmarks are
+// located for. Expression cues and occlusion hints use the same limit.
+const MinFaceSize = 24
+
+const (
+	// size is the side length every face is resampled to before the search.
+	size = 96
+
+	// noseDepth is the distance of the nose tip in front of the eyes, as a
+	// fraction of the distance between the eyes.
+	noseDepth = 0.6
+	// noseRatio is the height of the nose tip between the eyes and the mouth
+	// of a frontal face, as a fraction of that distance.
+	noseRatio = 0.6
+)
+
+// window is a search region in fractions of the face size.
+type window struct{ x0, y0, x1, y1 float64 }
+
+var (
+	leftEyeWindow  = window{0.12, 0.22, 0.48, 0.55}
+	rightEyeWindow = window{0.52, 0.22, 0.88, 0.55}
+	mouthWindow    = window{0.25, 0.62, 0.75, 0.92}
+)
+
+func (w window) rect() image.Rectangle {
+	return image.Rect(int(w.x0*size), int(w.y0*size), int(w.x1*size), int(w.y1*size))
+}
+
+// field is a grayscale image as floats.
+type field struct {
+	w, h int
+	v    []float64
+}
+
+func (f *field) at(x, y int) float64 { return f.v[y*f.w+x] }
+
+// Locate finds the eye centers, nose tip and mouth corners of the upright
+// face that fills img, in pixel coordinates of img. Eyes are the most
+// compact dark blobs of the upper face, the mouth is the darkest row of the
+// lower face and the nose tip sits above the darkest spot, the nostrils,
+// between them.
+func Locate(face image.Image) domain.Landmarks {
+	gray := imaging.Grayscale(imaging.Resize(face, size, size))
+	img := &field{w: size, h: size, v: make([]float64, size*size)}
+	for i, p := range gray.Pix {
+		img.v[i] = float64(p)
+	}
+	fine, coarse := blur(img, 1), blur(img, 5)
+
+	// Dark blobs on a brighter surrounding have the most negative response.
+	blobs := &field{w: size, h: size, v: make([]float64, size*size)}
+	for i := range blobs.v {
+		blobs.v[i] = fine.v[i] - coarse.v[i]
+	}
+	leftEye := centroid(fine, argmin(blobs, leftEyeWindow.rect()), size/12)
+	rightEye := centroid(fine, argmin(blobs, rightEyeWindow.rect()), size/12)
+
+	mouthLeft, mouthRight := mouthCorners(fine)
+	nose := noseTip(fine, leftEye, rightEye, (mouthLeft.Y+mouthRight.Y)/2)
+
+	bounds := face.Bounds()
+	scale := func(p image.Point) domain.Point {
+		return domain.Point{
+			X: bounds.Min.X + (2*p.X+1)*bounds.Dx()/(2*size),
+			Y: bounds.Min.Y + (2*p.Y+1)*bounds.Dy()/(2*size),
+		}
+	}
+	return domain.Landmarks{
+		LeftEye:    scale(leftEye),
+		RightEye:   scale(rightEye),
+		NoseTip:    scale(nose),
+		MouthLeft:  scale(mouthLeft),
+		MouthRight: scale(mouthRight),
+	}
+}
+
+// mouthCorners finds the darkest row of the mouth window and follows the
+// dark run around its darkest pixel to both corners.
+func mouthCorners(img *field) (image.Point, image.Point) {
+	r := mouthWindow.rect()
+	row, rowMin := r.Min.Y, math.Inf(1)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		var sum float64
+		for x := r.Min.X; x < r.Max.X; x++ {
+			sum += img.at(x, y)
+		}
+		if sum < rowMin {
+			row, rowMin = y, sum
+		}
+	}
+
+	lo, hi := size*15/100, size*85/100
+	profile := make([]float64, hi-lo)
+	for i := range profile {
+		profile[i] = img.at(lo+i, row)
+	}
+	center := lo + argminRange(profile, size*35/100-lo, size*65/100-lo)
+	sorted := append([]float64(nil), profile...)
+	sort.Float64s(sorted)
+	darkest, median := img.at(center, row), sorted[len(sorted)/2]
+	threshold := darkest + (median-darkest)/2
+
+	left, right := center, center
+	for left > lo && img.at(left-1, row) < threshold {
+		left--
+	}
+	for right < hi-1 && img.at(right+1, row) < threshold {
+		right++
+	}
+	return image.Pt(left, row), image.Pt(right, row)
+}
+
+// noseTip returns the point just above the darkness weighted centroid of the
+// band between the eyes and the mouth, where the nostrils are.
+func noseTip(img *field, leftEye, rightEye image.Point, mouthY int) image.Point {
+	eyeY := (leftEye.Y + rightEye.Y) / 2
+	y0 := eyeY + (mouthY-eyeY)*45/100
+	y1 := max(y0+1, eyeY+(mouthY-eyeY)*85/100)
+	x0, x1 := min(leftEye.X, rightEye.X), max(leftEye.X, rightEye.X)+1
+
+	var mean float64
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			mean += img.at(x, y)
+		}
+	}
+	mean /= float64((y1 - y0) * (x1 - x0))
+
+	var sx, sy, sw float64
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			if w := mean - img.at(x, y); w > 0 {
+				sx += w * float64(x)
+				sy += w * float64(y)
+				sw += w
+			}
+		}
+	}
+	if sw == 0 {
+		return image.Pt((x0+x1)/2, (y0+y1)/2)
+	}
+	return image.Pt(int(sx/sw), max(y0, int(sy/sw)-size*3/100))
+}
+
+// Pose estimates the head pose from the landmarks. Roll is the angle of the
+// eye line. Once it is undone, yaw follows from the sideways offset of the
+// nose tip from the eye midpoint and pitch from the height of the nose tip
+// between the eyes and the mouth, both relative to the nose depth.
+func Pose(l domain.Landmarks) domain.HeadPose {
+	ex, ey := float64(l.RightEye.X-l.LeftEye.X), float64(l.RightEye.Y-l.LeftEye.Y)
+	iod := math.Hypot(ex, ey)
+	if iod == 0 {
+		return domain.HeadPose{}
+	}
+	roll := math.Atan2(ey, ex)
+
+	midX, midY := float64(l.LeftEye.X+l.RightEye.X)/2, float64(l.LeftEye.Y+l.RightEye.Y)/2
+	sin, cos := math.Sincos(-roll)
+	upright := func(p domain.Point) (float64, float64) {
+		dx, dy := float64(p.X)-midX, float64(p.Y)-midY
+		return dx*cos - dy*sin, dx*sin + dy*cos
+	}
+	noseX, noseY := upright(l.NoseTip)
+	_, mouthLeftY := upright(l.MouthLeft)
+	_, mouthRightY := upright(l.MouthRight)
+	mouthY := (mouthLeftY + mouthRightY) / 2
+
+	depth := noseDepth * iod
+	pose := domain.HeadPose{
+		Yaw:  degrees(math.Atan(noseX / depth)),
+		Roll: degrees(roll),
+	}
+	if mouthY > 0 {
+		pose.Pitch = degrees(math.Atan((noseRatio*mouthY - noseY) / depth))
+	}
+	return pose
+}
+
+func degrees(rad float64) float64 {
+	return rad * 180 / math.Pi
+}
+
+// argmin returns the position of the smallest value inside r.
+func argmin(f *field, r image.Rectangle) image.Point {
+	best, bestV := r.Min, math.Inf(1)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			if v := f.at(x, y); v < bestV {
+				best, bestV = image.Pt(x, y), v
+			}
+		}
+	}
+	return best
+}
+
+// centroid returns the darkness weighted centroid of the square of the given
+// radius around p, which moves a point on the edge of a dark blob to its
+// center.
+func centroid(f *field, p image.Point, radius int) image.Point {
+	r := image.Rect(p.X-radius, p.Y-radius, p.X+radius+1, p.Y+radius+1).Intersect(image.Rect(0, 0, f.w, f.h))
+	var mean float64
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			mean += f.at(x, y)
+		}
+	}
+	mean /= float64(r.Dx() * r.Dy())
+
+	var sx, sy, sw float64
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			if w := mean - f.at(x, y); w > 0 {
+				sx += w * float64(x)
+				sy += w * float64(y)
+				sw += w
+			}
+		}
+	}
+	if sw == 0 {
+		return p
+	}
+	return image.Pt(int(math.Round(sx/sw)), int(math.Round(sy/sw)))
+}
+
+// argminRange returns the index of the smallest value of v[lo:hi].
+func argminRange(v []float64, lo, hi int) int {
+	best := lo
+	for i := lo; i < hi; i++ {
+		if v[i] < v[best] {
+			best = i
+		}
+	}
+	return best
+}
+
+// blur is a box blur of the given radius computed from an integral image,
+// with the box clamped at the borders.
+func blur(f *field, radius int) *field {
+	w, h := f.w, f.h
+	sum := make([]float64, (w+1)*(h+1))
+	for y := 0; y < h; y++ {
+		var row float64
+		for x := 0; x < w; x++ {
+			row += f.at(x, y)
+			sum[(y+1)*(w+1)+x+1] = sum[y*(w+1)+x+1] + row
+		}
+	}
+	out := &field{w: w, h: h, v: make([]float64, w*h)}
+	for y := 0; y < h; y++ {
+		y0, y1 := max(0, y-radius), min(h, y+radius+1)
+		for x := 0; x < w; x++ {
+			x0, x1 := max(0, x-radius), min(w, x+radius+1)
+			total := sum[y1*(w+1)+x1] - sum[y0*(w+1)+x1] - sum[y1*(w+1)+x0] + sum[y0*(w+1)+x0]
+			out.v[y*w+x] = total / float64((x1-x0)*(y1-y0))
+		}
+	}
+	return out
+}
@@ -0,0 +1,177 @@
+package landmark
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/anggi-susanto/go-face-detection-be/domain"
+)
+
+// features places the dark features of a synthetic face, in fractions of
+// its size.
+type features struct {
+	leftEye, rightEye     [2]float64
+	mouthLeft, mouthRight [2]float64
+}
+
+var frontal = features{
+	leftEye:    [2]float64{0.32, 0.4},
+	rightEye:   [2]float64{0.68, 0.4},
+	mouthLeft:  [2]float64{0.38, 0.77},
+	mouthRight: [2]float64{0.62, 0.77},
+}
+
+// syntheticFace draws a bright face of the given side length at offset with
+// dark round eyes, two nostrils halfway between the eyes and the mouth and a
+// dark mouth line.
+func syntheticFace(side int, offset image.Point, f features) image.Image {
+	img := image.NewGray(image.Rect(0, 0, side, side).Add(offset))
+	s := float64(side)
+	disc := func(cx, cy, r float64) bool { return math.Hypot(cx, cy) <= r }
+	for y := 0; y < side; y++ {
+		for x := 0; x < side; x++ {
+			fx, fy := float64(x)+0.5, float64(y)+0.5
+			v := uint8(200)
+			switch {
+			case disc(fx-f.leftEye[0]*s, fy-f.leftEye[1]*s, 0.05*s),
+				disc(fx-f.rightEye[0]*s, fy-f.rightEye[1]*s, 0.05*s):
+				v = 30
+			case disc(fx-0.46*s, fy-0.64*s, 0.02*s), disc(fx-0.54*s, fy-0.64*s, 0.02*s):
+				v = 60
+			case onSegment(fx/s, fy/s, f.mouthLeft, f.mouthRight, 0.02):
+				v = 40
+			}
+			img.SetGray(x+offset.X, y+offset.Y, color.Gray{Y: v})
+		}
+	}
+	return img
+}
+
+// onSegment reports whether (x, y) lies within width of the segment a-b.
+func onSegment(x, y float64, a, b [2]float64, width float64) bool {
+	dx, dy := b[0]-a[0], b[1]-a[1]
+	t := math.Max(0, math.Min(1, ((x-a[0])*dx+(y-a[1])*dy)/(dx*dx+dy*dy)))
+	return math.Hypot(x-a[0]-t*dx, y-a[1]-t*dy) <= width
+}
+
+func TestLocate(t *testing.T) {
+	tilted := features{
+		leftEye:    [2]float64{0.32, 0.36},
+		rightEye:   [2]float64{0.68, 0.44},
+		mouthLeft:  [2]float64{0.38, 0.75},
+		mouthRight: [2]float64{0.62, 0.79},
+	}
+	tests := []struct {
+		name     string
+		side     int
+		offset   image.Point
+		features features
+	}{
+		{name: "frontal at the search size", side: size, features: frontal},
+		{name: "frontal upscaled", side: 200, features: frontal},
+		{name: "frontal downscaled", side: 48, features: frontal},
+		{name: "offset bounds", side: 120, offset: image.Pt(30, 50), features: frontal},
+		{name: "tilted", side: 160, features: tilted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Locate(syntheticFace(tt.side, tt.offset, tt.features))
+
+			// Features are found within 6% of the face size.
+			tolerance := 0.06 * float64(tt.side)
+			s := float64(tt.side)
+			want := func(f [2]float64) domain.Point {
+				return domain.Point{X: tt.offset.X + int(f[0]*s), Y: tt.offset.Y + int(f[1]*s)}
+			}
+			check := func(name string, got, want domain.Point) {
+				if math.Hypot(float64(got.X-want.X), float64(got.Y-want.Y)) > tolerance {
+					t.Errorf("%s = %+v, want %+v", name, got, want)
+				}
+			}
+			check("LeftEye", got.LeftEye, want(tt.features.leftEye))
+			check("RightEye", got.RightEye, want(tt.features.rightEye))
+			check("MouthLeft", got.MouthLeft, want(tt.features.mouthLeft))
+			check("MouthRight", got.MouthRight, want(tt.features.mouthRight))
+
+			eyeY := (got.LeftEye.Y + got.RightEye.Y) / 2
+			mouthY := (got.MouthLeft.Y + got.MouthRight.Y) / 2
+			if got.NoseTip.Y <= eyeY || got.NoseTip.Y >= mouthY || got.NoseTip.X <= got.LeftEye.X || got.NoseTip.X >= got.RightEye.X {
+				t.Errorf("NoseTip = %+v, want between the eyes %+v, %+v and the mouth", got.NoseTip, got.LeftEye, got.RightEye)
+			}
+		})
+	}
+}
+
+func TestLocatedPoseRoll(t *testing.T) {
+	tests := []struct {
+		name     string
+		features features
+		wantSign float64
+	}{
+		{name: "right eye lower", features: features{
+			leftEye: [2]float64{0.32, 0.36}, rightEye: [2]float64{0.68, 0.44},
+			mouthLeft: [2]float64{0.38, 0.75}, mouthRight: [2]float64{0.62, 0.79},
+		}, wantSign: 1},
+		{name: "left eye lower", features: features{
+			leftEye: [2]float64{0.32, 0.44}, rightEye: [2]float64{0.68, 0.36},
+			mouthLeft: [2]float64{0.38, 0.79}, mouthRight: [2]float64{0.62, 0.75},
+		}, wantSign: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pose := Pose(Locate(syntheticFace(160, image.Point{}, tt.features)))
+			if pose.Roll*tt.wantSign < 5 {
+				t.Errorf("Roll = %v, want at least 5 degrees with sign %v", pose.Roll, tt.wantSign)
+			}
+		})
+	}
+}
+
+func TestPose(t *testing.T) {
+	// level has the eyes 100 apart and the nose tip at noseRatio of the way
+	// down to the mouth, which is a frontal, level and upright head.
+	level := domain.Landmarks{
+		LeftEye:    domain.Point{X: 100, Y: 100},
+		RightEye:   domain.Point{X: 200, Y: 100},
+		NoseTip:    domain.Point{X: 150, Y: 160},
+		MouthLeft:  domain.Point{X: 120, Y: 200},
+		MouthRight: domain.Point{X: 180, Y: 200},
+	}
+	rotate := func(l domain.Landmarks, deg float64) domain.Landmarks {
+		sin, cos := math.Sincos(deg * math.Pi / 180)
+		r := func(p domain.Point) domain.Point {
+			dx, dy := float64(p.X-150), float64(p.Y-100)
+			return domain.Point{X: 150 + int(math.Round(dx*cos-dy*sin)), Y: 100 + int(math.Round(dx*sin+dy*cos))}
+		}
+		return domain.Landmarks{LeftEye: r(l.LeftEye), RightEye: r(l.RightEye), NoseTip: r(l.NoseTip), MouthLeft: r(l.MouthLeft), MouthRight: r(l.MouthRight)}
+	}
+	turned := level
+	turned.NoseTip.X = 180
+	raised := level
+	raised.NoseTip.Y = 130
+
+	tests := []struct {
+		name string
+		l    domain.Landmarks
+		want domain.HeadPose
+	}{
+		{name: "frontal", l: level, want: domain.HeadPose{}},
+		{name: "rolled clockwise", l: rotate(level, 20), want: domain.HeadPose{Roll: 20}},
+		{name: "rolled counterclockwise", l: rotate(level, -20), want: domain.HeadPose{Roll: -20}},
+		{name: "nose to the right", l: turned, want: domain.HeadPose{Yaw: degrees(math.Atan(30.0 / 60))}},
+		{name: "nose raised", l: raised, want: domain.HeadPose{Pitch: degrees(math.Atan(30.0 / 60))}},
+		{name: "eyes on one point", l: domain.Landmarks{LeftEye: level.LeftEye, RightEye: level.LeftEye}, want: domain.HeadPose{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Pose(tt.l)
+			// Rounding the rotated landmarks to pixels moves the angles by
+			// less than a degree.
+			if math.Abs(got.Yaw-tt.want.Yaw) > 1 || math.Abs(got.Pitch-tt.want.Pitch) > 1 || math.Abs(got.Roll-tt.want.Roll) > 1 {
+				t.Errorf("Pose() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
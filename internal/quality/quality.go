@@ -0,0 +1,67 @@
+package quality
+
+import (
+	"image"
+
+	"github.com/anggi-susanto/go-face-detection-be/domain"
+	"github.com/anggi-susanto/go-face-detection-be/internal/imaging"
+	"github.com/anggi-susanto/go-face-detection-be/internal/landmark"
+)
+
+const (
+	// darkEyes is the eye region brightness, relative to the whole face,
+	// below which the eyes look covered.
+	darkEyes = 0.55
+	// smoothLowerFace is the lower face sharpness, relative to the eye
+	// region, below which the mouth and nose look covered.
+	smoothLowerFace = 0.1
+)
+
+// window is a region in fractions of the face size.
+type window struct{ x0, y0, x1, y1 float64 }
+
+var (
+	eyesWindow      = window{0.1, 0.2, 0.9, 0.5}
+	lowerFaceWindow = window{0.2, 0.6, 0.8, 0.95}
+)
+
+func (w window) crop(img image.Image) image.Image {
+	b := img.Bounds()
+	fw, fh := float64(b.Dx()), float64(b.Dy())
+	r := image.Rect(int(w.x0*fw), int(w.y0*fh), int(w.x1*fw), int(w.y1*fh)).Add(b.Min)
+	return imaging.Crop(img, r)
+}
+
+// Measure returns the quality metrics of a face. Sharpness, brightness and
+// contrast are measured on the face box as stored, occlusion hints on the
+// upright face.
+//
+// Parameters:
+// - face: The face box cropped from the photo.
+// - upright: The same face turned upright.
+//
+// Returns:
+// - domain.FaceQuality: The metrics, with Size set from the face box and no edge hint.
+func Measure(face, upright image.Image) domain.FaceQuality {
+	b := face.Bounds()
+	q := domain.FaceQuality{
+		Sharpness: imaging.Sharpness(face),
+		Size:      min(b.Dx(), b.Dy()),
+	}
+	q.Brightness, q.Contrast = imaging.LumaStats(face)
+
+	ub := upright.Bounds()
+	if ub.Dx() < landmark.MinFaceSize || ub.Dy() < landmark.MinFaceSize {
+		return q
+	}
+	faceBrightness, _ := imaging.LumaStats(upright)
+	eyes := eyesWindow.crop(upright)
+	eyesBrightness, _ := imaging.LumaStats(eyes)
+	if eyesBrightness < darkEyes*faceBrightness {
+		q.Occlusion = append(q.Occlusion, domain.OcclusionEyes)
+	}
+	if imaging.Sharpness(lowerFaceWindow.crop(upright)) < smoothLowerFace*imaging.Sharpness(eyes) {
+		q.Occlusion = append(q.Occlusion, domain.OcclusionLowerFace)
+	}
+	return q
+}
@@ -0,0 +1,56 @@
+package quality
+
+import (
+	"image"
+	"image/color"
+	"slices"
+	"testing"
+
+	"github.com/anggi-susanto/go-face-detection-be/domain"
+	"github.com/anggi-susanto/go-face-detection-be/internal/landmark"
+)
+
+// face draws a side x side face of brightness 160 whose eye band, from 20%
+// to 50% of its height, is dark or a textured checkerboard.
+func face(side int, darkEyes, texturedEyes bool) image.Image {
+	img := image.NewGray(image.Rect(0, 0, side, side))
+	for y := 0; y < side; y++ {
+		for x := 0; x < side; x++ {
+			v := uint8(160)
+			if inEyes := y >= side/5 && y < side/2; inEyes && darkEyes {
+				v = 20
+			} else if inEyes && texturedEyes && (x+y)%2 == 0 {
+				v = 255
+			}
+			img.SetGray(x, y, color.Gray{Y: v})
+		}
+	}
+	return img
+}
+
+func TestMeasure(t *testing.T) {
+	tests := []struct {
+		name          string
+		face          image.Image
+		wantSize      int
+		wantOcclusion []string
+	}{
+		{name: "plain face", face: face(40, false, false), wantSize: 40},
+		{name: "dark eyes", face: face(40, true, false), wantSize: 40, wantOcclusion: []string{domain.OcclusionEyes}},
+		{name: "smooth lower face", face: face(40, false, true), wantSize: 40, wantOcclusion: []string{domain.OcclusionLowerFace}},
+		{name: "dark eyes at the smallest size", face: face(landmark.MinFaceSize, true, false), wantSize: landmark.MinFaceSize, wantOcclusion: []string{domain.OcclusionEyes}},
+		{name: "dark eyes below the smallest size", face: face(landmark.MinFaceSize-1, true, false), wantSize: landmark.MinFaceSize - 1},
+		{name: "size is the smaller side", face: image.NewGray(image.Rect(0, 0, 50, 30)), wantSize: 30},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Measure(tt.face, tt.face)
+			if got.Size != tt.wantSize {
+				t.Errorf("Measure() size = %d, want %d", got.Size, tt.wantSize)
+			}
+			if !slices.Equal(got.Occlusion, tt.wantOcclusion) {
+				t.Errorf("Measure() occlusion = %v, want %v", got.Occlusion, tt.wantOcclusion)
+			}
+		})
+	}
+}
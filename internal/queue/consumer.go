@@ -2,16 +2,31 @@ package queue
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"strconv"
+	"image"
+	"image/color"
+	"math"
+	"time"
 
 	"github.com/anggi-susanto/go-face-detection-be/config"
 	"github.com/anggi-susanto/go-face-detection-be/domain"
+	"github.com/anggi-susanto/go-face-detection-be/internal/clustering"
+	"github.com/anggi-susanto/go-face-detection-be/internal/compliance"
+	"github.com/anggi-susanto/go-face-detection-be/internal/detector"
+	"github.com/anggi-susanto/go-face-detection-be/internal/embedding"
+	"github.com/anggi-susanto/go-face-detection-be/internal/expression"
+	"github.com/anggi-susanto/go-face-detection-be/internal/imaging"
+	"github.com/anggi-susanto/go-face-detection-be/internal/index"
+	"github.com/anggi-susanto/go-face-detection-be/internal/landmark"
+	"github.com/anggi-susanto/go-face-detection-be/internal/quality"
+	"github.com/anggi-susanto/go-face-detection-be/internal/recognition"
 	"github.com/anggi-susanto/go-face-detection-be/internal/repository/mongo"
+	"github.com/anggi-susanto/go-face-detection-be/internal/storage"
 	"github.com/sirupsen/logrus"
 	"github.com/streadway/amqp"
-
-	python3 "github.com/DataDog/go-python3"
+	mongodriver "go.mongodb.org/mongo-driver/mongo"
 )
 
 type Consumer interface {
@@ -19,14 +34,28 @@ type Consumer interface {
 }
 
 type consumer struct {
-	config *config.RabbitMqConfig
-	repo   *mongo.PhotoRepository
+	config       *config.RabbitMqConfig
+	workerConfig *config.WorkerConfig
+	compliance   *config.ComplianceConfig
+	repo         *mongo.PhotoRepository
+	detector     detector.Detector
+	embedder     embedding.Embedder
+	index        index.Index
+	gallery      recognition.Gallery
+	clusters     clustering.Assigner
 }
 
-func NewConsumer(config *config.RabbitMqConfig, repo *mongo.PhotoRepository) Consumer {
+func NewConsumer(config *config.RabbitMqConfig, workerConfig *config.WorkerConfig, compliance *config.ComplianceConfig, repo *mongo.PhotoRepository, detector detector.Detector, embedder embedding.Embedder, index index.Index, gallery recognition.Gallery, clusters clustering.Assigner) Consumer {
 	return &consumer{
-		config: config,
-		repo:   repo,
+		config:       config,
+		workerConfig: workerConfig,
+		compliance:   compliance,
+		repo:         repo,
+		detector:     detector,
+		embedder:     embedder,
+		index:        index,
+		gallery:      gallery,
+		clusters:     clusters,
 	}
 }
 
@@ -55,10 +84,15 @@ func (c *consumer) ReceiveFromQueue(ctx context.Context) {
 		logrus.Fatal(err)
 	}
 
+	concurrency := workerCount(c.workerConfig)
+	if err := ch.Qos(concurrency, 0, false); err != nil {
+		logrus.Fatal(err)
+	}
+
 	msgs, err := ch.Consume(
 		q.Name,
 		"",
-		true,
+		false,
 		false,
 		false,
 		false,
@@ -70,89 +104,398 @@ func (c *consumer) ReceiveFromQueue(ctx context.Context) {
 
 	forever := make(chan bool)
 
-	go func() {
-		for d := range msgs {
-			photoIDStr := string(d.Body)
-
-			photo, err := c.repo.FindByID(ctx, photoIDStr)
-			if err != nil {
-				logrus.Infof("Error retrieving file path: %s", err)
-				c.updateStatus(ctx, photo, "error", 0)
-				continue
+	for i := 0; i < concurrency; i++ {
+		go func() {
+			for d := range msgs {
+				c.handleMessage(ctx, d.Body)
+				if err := d.Ack(false); err != nil {
+					logrus.Errorf("Failed to ack message: %v", err)
+				}
 			}
+		}()
+	}
 
-			facesDetected, err := DetectFaces(photo.FilePath)
-			if err != nil {
-				logrus.Infof("Error processing photo: %s", err)
-				c.updateStatus(ctx, photo, "error", 0)
-			} else {
-				logrus.Infof("Successfully processed photo: %s. Faces detected: %d", photo.FilePath, facesDetected)
-				c.updateStatus(ctx, photo, "processed", facesDetected)
-			}
-		}
-	}()
-
-	logrus.Printf(" [*] Waiting for messages. To exit press CTRL+C")
+	logrus.Printf(" [*] Waiting for messages with %d workers. To exit press CTRL+C", concurrency)
 	<-forever
 }
 
-func (c *consumer) updateStatus(ctx context.Context, photo *domain.Photo, status string, facesDetected int) {
-	photo.FacesDetected = facesDetected
-	photo.Status = status
-	err := c.repo.Update(ctx, photo)
+// workerCount returns the number of messages processed in parallel, at
+// least one.
+func workerCount(workerConfig *config.WorkerConfig) int {
+	if workerConfig.Concurrency < 1 {
+		return 1
+	}
+	return workerConfig.Concurrency
+}
+
+// handleMessage processes a single face detection job.
+func (c *consumer) handleMessage(ctx context.Context, body []byte) {
+	var msg Message
+	if err := json.Unmarshal(body, &msg); err != nil {
+		logrus.Infof("Error decoding message: %s", err)
+		return
+	}
+
+	photo, err := c.repo.FindByID(ctx, msg.PhotoID)
+	if errors.Is(err, mongodriver.ErrNoDocuments) {
+		logrus.Infof("Skipping job of deleted photo %s", msg.PhotoID)
+		return
+	}
+	if err != nil {
+		logrus.Infof("Error retrieving file path: %s", err)
+		return
+	}
+
+	faces, err := c.detectFaces(ctx, photo, msg)
+	if err != nil {
+		logrus.Infof("Error processing photo: %s", err)
+		discardFailed(photo, msg.Redaction)
+		err = c.updateStatus(ctx, photo, "error", nil)
+	} else {
+		logrus.Infof("Successfully processed photo: %s. Faces detected: %d", photo.FilePath, len(faces))
+		err = c.updateStatus(ctx, photo, "processed", faces)
+	}
+	if errors.Is(err, mongodriver.ErrNoDocuments) {
+		// The photo was deleted while it was processed, its record must
+		// not come back through the index, the clusters or stray files.
+		logrus.Infof("Discarding results of deleted photo %s", photo.ID)
+		discardOutputs(photo)
+		return
+	}
 	if err != nil {
+		// Search and clusters must only point at results stored in Mongo.
 		logrus.Errorf("Failed to update status: %v", err)
+		return
+	}
+	c.index.Remove(photo.ID)
+	c.index.Add(index.PhotoEntries(photo)...)
+	if err := c.clusters.Assign(ctx, photo); err != nil {
+		logrus.Errorf("Failed to assign faces to clusters: %v", err)
+	}
+}
+
+func (c *consumer) updateStatus(ctx context.Context, photo *domain.Photo, status string, faces []domain.Face) error {
+	photo.FacesDetected = len(faces)
+	photo.Faces = faces
+	photo.Expressions = domain.SummarizeExpressions(faces)
+	photo.Status = status
+	photo.UpdatedAt = time.Now()
+	return c.repo.Update(ctx, photo)
+}
+
+// discardOutputs removes the files written while processing the photo.
+func discardOutputs(photo *domain.Photo) {
+	paths := []string{photo.RedactedPath}
+	if photo.FilePath != "" {
+		paths = append(paths, storage.ProcessedPath(photo.FilePath))
+	}
+	for _, f := range photo.Faces {
+		paths = append(paths, f.CropPath)
+	}
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		if err := storage.RemovePhoto(path); err != nil {
+			logrus.Errorf("Failed to remove %s: %v", path, err)
+		}
+	}
+}
+
+// discardFailed removes the files written by a failed job, and its original
+// when the job asked for it to be discarded, before the photo is stored with
+// the error status.
+func discardFailed(photo *domain.Photo, opts domain.RedactionOptions) {
+	discardOutputs(photo)
+	photo.RedactedPath = ""
+	discardUnredacted(photo, opts)
+}
+
+// detectFaces runs the configured detector on the photo and writes a copy
+// next to the original, either with every detected face outlined or, when
+// redaction is requested, with every face obscured.
+func (c *consumer) detectFaces(ctx context.Context, photo *domain.Photo, msg Message) ([]domain.Face, error) {
+	img, err := imaging.Load(photo.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load photo: %w", err)
+	}
+	bounds := img.Bounds()
+	photo.Width, photo.Height = bounds.Dx(), bounds.Dy()
+	photo.Detector = c.detector.Name()
+	photo.Orientation = imaging.ReadOrientation(photo.FilePath)
+
+	prepared := c.preprocess(img, photo.Orientation, msg.Params)
+	detect := func(ctx context.Context, img image.Image) ([]detector.Detection, error) {
+		if msg.Params.Tiled {
+			return detector.DetectTiled(ctx, c.detector, img, msg.Params)
+		}
+		return c.detector.Detect(ctx, img, msg.Params)
+	}
+	detections, rotation, err := detector.DetectRotated(ctx, detect, prepared.img, msg.Params)
+	if err != nil {
+		return nil, err
+	}
+	photo.Rotation = rotation
+	for i := range detections {
+		detections[i].Rect = prepared.toOriginal(detections[i].Rect)
+	}
+
+	faces := make([]domain.Face, 0, len(detections))
+	var regions []domain.Region
+	for _, d := range detections {
+		face := newFace(d, bounds)
+		if !domain.IsFaceModel(d.Model) {
+			regions = append(regions, domain.Region{
+				Box:        face.Box,
+				Normalized: face.Normalized,
+				Confidence: face.Confidence,
+				Model:      face.Model,
+			})
+			continue
+		}
+		faces = append(faces, face)
+	}
+	c.locateLandmarks(photo, img, prepared, faces)
+	faces = filterPose(faces, msg.Params.MaxYaw)
+	c.analyzeExpressions(ctx, photo, img, prepared, faces)
+	c.measureQuality(photo, img, prepared, faces)
+	if msg.Params.Profile == domain.ProfileCompliance {
+		photo.Compliance = c.checkCompliance(img, prepared, faces)
+	}
+
+	if msg.Redaction.Enabled() {
+		// Faces dropped by the pose filter are still redacted.
+		if err := c.redact(photo, img, prepared, detections, msg.Redaction); err != nil {
+			return nil, err
+		}
+	} else {
+		annotated := imaging.ToRGBA(img)
+		for _, r := range regions {
+			box := image.Rect(r.Box.X, r.Box.Y, r.Box.X+r.Box.Width, r.Box.Y+r.Box.Height).Add(bounds.Min)
+			imaging.DrawRect(annotated, box, color.RGBA{R: 255, A: 255}, 2)
+		}
+		for _, f := range faces {
+			box := image.Rect(f.Box.X, f.Box.Y, f.Box.X+f.Box.Width, f.Box.Y+f.Box.Height).Add(bounds.Min)
+			imaging.DrawRect(annotated, box, color.RGBA{B: 255, A: 255}, 2)
+			if f.Landmarks != nil {
+				for _, pt := range []domain.Point{f.Landmarks.LeftEye, f.Landmarks.RightEye, f.Landmarks.NoseTip, f.Landmarks.MouthLeft, f.Landmarks.MouthRight} {
+					imaging.Fill(annotated, image.Rect(pt.X-1, pt.Y-1, pt.X+2, pt.Y+2).Add(bounds.Min), color.RGBA{G: 255, A: 255})
+				}
+			}
+		}
+		if err := imaging.Save(storage.ProcessedPath(photo.FilePath), prepared.upright(annotated)); err != nil {
+			return nil, fmt.Errorf("failed to save processed photo: %w", err)
+		}
+	}
+
+	// Crops and embeddings describe the raw face, so they are never kept for
+	// redacted photos.
+	if !msg.Redaction.Enabled() {
+		if err := c.cropFaces(photo, img, prepared, faces); err != nil {
+			return nil, err
+		}
+		c.embedFaces(photo, img, prepared, faces)
+		recognition.Suggest(c.gallery, faces, c.workerConfig.PersonMatchThreshold)
 	}
+	photo.Regions = regions
+	return faces, nil
 }
 
-func DetectFaces(imagePath string) (int, error) {
-	python3.Py_Initialize()
-	defer python3.Py_Finalize()
+// cropFaces writes a padded thumbnail of every face next to the original and
+// records its path on the face. When a crop cannot be saved the crops
+// already written are removed again.
+func (c *consumer) cropFaces(photo *domain.Photo, img image.Image, prepared *preparedImage, faces []domain.Face) error {
+	bounds := img.Bounds()
+	for i := range faces {
+		box := faces[i].Box
+		padX := int(float64(box.Width) * c.workerConfig.CropPadding)
+		padY := int(float64(box.Height) * c.workerConfig.CropPadding)
+		rect := image.Rect(box.X-padX, box.Y-padY, box.X+box.Width+padX, box.Y+box.Height+padY).Add(bounds.Min)
 
-	code := `
-import sys
-import cv2
+		crop := imaging.Thumbnail(prepared.upright(imaging.Crop(img, rect)), c.workerConfig.CropSize)
+		cropPath := storage.FaceCropPath(photo.FilePath, i)
+		if err := imaging.Save(cropPath, crop); err != nil {
+			for j := range faces[:i] {
+				if err := storage.RemovePhoto(faces[j].CropPath); err != nil {
+					logrus.Errorf("Failed to remove %s: %v", faces[j].CropPath, err)
+				}
+				faces[j].CropPath = ""
+			}
+			return fmt.Errorf("failed to save face crop: %w", err)
+		}
+		faces[i].CropPath = cropPath
+	}
+	return nil
+}
+
+// embedFaces computes the similarity search embedding of every face, turned
+// upright by the photo orientation and the rotation it was found under.
+func (c *consumer) embedFaces(photo *domain.Photo, img image.Image, prepared *preparedImage, faces []domain.Face) {
+	for i := range faces {
+		face, _ := prepared.uprightFace(img, faces[i].Box, photo.Rotation)
+		faces[i].Embedding = c.embedder.Embed(face)
+	}
+}
+
+// locateLandmarks finds the landmarks of every face large enough and
+// estimates its head pose. Roll is relative to the upright photo.
+func (c *consumer) locateLandmarks(photo *domain.Photo, img image.Image, prepared *preparedImage, faces []domain.Face) {
+	for i := range faces {
+		if faces[i].Box.Width < landmark.MinFaceSize || faces[i].Box.Height < landmark.MinFaceSize {
+			continue
+		}
+		face, toOriginal := prepared.uprightFace(img, faces[i].Box, photo.Rotation)
+		found := landmark.Locate(face)
+		pose := landmark.Pose(found)
+		pose.Roll = math.Remainder(pose.Roll-photo.Rotation, 360)
+
+		faces[i].Landmarks = &domain.Landmarks{
+			LeftEye:    toOriginal(found.LeftEye),
+			RightEye:   toOriginal(found.RightEye),
+			NoseTip:    toOriginal(found.NoseTip),
+			MouthLeft:  toOriginal(found.MouthLeft),
+			MouthRight: toOriginal(found.MouthRight),
+		}
+		faces[i].Pose = &pose
+	}
+}
 
-def detect_faces(image_path):
-    img = cv2.imread(image_path)
-    face_cascade = cv2.CascadeClassifier(cv2.data.haarcascades + 'haarcascade_frontalface_default.xml')
-    gray = cv2.cvtColor(img, cv2.COLOR_BGR2GRAY)
-    faces = face_cascade.detectMultiScale(gray, 1.1, 4)
-    
-    for (x, y, w, h) in faces:
-        cv2.rectangle(img, (x, y), (x+w, y+h), (255, 0, 0), 2)
-    
-    result_path = image_path.replace("/app/photos/", "/app/photos/processed_")
-    cv2.imwrite(result_path, img)
-    return len(faces)
-`
+// analyzeExpressions estimates whether the eyes of every face large enough
+// are open and whether it smiles. A cue that fails leaves the face without
+// cues rather than failing the job.
+func (c *consumer) analyzeExpressions(ctx context.Context, photo *domain.Photo, img image.Image, prepared *preparedImage, faces []domain.Face) {
+	unavailable := fmt.Sprintf("the %s detector backend cannot run the eye and smile cascades", c.detector.Name())
+	if !expression.Supported(c.detector) {
+		photo.ExpressionsUnavailable = unavailable
+		return
+	}
+	for i := range faces {
+		if faces[i].Box.Width < landmark.MinFaceSize || faces[i].Box.Height < landmark.MinFaceSize {
+			continue
+		}
+		face, _ := prepared.uprightFace(img, faces[i].Box, photo.Rotation)
+		cues, err := expression.Analyze(ctx, c.detector, face)
+		if errors.Is(err, detector.ErrUnsupportedModel) {
+			photo.ExpressionsUnavailable = unavailable
+			return
+		}
+		if err != nil {
+			logrus.Errorf("Failed to analyze expression of face %d: %v", i, err)
+			continue
+		}
+		faces[i].EyesOpen = &cues.EyesOpen
+		faces[i].EyesOpenConfidence = cues.EyesOpenConfidence
+		faces[i].Smiling = &cues.Smiling
+		faces[i].SmilingConfidence = cues.SmilingConfidence
+	}
+}
 
-	pythonModule := python3.PyImport_AddModule("__main__")
+// checkCompliance runs the compliance rules on the photo as displayed.
+func (c *consumer) checkCompliance(img image.Image, prepared *preparedImage, faces []domain.Face) *domain.ComplianceReport {
+	upright := make([]compliance.Face, 0, len(faces))
+	for _, f := range faces {
+		upright = append(upright, compliance.Face{Box: prepared.uprightRect(f.Box), Pose: f.Pose})
+	}
+	return compliance.Check(c.compliance, prepared.upright(img), upright)
+}
 
-	python3.PyRun_SimpleString(code)
+// measureQuality records the quality metrics of every face. Faces touching
+// the photo border are hinted as cut off.
+func (c *consumer) measureQuality(photo *domain.Photo, img image.Image, prepared *preparedImage, faces []domain.Face) {
+	bounds := img.Bounds()
+	for i := range faces {
+		box := faces[i].Box
+		rect := image.Rect(box.X, box.Y, box.X+box.Width, box.Y+box.Height).Add(bounds.Min)
+		upright, _ := prepared.uprightFace(img, box, photo.Rotation)
+		faces[i].Quality = quality.Measure(imaging.Crop(img, rect), upright)
+		if box.X == 0 || box.Y == 0 || box.X+box.Width == bounds.Dx() || box.Y+box.Height == bounds.Dy() {
+			faces[i].Quality.Occlusion = append(faces[i].Quality.Occlusion, domain.OcclusionEdge)
+		}
+	}
+}
 
-	detectFaces := pythonModule.GetAttrString("detect_faces")
-	if detectFaces == nil {
-		return 0, fmt.Errorf("Failed to load function detect_faces")
+// filterPose drops the faces whose estimated yaw exceeds maxYaw degrees.
+// Faces without a pose and a zero maxYaw keep every face.
+func filterPose(faces []domain.Face, maxYaw float64) []domain.Face {
+	if maxYaw == 0 {
+		return faces
 	}
+	kept := faces[:0]
+	for _, f := range faces {
+		if f.Pose == nil || math.Abs(f.Pose.Yaw) <= maxYaw {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
 
-	args := python3.PyTuple_New(1)
-	python3.PyTuple_SetItem(args, 0, python3.PyUnicode_FromString(imagePath))
+// redact writes the redacted variant of the photo and, if requested, removes
+// the original so that no unredacted copy stays at rest.
+func (c *consumer) redact(photo *domain.Photo, img image.Image, prepared *preparedImage, detections []detector.Detection, opts domain.RedactionOptions) error {
+	redacted := imaging.ToRGBA(img)
+	for _, d := range detections {
+		size := d.Rect.Dx()
+		if d.Rect.Dy() > size {
+			size = d.Rect.Dy()
+		}
+		switch opts.Method {
+		case domain.RedactBlur:
+			imaging.Blur(redacted, d.Rect, size/6+1)
+		case domain.RedactPixelate:
+			imaging.Pixelate(redacted, d.Rect, size/8+1)
+		default:
+			imaging.Fill(redacted, d.Rect, color.Black)
+		}
+	}
 
-	result := detectFaces.CallObject(args)
-	if result == nil {
-		return 0, fmt.Errorf("Failed to call function detect_faces")
+	redactedPath := storage.RedactedPath(photo.FilePath)
+	if err := imaging.Save(redactedPath, prepared.upright(redacted)); err != nil {
+		return fmt.Errorf("failed to save redacted photo: %w", err)
 	}
+	photo.RedactedPath = redactedPath
 
-	facesDetected := python3.PyLong_AsLong(result)
-	return facesDetected, nil
+	if opts.DiscardOriginal {
+		if err := storage.RemovePhoto(photo.FilePath); err != nil {
+			return fmt.Errorf("failed to discard original photo: %w", err)
+		}
+		photo.FilePath = ""
+	}
+	return nil
 }
 
-func (c *consumer) getFilePath(ctx context.Context, photoID int64) (string, error) {
-	photo, err := c.repo.FindByID(ctx, strconv.FormatInt(photoID, 10))
-	if err != nil {
-		return "", err
+// discardUnredacted removes the original of a failed job that asked for it
+// to be discarded, so that no unredacted copy stays at rest when redaction
+// did not complete.
+func discardUnredacted(photo *domain.Photo, opts domain.RedactionOptions) {
+	if !opts.DiscardOriginal || photo.FilePath == "" {
+		return
 	}
+	if err := storage.RemovePhoto(photo.FilePath); err != nil {
+		logrus.Errorf("Failed to discard original of failed photo %s: %v", photo.ID, err)
+		return
+	}
+	photo.FilePath = ""
+}
 
-	return photo.FilePath, nil
+// newFace converts a detection into a face with pixel coordinates relative to
+// the photo origin and coordinates normalized to the photo size.
+func newFace(d detector.Detection, bounds image.Rectangle) domain.Face {
+	rect := d.Rect.Sub(bounds.Min)
+	w, h := float64(bounds.Dx()), float64(bounds.Dy())
+	return domain.Face{
+		Box: domain.Box{
+			X:      rect.Min.X,
+			Y:      rect.Min.Y,
+			Width:  rect.Dx(),
+			Height: rect.Dy(),
+		},
+		Normalized: domain.NormalizedBox{
+			X:      float64(rect.Min.X) / w,
+			Y:      float64(rect.Min.Y) / h,
+			Width:  float64(rect.Dx()) / w,
+			Height: float64(rect.Dy()) / h,
+		},
+		Confidence: d.Score,
+		Model:      d.Model,
+	}
 }
@@ -0,0 +1,492 @@
+package queue
+
+import (
+	"context"
+	"image"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/anggi-susanto/go-face-detection-be/config"
+	"github.com/anggi-susanto/go-face-detection-be/domain"
+	"github.com/anggi-susanto/go-face-detection-be/internal/detector"
+	"github.com/anggi-susanto/go-face-detection-be/internal/embedding"
+	"github.com/anggi-susanto/go-face-detection-be/internal/imaging"
+	"github.com/anggi-susanto/go-face-detection-be/internal/landmark"
+	"github.com/anggi-susanto/go-face-detection-be/internal/recognition"
+	"github.com/anggi-susanto/go-face-detection-be/internal/storage"
+)
+
+// fakeDetector returns the same detections for every image.
+type fakeDetector struct {
+	detections []detector.Detection
+	err        error
+}
+
+func (d *fakeDetector) Detect(ctx context.Context, img image.Image, params domain.DetectionParams) ([]detector.Detection, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	return append([]detector.Detection(nil), d.detections...), nil
+}
+
+func (d *fakeDetector) Name() string { return "fake" }
+
+func (d *fakeDetector) Supports(model string) bool { return domain.IsFaceModel(model) }
+
+func (d *fakeDetector) Close() error { return nil }
+
+// newTestConsumer returns a consumer running the fake detector without
+// preprocessing.
+func newTestConsumer(d detector.Detector) *consumer {
+	return &consumer{
+		workerConfig: &config.WorkerConfig{CropPadding: 0.2, CropSize: 32},
+		detector:     d,
+		embedder:     embedding.NewLBPEmbedder(),
+		gallery:      recognition.NewGallery(),
+	}
+}
+
+// savePhoto stores a w x h test photo in a temporary directory and returns
+// its path.
+func savePhoto(t *testing.T, name string, w, h int) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := imaging.Save(path, testPhoto(w, h)); err != nil {
+		t.Fatalf("imaging.Save() error = %v", err)
+	}
+	return path
+}
+
+func TestNewFace(t *testing.T) {
+	tests := []struct {
+		name   string
+		rect   image.Rectangle
+		bounds image.Rectangle
+		want   domain.Face
+	}{
+		{
+			name:   "inside the photo",
+			rect:   image.Rect(20, 10, 60, 50),
+			bounds: image.Rect(0, 0, 200, 100),
+			want: domain.Face{
+				Box:        domain.Box{X: 20, Y: 10, Width: 40, Height: 40},
+				Normalized: domain.NormalizedBox{X: 0.1, Y: 0.1, Width: 0.2, Height: 0.4},
+			},
+		},
+		{
+			name:   "offset bounds",
+			rect:   image.Rect(30, 60, 70, 100),
+			bounds: image.Rect(10, 50, 210, 150),
+			want: domain.Face{
+				Box:        domain.Box{X: 20, Y: 10, Width: 40, Height: 40},
+				Normalized: domain.NormalizedBox{X: 0.1, Y: 0.1, Width: 0.2, Height: 0.4},
+			},
+		},
+		{
+			name:   "whole photo",
+			rect:   image.Rect(0, 0, 200, 100),
+			bounds: image.Rect(0, 0, 200, 100),
+			want: domain.Face{
+				Box:        domain.Box{Width: 200, Height: 100},
+				Normalized: domain.NormalizedBox{Width: 1, Height: 1},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newFace(detector.Detection{Rect: tt.rect, Score: 12.5, Model: domain.ModelFrontalDefault}, tt.bounds)
+			if got.Box != tt.want.Box {
+				t.Errorf("newFace() box = %+v, want %+v", got.Box, tt.want.Box)
+			}
+			n, want := got.Normalized, tt.want.Normalized
+			if math.Abs(n.X-want.X) > 1e-9 || math.Abs(n.Y-want.Y) > 1e-9 || math.Abs(n.Width-want.Width) > 1e-9 || math.Abs(n.Height-want.Height) > 1e-9 {
+				t.Errorf("newFace() normalized box = %+v, want %+v", n, want)
+			}
+			if got.Confidence != 12.5 || got.Model != domain.ModelFrontalDefault {
+				t.Errorf("newFace() confidence = %v, model = %q, want 12.5, %q", got.Confidence, got.Model, domain.ModelFrontalDefault)
+			}
+		})
+	}
+}
+
+func TestDetectFacesRecordsFaces(t *testing.T) {
+	tests := []struct {
+		name       string
+		detections []detector.Detection
+		want       []domain.Box
+	}{
+		{name: "no faces", detections: nil, want: []domain.Box{}},
+		{
+			name: "every face with its box and confidence",
+			detections: []detector.Detection{
+				{Rect: image.Rect(10, 10, 40, 40), Score: 9, Model: domain.ModelFrontalDefault},
+				{Rect: image.Rect(100, 20, 140, 60), Score: 7, Model: domain.ModelFrontalDefault},
+			},
+			want: []domain.Box{{X: 10, Y: 10, Width: 30, Height: 30}, {X: 100, Y: 20, Width: 40, Height: 40}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestConsumer(&fakeDetector{detections: tt.detections})
+			photo := &domain.Photo{ID: domain.NewPhotoID(), FilePath: savePhoto(t, "photo.png", 160, 80)}
+
+			faces, err := c.detectFaces(context.Background(), photo, Message{PhotoID: photo.ID, Params: domain.DefaultDetectionParams()})
+			if err != nil {
+				t.Fatalf("detectFaces() error = %v", err)
+			}
+			if photo.Width != 160 || photo.Height != 80 {
+				t.Errorf("photo size = %dx%d, want 160x80", photo.Width, photo.Height)
+			}
+			if len(faces) != len(tt.want) {
+				t.Fatalf("detectFaces() returned %d faces, want %d", len(faces), len(tt.want))
+			}
+			for i, f := range faces {
+				if f.Box != tt.want[i] {
+					t.Errorf("face %d box = %+v, want %+v", i, f.Box, tt.want[i])
+				}
+				if f.Confidence != tt.detections[i].Score {
+					t.Errorf("face %d confidence = %v, want %v", i, f.Confidence, tt.detections[i].Score)
+				}
+			}
+		})
+	}
+}
+
+// exists reports whether a file is stored at path.
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func TestDetectFacesRedaction(t *testing.T) {
+	detections := []detector.Detection{{Rect: image.Rect(10, 10, 50, 50), Score: 9, Model: domain.ModelFrontalDefault}}
+	tests := []struct {
+		name         string
+		redaction    domain.RedactionOptions
+		wantOriginal bool
+	}{
+		{name: "blur", redaction: domain.RedactionOptions{Method: domain.RedactBlur}, wantOriginal: true},
+		{name: "pixelate", redaction: domain.RedactionOptions{Method: domain.RedactPixelate}, wantOriginal: true},
+		{name: "box discarding the original", redaction: domain.RedactionOptions{Method: domain.RedactBox, DiscardOriginal: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestConsumer(&fakeDetector{detections: detections})
+			original := savePhoto(t, "photo.png", 160, 80)
+			photo := &domain.Photo{ID: domain.NewPhotoID(), FilePath: original}
+
+			faces, err := c.detectFaces(context.Background(), photo, Message{PhotoID: photo.ID, Params: domain.DefaultDetectionParams(), Redaction: tt.redaction})
+			if err != nil {
+				t.Fatalf("detectFaces() error = %v", err)
+			}
+			if len(faces) != 1 {
+				t.Fatalf("detectFaces() returned %d faces, want 1", len(faces))
+			}
+			if photo.RedactedPath != storage.RedactedPath(original) || !exists(photo.RedactedPath) {
+				t.Errorf("redacted path = %q, want the stored file %q", photo.RedactedPath, storage.RedactedPath(original))
+			}
+			if exists(storage.ProcessedPath(original)) {
+				t.Error("an annotated copy was written for a redacted photo")
+			}
+			if faces[0].CropPath != "" || exists(storage.FaceCropPath(original, 0)) {
+				t.Error("a face crop was written for a redacted photo")
+			}
+			if faces[0].Embedding != nil {
+				t.Error("an embedding was computed for a redacted photo")
+			}
+			if got := exists(original); got != tt.wantOriginal {
+				t.Errorf("original stored = %v, want %v", got, tt.wantOriginal)
+			}
+			if tt.wantOriginal != (photo.FilePath != "") {
+				t.Errorf("photo file path = %q after redaction", photo.FilePath)
+			}
+
+			// The face itself must no longer be recognisable.
+			redacted, err := imaging.Load(photo.RedactedPath)
+			if err != nil {
+				t.Fatalf("imaging.Load() error = %v", err)
+			}
+			before, changed := testPhoto(160, 80), 0
+			for y := 10; y < 50; y++ {
+				for x := 10; x < 50; x++ {
+					if before.At(x, y) != redacted.At(x, y) {
+						changed++
+					}
+				}
+			}
+			if changed == 0 {
+				t.Error("no pixel inside the face changed")
+			}
+		})
+	}
+}
+
+func TestDiscardUnredacted(t *testing.T) {
+	tests := []struct {
+		name         string
+		redaction    domain.RedactionOptions
+		wantOriginal bool
+	}{
+		{name: "no redaction", wantOriginal: true},
+		{name: "redaction keeping the original", redaction: domain.RedactionOptions{Method: domain.RedactBlur}, wantOriginal: true},
+		{name: "redaction discarding the original", redaction: domain.RedactionOptions{Method: domain.RedactBlur, DiscardOriginal: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := savePhoto(t, "photo.png", 16, 16)
+			photo := &domain.Photo{ID: domain.NewPhotoID(), FilePath: original}
+			discardUnredacted(photo, tt.redaction)
+			if got := exists(original); got != tt.wantOriginal {
+				t.Errorf("original stored = %v, want %v", got, tt.wantOriginal)
+			}
+			if tt.wantOriginal != (photo.FilePath != "") {
+				t.Errorf("photo file path = %q", photo.FilePath)
+			}
+		})
+	}
+}
+
+func TestDetectFacesFailureDiscardsOutputs(t *testing.T) {
+	detections := []detector.Detection{
+		{Rect: image.Rect(10, 10, 40, 40), Score: 9, Model: domain.ModelFrontalDefault},
+		{Rect: image.Rect(100, 20, 140, 60), Score: 7, Model: domain.ModelFrontalDefault},
+	}
+	tests := []struct {
+		name         string
+		redaction    domain.RedactionOptions
+		blocked      func(original string) string
+		wantOriginal bool
+	}{
+		{name: "first crop fails", blocked: func(p string) string { return storage.FaceCropPath(p, 0) }, wantOriginal: true},
+		{name: "second crop fails", blocked: func(p string) string { return storage.FaceCropPath(p, 1) }, wantOriginal: true},
+		{name: "annotated copy fails", blocked: storage.ProcessedPath, wantOriginal: true},
+		{name: "redacted copy fails", redaction: domain.RedactionOptions{Method: domain.RedactBox, DiscardOriginal: true},
+			blocked: storage.RedactedPath},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestConsumer(&fakeDetector{detections: detections})
+			original := savePhoto(t, "photo.png", 160, 80)
+			photo := &domain.Photo{ID: domain.NewPhotoID(), FilePath: original}
+
+			// A directory in place of an output makes saving it fail.
+			blocked := tt.blocked(original)
+			if err := os.Mkdir(blocked, 0o755); err != nil {
+				t.Fatalf("os.Mkdir() error = %v", err)
+			}
+
+			_, err := c.detectFaces(context.Background(), photo, Message{PhotoID: photo.ID, Params: domain.DefaultDetectionParams(), Redaction: tt.redaction})
+			if err == nil {
+				t.Fatal("detectFaces() error = nil, want the crop failure")
+			}
+			for i := range detections {
+				if path := storage.FaceCropPath(original, i); path != blocked && exists(path) {
+					t.Errorf("face crop %d left behind after the failure", i)
+				}
+			}
+
+			discardFailed(photo, tt.redaction)
+			for _, path := range []string{storage.ProcessedPath(original), storage.RedactedPath(original)} {
+				if path != blocked && exists(path) {
+					t.Errorf("%s left behind after the failure", filepath.Base(path))
+				}
+			}
+			if got := exists(original); got != tt.wantOriginal {
+				t.Errorf("original stored = %v, want %v", got, tt.wantOriginal)
+			}
+		})
+	}
+}
+
+func TestDiscardFailed(t *testing.T) {
+	tests := []struct {
+		name         string
+		redaction    domain.RedactionOptions
+		wantOriginal bool
+	}{
+		{name: "annotated job", wantOriginal: true},
+		{name: "redacted job keeping the original", redaction: domain.RedactionOptions{Method: domain.RedactBlur}, wantOriginal: true},
+		{name: "redacted job discarding the original", redaction: domain.RedactionOptions{Method: domain.RedactBlur, DiscardOriginal: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := savePhoto(t, "photo.png", 16, 16)
+			outputs := []string{storage.ProcessedPath(original), storage.RedactedPath(original), storage.FaceCropPath(original, 0)}
+			for _, path := range outputs {
+				if err := os.WriteFile(path, []byte("output"), 0o644); err != nil {
+					t.Fatalf("os.WriteFile() error = %v", err)
+				}
+			}
+			photo := &domain.Photo{
+				ID:           domain.NewPhotoID(),
+				FilePath:     original,
+				RedactedPath: outputs[1],
+				Faces:        []domain.Face{{CropPath: outputs[2]}},
+			}
+
+			discardFailed(photo, tt.redaction)
+			for _, path := range outputs {
+				if exists(path) {
+					t.Errorf("%s left behind", filepath.Base(path))
+				}
+			}
+			if photo.RedactedPath != "" {
+				t.Errorf("redacted path = %q, want empty", photo.RedactedPath)
+			}
+			if got := exists(original); got != tt.wantOriginal {
+				t.Errorf("original stored = %v, want %v", got, tt.wantOriginal)
+			}
+		})
+	}
+}
+
+func TestWorkerCount(t *testing.T) {
+	tests := []struct {
+		name        string
+		concurrency int
+		want        int
+	}{
+		{name: "unset", concurrency: 0, want: 1},
+		{name: "negative", concurrency: -3, want: 1},
+		{name: "single", concurrency: 1, want: 1},
+		{name: "parallel", concurrency: 8, want: 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := workerCount(&config.WorkerConfig{Concurrency: tt.concurrency}); got != tt.want {
+				t.Errorf("workerCount(%d) = %d, want %d", tt.concurrency, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectFacesConcurrent(t *testing.T) {
+	tests := []struct {
+		name    string
+		workers int
+	}{
+		{name: "two workers", workers: 2},
+		{name: "eight workers", workers: 8},
+	}
+	detections := []detector.Detection{{Rect: image.Rect(10, 10, 40, 40), Score: 9, Model: domain.ModelFrontalDefault}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Workers share the consumer, its detector and its gallery.
+			c := newTestConsumer(&fakeDetector{detections: detections})
+			photos := make([]*domain.Photo, tt.workers)
+			for i := range photos {
+				photos[i] = &domain.Photo{ID: domain.NewPhotoID(), FilePath: savePhoto(t, "photo.png", 80, 60)}
+			}
+
+			var wg sync.WaitGroup
+			errs := make([]error, len(photos))
+			faces := make([][]domain.Face, len(photos))
+			for i, photo := range photos {
+				wg.Add(1)
+				go func(i int, photo *domain.Photo) {
+					defer wg.Done()
+					faces[i], errs[i] = c.detectFaces(context.Background(), photo, Message{PhotoID: photo.ID, Params: domain.DefaultDetectionParams()})
+				}(i, photo)
+			}
+			wg.Wait()
+
+			for i, photo := range photos {
+				if errs[i] != nil {
+					t.Fatalf("detectFaces() of photo %d error = %v", i, errs[i])
+				}
+				if len(faces[i]) != 1 || faces[i][0].CropPath != storage.FaceCropPath(photo.FilePath, 0) {
+					t.Errorf("photo %d faces = %+v, want one face cropped next to it", i, faces[i])
+				}
+				if !exists(storage.ProcessedPath(photo.FilePath)) {
+					t.Errorf("photo %d has no annotated copy", i)
+				}
+			}
+		})
+	}
+}
+
+func TestDetectFacesSuggestsPeople(t *testing.T) {
+	detections := []detector.Detection{{Rect: image.Rect(10, 10, 50, 50), Score: 9, Model: domain.ModelFrontalDefault}}
+	tests := []struct {
+		name      string
+		enroll    bool
+		threshold float64
+		want      bool
+	}{
+		{name: "nobody enrolled", threshold: 0.5},
+		{name: "look-alike", enroll: true, threshold: 0.93, want: true},
+		{name: "threshold not reached", enroll: true, threshold: 1.01},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestConsumer(&fakeDetector{detections: detections})
+			c.workerConfig.PersonMatchThreshold = tt.threshold
+			path := savePhoto(t, "photo.png", 160, 80)
+			msg := Message{Params: domain.DefaultDetectionParams()}
+
+			person := domain.NewPersonID()
+			if tt.enroll {
+				// Enroll the face itself, as found by a first run.
+				faces, err := c.detectFaces(context.Background(), &domain.Photo{FilePath: path}, msg)
+				if err != nil {
+					t.Fatalf("detectFaces() error = %v", err)
+				}
+				c.gallery.Enroll(person, [][]float32{faces[0].Embedding})
+			}
+
+			faces, err := c.detectFaces(context.Background(), &domain.Photo{FilePath: path}, msg)
+			if err != nil {
+				t.Fatalf("detectFaces() error = %v", err)
+			}
+			face := faces[0]
+			if face.PersonID != nil {
+				t.Errorf("detectFaces() assigned the face to %s", face.PersonID)
+			}
+			if (face.PersonSuggestion != nil) != tt.want {
+				t.Fatalf("detectFaces() suggestion = %+v, want one %v", face.PersonSuggestion, tt.want)
+			}
+			if tt.want && (face.PersonSuggestion.PersonID != person || face.PersonSuggestion.Score < 0.999) {
+				t.Errorf("detectFaces() suggestion = %+v, want %s with a score of 1", face.PersonSuggestion, person)
+			}
+		})
+	}
+}
+
+func TestLocateLandmarks(t *testing.T) {
+	tests := []struct {
+		name string
+		box  domain.Box
+		want bool
+	}{
+		{name: "too narrow", box: domain.Box{X: 10, Y: 10, Width: landmark.MinFaceSize - 1, Height: 40}},
+		{name: "too short", box: domain.Box{X: 10, Y: 10, Width: 40, Height: landmark.MinFaceSize - 1}},
+		{name: "smallest located", box: domain.Box{X: 10, Y: 10, Width: landmark.MinFaceSize, Height: landmark.MinFaceSize}, want: true},
+		{name: "large", box: domain.Box{X: 20, Y: 5, Width: 60, Height: 60}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestConsumer(&fakeDetector{})
+			img := testPhoto(120, 80)
+			faces := []domain.Face{{Box: tt.box}}
+
+			c.locateLandmarks(&domain.Photo{}, img, c.preprocess(img, imaging.OrientationNormal, domain.DefaultDetectionParams()), faces)
+
+			got := faces[0]
+			if (got.Landmarks != nil) != tt.want || (got.Pose != nil) != tt.want {
+				t.Fatalf("locateLandmarks() landmarks = %+v, pose = %+v, want located %v", got.Landmarks, got.Pose, tt.want)
+			}
+			if !tt.want {
+				return
+			}
+			box := image.Rect(tt.box.X, tt.box.Y, tt.box.X+tt.box.Width, tt.box.Y+tt.box.Height)
+			for _, p := range []domain.Point{got.Landmarks.LeftEye, got.Landmarks.RightEye, got.Landmarks.NoseTip, got.Landmarks.MouthLeft, got.Landmarks.MouthRight} {
+				if !image.Pt(p.X, p.Y).In(box) {
+					t.Errorf("landmark %+v outside the face box %v", p, box)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,10 @@
+package queue
+
+import "github.com/anggi-susanto/go-face-detection-be/domain"
+
+// Message is the body of a face detection job published to the queue.
+type Message struct {
+	PhotoID   domain.PhotoID          `json:"photo_id"`
+	Params    domain.DetectionParams  `json:"params"`
+	Redaction domain.RedactionOptions `json:"redaction"`
+}
@@ -0,0 +1,124 @@
+package queue
+
+import (
+	"fmt"
+	"image"
+	"math"
+
+	"github.com/anggi-susanto/go-face-detection-be/domain"
+	"github.com/anggi-susanto/go-face-detection-be/internal/imaging"
+)
+
+// Contrast enhancements applied before detection.
+const (
+	EqualizeHistogram = "hist"
+	EqualizeCLAHE     = "clahe"
+)
+
+// ValidateEqualize checks the contrast enhancement configured for the
+// worker.
+//
+// Parameters:
+// - method: The configured enhancement, empty to disable it.
+//
+// Returns:
+// - error: An error object if the enhancement is unknown, otherwise nil.
+func ValidateEqualize(method string) error {
+	switch method {
+	case "", EqualizeHistogram, EqualizeCLAHE:
+		return nil
+	default:
+		return fmt.Errorf("unknown contrast enhancement %q, want %q or %q", method, EqualizeHistogram, EqualizeCLAHE)
+	}
+}
+
+const (
+	claheTiles     = 8
+	claheClipLimit = 2.0
+)
+
+// preparedImage is the image handed to the detector together with what is
+// needed to map its detections back to the original pixel space.
+type preparedImage struct {
+	img         image.Image
+	orientation int
+	scale       float64
+	// orientedW and orientedH are the upright size before downscaling.
+	orientedW, orientedH int
+	original             image.Rectangle
+}
+
+// preprocess applies the configured EXIF orientation correction, downscale
+// and contrast enhancement to the photo before detection. Tiled detection
+// works on the full resolution image and is never downscaled.
+func (c *consumer) preprocess(img image.Image, orientation int, params domain.DetectionParams) *preparedImage {
+	p := &preparedImage{
+		img:         img,
+		orientation: imaging.OrientationNormal,
+		scale:       1,
+		original:    img.Bounds(),
+	}
+
+	if c.workerConfig.AutoOrient && orientation != imaging.OrientationNormal {
+		p.img = imaging.Orient(p.img, orientation)
+		p.orientation = orientation
+	}
+	p.orientedW, p.orientedH = p.img.Bounds().Dx(), p.img.Bounds().Dy()
+
+	if !params.Tiled {
+		p.img, p.scale = imaging.Downscale(p.img, c.workerConfig.MaxDimension)
+	}
+
+	switch c.workerConfig.Equalize {
+	case EqualizeHistogram:
+		p.img = imaging.EqualizeHistogram(imaging.Grayscale(p.img))
+	case EqualizeCLAHE:
+		p.img = imaging.CLAHE(imaging.Grayscale(p.img), claheTiles, claheClipLimit)
+	}
+	return p
+}
+
+// toOriginal maps a rectangle detected on the prepared image back to the
+// pixel space of the original photo.
+func (p *preparedImage) toOriginal(r image.Rectangle) image.Rectangle {
+	r = r.Sub(p.img.Bounds().Min)
+	r = image.Rect(
+		int(math.Floor(float64(r.Min.X)*p.scale)),
+		int(math.Floor(float64(r.Min.Y)*p.scale)),
+		int(math.Ceil(float64(r.Max.X)*p.scale)),
+		int(math.Ceil(float64(r.Max.Y)*p.scale)),
+	)
+	r = imaging.OrientRect(r, imaging.InverseOrientation(p.orientation), p.orientedW, p.orientedH)
+	return r.Add(p.original.Min).Intersect(p.original)
+}
+
+// upright returns an output image in the orientation the photo is displayed
+// with, so that annotated, redacted and cropped outputs do not depend on
+// EXIF support of the viewer.
+func (p *preparedImage) upright(img image.Image) image.Image {
+	return imaging.Orient(img, p.orientation)
+}
+
+// uprightRect maps a box, given in the original pixel space, to the photo as
+// displayed upright.
+func (p *preparedImage) uprightRect(box domain.Box) image.Rectangle {
+	r := image.Rect(box.X, box.Y, box.X+box.Width, box.Y+box.Height)
+	return imaging.OrientRect(r, p.orientation, p.original.Dx(), p.original.Dy())
+}
+
+// uprightFace returns the face inside the box, given in the original pixel
+// space, turned upright by the photo orientation and by the rotation the
+// faces were found under. The returned function maps a pixel of the face
+// image back to the original pixel space.
+func (p *preparedImage) uprightFace(img image.Image, box domain.Box, rotation float64) (image.Image, func(domain.Point) domain.Point) {
+	rect := image.Rect(box.X, box.Y, box.X+box.Width, box.Y+box.Height).Add(p.original.Min)
+	upright := p.upright(imaging.Crop(img, rect))
+	uw, uh := upright.Bounds().Dx(), upright.Bounds().Dy()
+
+	toOriginal := func(pt domain.Point) domain.Point {
+		q := imaging.UnrotatePoint(image.Pt(pt.X, pt.Y), rotation, uw, uh)
+		q = imaging.OrientPoint(q, imaging.InverseOrientation(p.orientation), uw, uh)
+		return domain.Point{X: box.X + q.X, Y: box.Y + q.Y}
+	}
+	return imaging.Rotate(upright, rotation), toOriginal
+}
@@ -0,0 +1,136 @@
+package queue
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/anggi-susanto/go-face-detection-be/config"
+	"github.com/anggi-susanto/go-face-detection-be/domain"
+	"github.com/anggi-susanto/go-face-detection-be/internal/imaging"
+)
+
+var testOrientations = []int{
+	imaging.OrientationNormal,
+	imaging.OrientationFlipH,
+	imaging.OrientationRotate180,
+	imaging.OrientationFlipV,
+	imaging.OrientationTranspose,
+	imaging.OrientationRotate90,
+	imaging.OrientationTransverse,
+	imaging.OrientationRotate270,
+}
+
+// testPhoto returns a w x h image whose every pixel has a distinct color.
+func testPhoto(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 0x80, A: 0xFF})
+		}
+	}
+	return img
+}
+
+func TestPreparedImageToOriginal(t *testing.T) {
+	img := testPhoto(80, 60)
+	box := domain.Box{X: 8, Y: 4, Width: 16, Height: 12}
+	want := image.Rect(box.X, box.Y, box.X+box.Width, box.Y+box.Height)
+
+	tests := []struct {
+		name         string
+		autoOrient   bool
+		maxDimension int
+		tiled        bool
+		wantScale    float64
+	}{
+		{name: "as stored", autoOrient: false, wantScale: 1},
+		{name: "oriented", autoOrient: true, wantScale: 1},
+		{name: "oriented and downscaled", autoOrient: true, maxDimension: 40, wantScale: 2},
+		{name: "tiled is not downscaled", autoOrient: true, maxDimension: 40, tiled: true, wantScale: 1},
+	}
+	for _, tt := range tests {
+		for _, orientation := range testOrientations {
+			t.Run(fmt.Sprintf("%s/%d", tt.name, orientation), func(t *testing.T) {
+				c := &consumer{workerConfig: &config.WorkerConfig{AutoOrient: tt.autoOrient, MaxDimension: tt.maxDimension}}
+				p := c.preprocess(img, orientation, domain.DetectionParams{Tiled: tt.tiled})
+				if p.scale != tt.wantScale {
+					t.Fatalf("scale = %v, want %v", p.scale, tt.wantScale)
+				}
+
+				// The box as the detector sees it on the prepared image.
+				upright := p.uprightRect(box)
+				detected := image.Rect(
+					int(float64(upright.Min.X)/p.scale), int(float64(upright.Min.Y)/p.scale),
+					int(float64(upright.Max.X)/p.scale), int(float64(upright.Max.Y)/p.scale),
+				)
+				if !detected.In(p.img.Bounds()) {
+					t.Fatalf("detection %v outside the prepared image %v", detected, p.img.Bounds())
+				}
+				if got := p.toOriginal(detected); got != want {
+					t.Errorf("toOriginal(%v) = %v, want %v", detected, got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestPreparedImageUprightFace(t *testing.T) {
+	img := testPhoto(80, 60)
+	box := domain.Box{X: 8, Y: 4, Width: 16, Height: 12}
+
+	tests := []struct {
+		name     string
+		rotation float64
+	}{
+		{name: "no rotation", rotation: 0},
+		{name: "rotated 90", rotation: 90},
+		{name: "rotated 180", rotation: 180},
+		{name: "rotated 270", rotation: 270},
+	}
+	for _, tt := range tests {
+		for _, orientation := range testOrientations {
+			t.Run(fmt.Sprintf("%s/%d", tt.name, orientation), func(t *testing.T) {
+				c := &consumer{workerConfig: &config.WorkerConfig{AutoOrient: true}}
+				p := c.preprocess(img, orientation, domain.DetectionParams{})
+				face, toOriginal := p.uprightFace(img, box, tt.rotation)
+
+				if got := face.Bounds().Dx() * face.Bounds().Dy(); got != box.Width*box.Height {
+					t.Fatalf("face has %d pixels, want %d", got, box.Width*box.Height)
+				}
+				// Every face pixel must map back to the pixel it was taken from.
+				b := face.Bounds()
+				for y := b.Min.Y; y < b.Max.Y; y++ {
+					for x := b.Min.X; x < b.Max.X; x++ {
+						pt := toOriginal(domain.Point{X: x, Y: y})
+						if got, want := face.At(x, y), img.At(pt.X, pt.Y); got != want {
+							t.Fatalf("face pixel (%d, %d) = %v, mapped to (%d, %d) = %v", x, y, got, pt.X, pt.Y, want)
+						}
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestValidateEqualize(t *testing.T) {
+	tests := []struct {
+		method  string
+		wantErr bool
+	}{
+		{method: ""},
+		{method: EqualizeHistogram},
+		{method: EqualizeCLAHE},
+		{method: "CLAHE", wantErr: true},
+		{method: "histogram", wantErr: true},
+		{method: "on", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			if err := ValidateEqualize(tt.method); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateEqualize(%q) error = %v, want error %v", tt.method, err, tt.wantErr)
+			}
+		})
+	}
+}
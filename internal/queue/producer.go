@@ -1,7 +1,7 @@
 package queue
 
 import (
-	"fmt"
+	"encoding/json"
 
 	"github.com/anggi-susanto/go-face-detection-be/config"
 	"github.com/sirupsen/logrus"
@@ -9,7 +9,7 @@ import (
 )
 
 type Producer interface {
-	SendToQueue(photoID int64) error
+	SendToQueue(msg Message) error
 }
 
 type producer struct {
@@ -22,7 +22,12 @@ func NewProducer(config *config.RabbitMqConfig) Producer {
 	}
 }
 
-func (p *producer) SendToQueue(photoID int64) error {
+func (p *producer) SendToQueue(msg Message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
 	conn, err := amqp.Dial(p.config.Uri)
 	if err != nil {
 		logrus.Fatal(err)
@@ -53,8 +58,8 @@ func (p *producer) SendToQueue(photoID int64) error {
 		false,
 		false,
 		amqp.Publishing{
-			ContentType: "text/plain",
-			Body:        []byte(fmt.Sprintf("%d", photoID)),
+			ContentType: "application/json",
+			Body:        body,
 		},
 	)
 	if err != nil {
@@ -0,0 +1,91 @@
+package recognition
+
+import (
+	"sync"
+
+	"github.com/anggi-susanto/go-face-detection-be/domain"
+	"github.com/anggi-susanto/go-face-detection-be/internal/embedding"
+)
+
+// Gallery holds the reference embeddings of every enrolled person and finds
+// the person a face embedding looks most alike.
+type Gallery interface {
+	// Enroll stores the reference embeddings of a person, replacing any
+	// earlier ones.
+	Enroll(personID domain.PersonID, embeddings [][]float32)
+	// Remove drops a person.
+	Remove(personID domain.PersonID)
+	// Match returns the person whose closest reference embedding is most
+	// similar to the vector, with that similarity. ok is false when nobody
+	// is enrolled.
+	Match(vector []float32) (personID domain.PersonID, score float64, ok bool)
+	// Len returns the number of enrolled people.
+	Len() int
+}
+
+type gallery struct {
+	mu     sync.RWMutex
+	people map[domain.PersonID][][]float32
+}
+
+// NewGallery creates an empty in-memory gallery.
+//
+// Returns:
+// - Gallery: The empty gallery.
+func NewGallery() Gallery {
+	return &gallery{people: make(map[domain.PersonID][][]float32)}
+}
+
+func (g *gallery) Enroll(personID domain.PersonID, embeddings [][]float32) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.people[personID] = embeddings
+}
+
+func (g *gallery) Remove(personID domain.PersonID) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.people, personID)
+}
+
+func (g *gallery) Match(vector []float32) (domain.PersonID, float64, bool) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	var bestID domain.PersonID
+	bestScore, ok := 0.0, false
+	for id, references := range g.people {
+		for _, ref := range references {
+			if score := embedding.Cosine(vector, ref); !ok || score > bestScore {
+				bestID, bestScore, ok = id, score, true
+			}
+		}
+	}
+	return bestID, bestScore, ok
+}
+
+func (g *gallery) Len() int {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return len(g.people)
+}
+
+// Suggest attaches the best matching enrolled person as a suggestion to
+// every face with an embedding whose match score reaches the threshold. The
+// score only measures how alike the faces look, so faces are never assigned
+// to a person here.
+//
+// Parameters:
+// - g: The gallery of enrolled people.
+// - faces: The faces to match, updated in place.
+// - threshold: The minimum cosine similarity of a suggestion.
+func Suggest(g Gallery, faces []domain.Face, threshold float64) {
+	for i := range faces {
+		if len(faces[i].Embedding) == 0 {
+			continue
+		}
+		if id, score, ok := g.Match(faces[i].Embedding); ok && score >= threshold {
+			faces[i].PersonSuggestion = &domain.PersonSuggestion{PersonID: id, Score: score}
+		}
+	}
+}
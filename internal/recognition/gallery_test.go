@@ -0,0 +1,108 @@
+package recognition
+
+import (
+	"math"
+	"testing"
+
+	"github.com/anggi-susanto/go-face-detection-be/domain"
+	"github.com/anggi-susanto/go-face-detection-be/internal/embedding"
+)
+
+func TestGallery(t *testing.T) {
+	alice, bob := domain.NewPersonID(), domain.NewPersonID()
+	tests := []struct {
+		name      string
+		build     func(g Gallery)
+		vector    []float32
+		wantID    domain.PersonID
+		wantScore float64
+		wantOK    bool
+		wantLen   int
+	}{
+		{name: "empty", build: func(g Gallery) {}, vector: []float32{1, 0}},
+		{
+			name: "closest reference wins",
+			build: func(g Gallery) {
+				g.Enroll(alice, [][]float32{{0, 1}, {0.6, 0.8}})
+				g.Enroll(bob, [][]float32{{0.8, 0.6}})
+			},
+			vector: []float32{1, 0}, wantID: bob, wantScore: 0.8, wantOK: true, wantLen: 2,
+		},
+		{
+			name: "enroll replaces references",
+			build: func(g Gallery) {
+				g.Enroll(alice, [][]float32{{1, 0}})
+				g.Enroll(alice, [][]float32{{0, 1}})
+				g.Enroll(bob, [][]float32{{0.6, 0.8}})
+			},
+			vector: []float32{1, 0}, wantID: bob, wantScore: 0.6, wantOK: true, wantLen: 2,
+		},
+		{
+			name: "removed person",
+			build: func(g Gallery) {
+				g.Enroll(alice, [][]float32{{1, 0}})
+				g.Enroll(bob, [][]float32{{0, 1}})
+				g.Remove(alice)
+			},
+			vector: []float32{1, 0}, wantID: bob, wantScore: 0, wantOK: true, wantLen: 1,
+		},
+		{
+			name:   "person without references",
+			build:  func(g Gallery) { g.Enroll(alice, nil) },
+			vector: []float32{1, 0}, wantLen: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGallery()
+			tt.build(g)
+			if got := g.Len(); got != tt.wantLen {
+				t.Errorf("Len() = %d, want %d", got, tt.wantLen)
+			}
+			id, score, ok := g.Match(tt.vector)
+			if ok != tt.wantOK {
+				t.Fatalf("Match() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && (id != tt.wantID || math.Abs(score-tt.wantScore) > 1e-6) {
+				t.Errorf("Match() = %s, %v, want %s, %v", id, score, tt.wantID, tt.wantScore)
+			}
+		})
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	alice := domain.NewPersonID()
+	g := NewGallery()
+	g.Enroll(alice, [][]float32{{1, 0}})
+
+	tests := []struct {
+		name      string
+		gallery   Gallery
+		vector    []float32
+		threshold float64
+		wantScore float64
+		want      bool
+	}{
+		{name: "above the threshold", gallery: g, vector: []float32{0.96, 0.28}, threshold: 0.93, wantScore: 0.96, want: true},
+		{name: "at the threshold", gallery: g, vector: []float32{0.6, 0.8}, threshold: embedding.Cosine([]float32{0.6, 0.8}, []float32{1, 0}), wantScore: 0.6, want: true},
+		{name: "below the threshold", gallery: g, vector: []float32{0.8, 0.6}, threshold: 0.93},
+		{name: "no embedding", gallery: g, threshold: 0.5},
+		{name: "nobody enrolled", gallery: NewGallery(), vector: []float32{1, 0}, threshold: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			faces := []domain.Face{{Embedding: tt.vector}}
+			Suggest(tt.gallery, faces, tt.threshold)
+			face := faces[0]
+			if face.PersonID != nil || face.PersonScore != 0 {
+				t.Errorf("Suggest() assigned the face to %v with %v", face.PersonID, face.PersonScore)
+			}
+			if (face.PersonSuggestion != nil) != tt.want {
+				t.Fatalf("Suggest() suggestion = %+v, want one %v", face.PersonSuggestion, tt.want)
+			}
+			if tt.want && (face.PersonSuggestion.PersonID != alice || math.Abs(face.PersonSuggestion.Score-tt.wantScore) > 1e-3) {
+				t.Errorf("Suggest() suggestion = %+v, want %s with %v", face.PersonSuggestion, alice, tt.wantScore)
+			}
+		})
+	}
+}
@@ -0,0 +1,250 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/anggi-susanto/go-face-detection-be/config"
+	"github.com/anggi-susanto/go-face-detection-be/domain"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ClusterRepository struct {
+	client     *mongo.Client
+	collection *mongo.Collection
+}
+
+// NewClusterRepository creates a new instance of the ClusterRepository
+// struct with the provided MongoDB client and configuration.
+//
+// Parameters:
+// - client: A pointer to a mongo.Client object representing the MongoDB client.
+// - config: A pointer to a config.MongoConfig object representing the MongoDB configuration.
+//
+// Returns:
+// - A pointer to a ClusterRepository object representing the newly created repository.
+func NewClusterRepository(client *mongo.Client, config *config.MongoConfig) *ClusterRepository {
+	return &ClusterRepository{
+		client:     client,
+		collection: client.Database(config.Database).Collection(config.ClustersCollection),
+	}
+}
+
+// Create inserts a new cluster document into the MongoDB collection.
+//
+// Parameters:
+// - ctx: The context.Context object for the function.
+// - cluster: A pointer to a domain.Cluster object representing the cluster to be inserted.
+//
+// Returns:
+// - error: An error object if there was an error inserting the cluster, otherwise nil.
+func (r *ClusterRepository) Create(ctx context.Context, cluster *domain.Cluster) error {
+	_, err := r.collection.InsertOne(ctx, cluster)
+	if err != nil {
+		// Log the error and return it
+		logrus.Error(err)
+		return err
+	}
+	return nil
+}
+
+// FindByID finds a cluster document in the MongoDB collection by its ID.
+//
+// Parameters:
+// - ctx: The context.Context object for the function.
+// - id: The ID of the cluster to be found.
+//
+// Returns:
+// - cluster: A pointer to a domain.Cluster object representing the found cluster, or nil if not found.
+// - error: An error object if there was an error finding the cluster, otherwise nil.
+func (r *ClusterRepository) FindByID(ctx context.Context, id domain.ClusterID) (*domain.Cluster, error) {
+	var cluster domain.Cluster
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&cluster)
+	if err != nil {
+		// Log the error and return it
+		logrus.Error(err)
+		return nil, err
+	}
+	return &cluster, nil
+}
+
+// FindAll finds all cluster documents in the MongoDB collection, largest
+// first, without their centroids.
+//
+// Parameters:
+// - ctx: The context.Context object for the function.
+//
+// Returns:
+// - clusters: A slice of domain.Cluster objects representing all the found clusters.
+// - error: An error object if there was an error finding the clusters, otherwise nil.
+func (r *ClusterRepository) FindAll(ctx context.Context) ([]domain.Cluster, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "size", Value: -1}, {Key: "_id", Value: 1}}).
+		SetProjection(bson.M{"centroid": 0})
+	return r.find(ctx, bson.M{}, opts)
+}
+
+// FindLocked finds all clusters edited by a user.
+//
+// Parameters:
+// - ctx: The context.Context object for the function.
+//
+// Returns:
+// - clusters: A slice of domain.Cluster objects representing the locked clusters.
+// - error: An error object if there was an error finding the clusters, otherwise nil.
+func (r *ClusterRepository) FindLocked(ctx context.Context) ([]domain.Cluster, error) {
+	return r.find(ctx, bson.M{"locked": true}, options.Find().SetProjection(bson.M{"centroid": 0}))
+}
+
+// FindCentroids finds the ID, size and centroid of every cluster.
+//
+// Parameters:
+// - ctx: The context.Context object for the function.
+//
+// Returns:
+// - clusters: A slice of domain.Cluster objects without their faces.
+// - error: An error object if there was an error finding the clusters, otherwise nil.
+func (r *ClusterRepository) FindCentroids(ctx context.Context) ([]domain.Cluster, error) {
+	return r.find(ctx, bson.M{}, options.Find().SetProjection(bson.M{"faces": 0}))
+}
+
+func (r *ClusterRepository) find(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]domain.Cluster, error) {
+	clusters := []domain.Cluster{}
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		// Log the error and return it
+		logrus.Error(err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	if err := cursor.All(ctx, &clusters); err != nil {
+		// Log the error and return it
+		logrus.Error(err)
+		return nil, err
+	}
+	return clusters, nil
+}
+
+// Update updates a cluster document in the MongoDB collection.
+//
+// Parameters:
+// - ctx: The context.Context object for the function.
+// - cluster: A pointer to a domain.Cluster object representing the cluster to be updated.
+//
+// Returns:
+// - error: An error object if there was an error updating the cluster, otherwise nil.
+func (r *ClusterRepository) Update(ctx context.Context, cluster *domain.Cluster) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": cluster.ID}, bson.M{"$set": cluster})
+	if err != nil {
+		// Log the error and return it
+		logrus.Error(err)
+		return err
+	}
+	return nil
+}
+
+// AddFace appends a face to a cluster and stores its new centroid.
+//
+// Parameters:
+// - ctx: The context.Context object for the function.
+// - id: The ID of the cluster.
+// - face: The face to add.
+// - centroid: The centroid of the cluster including the face.
+//
+// Returns:
+// - error: An error object if there was an error updating the cluster, otherwise nil.
+func (r *ClusterRepository) AddFace(ctx context.Context, id domain.ClusterID, face domain.FaceRef, centroid []float32) error {
+	update := bson.M{
+		"$push": bson.M{"faces": face},
+		"$inc":  bson.M{"size": 1},
+		"$set":  bson.M{"centroid": centroid, "updated_at": time.Now()},
+	}
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		// Log the error and return it
+		logrus.Error(err)
+		return err
+	}
+	return nil
+}
+
+// RemovePhoto drops every face of a photo from the clusters and deletes the
+// unlocked clusters left empty.
+//
+// Parameters:
+// - ctx: The context.Context object for the function.
+// - photoID: The ID of the photo.
+//
+// Returns:
+// - error: An error object if there was an error updating the clusters, otherwise nil.
+func (r *ClusterRepository) RemovePhoto(ctx context.Context, photoID domain.PhotoID) error {
+	pipeline := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{"faces": bson.M{"$filter": bson.M{
+			"input": "$faces",
+			"cond":  bson.M{"$ne": bson.A{"$$this.photo_id", photoID}},
+		}}}}},
+		{{Key: "$set", Value: bson.M{"size": bson.M{"$size": "$faces"}}}},
+	}
+	if _, err := r.collection.UpdateMany(ctx, bson.M{"faces.photo_id": photoID}, pipeline); err != nil {
+		// Log the error and return it
+		logrus.Error(err)
+		return err
+	}
+	if _, err := r.collection.DeleteMany(ctx, bson.M{"size": 0, "locked": bson.M{"$ne": true}}); err != nil {
+		// Log the error and return it
+		logrus.Error(err)
+		return err
+	}
+	return nil
+}
+
+// Delete deletes cluster documents from the MongoDB collection by their IDs.
+//
+// Parameters:
+// - ctx: The context.Context object for the function.
+// - ids: The IDs of the clusters to be deleted.
+//
+// Returns:
+// - error: An error object if there was an error deleting the clusters, otherwise nil.
+func (r *ClusterRepository) Delete(ctx context.Context, ids ...domain.ClusterID) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		// Log the error and return it
+		logrus.Error(err)
+		return err
+	}
+	return nil
+}
+
+// ReplaceUnlocked replaces every cluster not edited by a user with the given
+// clusters.
+//
+// Parameters:
+// - ctx: The context.Context object for the function.
+// - clusters: The new unlocked clusters.
+//
+// Returns:
+// - error: An error object if there was an error replacing the clusters, otherwise nil.
+func (r *ClusterRepository) ReplaceUnlocked(ctx context.Context, clusters []domain.Cluster) error {
+	if _, err := r.collection.DeleteMany(ctx, bson.M{"locked": bson.M{"$ne": true}}); err != nil {
+		// Log the error and return it
+		logrus.Error(err)
+		return err
+	}
+	if len(clusters) == 0 {
+		return nil
+	}
+	docs := make([]interface{}, len(clusters))
+	for i := range clusters {
+		docs[i] = clusters[i]
+	}
+	if _, err := r.collection.InsertMany(ctx, docs); err != nil {
+		// Log the error and return it
+		logrus.Error(err)
+		return err
+	}
+	return nil
+}
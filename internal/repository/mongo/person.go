@@ -0,0 +1,97 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/anggi-susanto/go-face-detection-be/config"
+	"github.com/anggi-susanto/go-face-detection-be/domain"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type PersonRepository struct {
+	client     *mongo.Client
+	collection *mongo.Collection
+}
+
+// NewPersonRepository creates a new instance of the PersonRepository struct
+// with the provided MongoDB client and configuration.
+//
+// Parameters:
+// - client: A pointer to a mongo.Client object representing the MongoDB client.
+// - config: A pointer to a config.MongoConfig object representing the MongoDB configuration.
+//
+// Returns:
+// - A pointer to a PersonRepository object representing the newly created repository.
+func NewPersonRepository(client *mongo.Client, config *config.MongoConfig) *PersonRepository {
+	return &PersonRepository{
+		client:     client,
+		collection: client.Database(config.Database).Collection(config.PeopleCollection),
+	}
+}
+
+// Create inserts a new person document into the MongoDB collection.
+//
+// Parameters:
+// - ctx: The context.Context object for the function.
+// - person: A pointer to a domain.Person object representing the person to be inserted.
+//
+// Returns:
+// - error: An error object if there was an error inserting the person, otherwise nil.
+func (p *PersonRepository) Create(ctx context.Context, person *domain.Person) error {
+	_, err := p.collection.InsertOne(ctx, person)
+	if err != nil {
+		// Log the error and return it
+		logrus.Error(err)
+		return err
+	}
+	return nil
+}
+
+// FindByID finds a person document in the MongoDB collection by its ID.
+//
+// Parameters:
+// - ctx: The context.Context object for the function.
+// - id: The ID of the person to be found.
+//
+// Returns:
+// - person: A pointer to a domain.Person object representing the found person, or nil if not found.
+// - error: An error object if there was an error finding the person, otherwise nil.
+func (p *PersonRepository) FindByID(ctx context.Context, id domain.PersonID) (*domain.Person, error) {
+	var person domain.Person
+	err := p.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&person)
+	if err != nil {
+		// Log the error and return it
+		logrus.Error(err)
+		return nil, err
+	}
+	return &person, nil
+}
+
+// FindAll finds all person documents in the MongoDB collection, ordered by
+// name.
+//
+// Parameters:
+// - ctx: The context.Context object for the function.
+//
+// Returns:
+// - people: A slice of domain.Person objects representing all the found people.
+// - error: An error object if there was an error finding the people, otherwise nil.
+func (p *PersonRepository) FindAll(ctx context.Context) ([]domain.Person, error) {
+	people := []domain.Person{}
+	cursor, err := p.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"name": 1}))
+	if err != nil {
+		// Log the error and return it
+		logrus.Error(err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	if err := cursor.All(ctx, &people); err != nil {
+		// Log the error and return it
+		logrus.Error(err)
+		return nil, err
+	}
+	return people, nil
+}
@@ -2,12 +2,17 @@ package mongo
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
 
 	"github.com/anggi-susanto/go-face-detection-be/config"
 	"github.com/anggi-susanto/go-face-detection-be/domain"
 	"github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type PhotoRepository struct {
@@ -58,7 +63,7 @@ func (p *PhotoRepository) Create(ctx context.Context, photo *domain.Photo) error
 // Returns:
 // - photo: A pointer to a domain.Photo object representing the found photo, or nil if not found.
 // - error: An error object if there was an error finding the photo, otherwise nil.
-func (p *PhotoRepository) FindByID(ctx context.Context, id string) (*domain.Photo, error) {
+func (p *PhotoRepository) FindByID(ctx context.Context, id domain.PhotoID) (*domain.Photo, error) {
 	var photo domain.Photo
 	err := p.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&photo)
 	if err != nil {
@@ -69,51 +74,310 @@ func (p *PhotoRepository) FindByID(ctx context.Context, id string) (*domain.Phot
 	return &photo, nil
 }
 
-// FindAll finds all photo documents in the MongoDB collection.
+// EnsureIndexes creates the indexes the photo listings and lookups rely
+// on. Existing indexes are left as they are.
 //
 // Parameters:
 // - ctx: The context.Context object for the function.
 //
 // Returns:
-// - photos: A slice of domain.Photo objects representing all the found photos.
+// - error: An error object if there was an error creating the indexes, otherwise nil.
+func (p *PhotoRepository) EnsureIndexes(ctx context.Context) error {
+	models := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "timestamp", Value: -1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "faces_detected", Value: -1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "timestamp", Value: -1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "tags", Value: 1}, {Key: "timestamp", Value: -1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "detector", Value: 1}, {Key: "timestamp", Value: -1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "burst_id", Value: 1}, {Key: "timestamp", Value: 1}}},
+		{Keys: bson.D{{Key: "faces.person_id", Value: 1}}},
+		{Keys: bson.D{{Key: "faces.person_suggestion.person_id", Value: 1}}},
+	}
+	if _, err := p.collection.Indexes().CreateMany(ctx, models); err != nil {
+		// Log the error and return it
+		logrus.Error(err)
+		return err
+	}
+	return nil
+}
+
+// photoCursor is the position of the last photo of a listing page, in the
+// sort order of the listing.
+type photoCursor struct {
+	Sort          string         `json:"s"`
+	Timestamp     time.Time      `json:"t"`
+	FacesDetected int            `json:"f"`
+	ID            domain.PhotoID `json:"id"`
+}
+
+func encodeCursor(c photoCursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s, sort string) (photoCursor, error) {
+	var c photoCursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || json.Unmarshal(data, &c) != nil || c.Sort != sort {
+		return c, domain.ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// List returns a page of the photos matching the request, with at least one
+// face passing the quality filter when it is set. Pages are ordered by the
+// sort field then by ID, and continue after the position of the cursor, so
+// photos added while paging do not shift later pages. Face embeddings are not
+// loaded.
+//
+// Parameters:
+// - ctx: The context.Context object for the function.
+// - req: The validated filters, sort and page of the listing.
+// - quality: The quality filter a face of each photo must pass.
+//
+// Returns:
+// - page: A pointer to a domain.PhotoPage object holding the photos and the cursor of the next page.
+// - error: domain.ErrInvalidCursor for a malformed cursor, an error object if there was an error finding the photos, otherwise nil.
+func (p *PhotoRepository) List(ctx context.Context, req domain.PhotoListRequest, quality domain.QualityFilter) (*domain.PhotoPage, error) {
+	filter, err := listFilter(req, quality)
+	if err != nil {
+		return nil, err
+	}
+
+	direction, cmp := -1, "$lt"
+	if req.Order == domain.OrderAsc {
+		direction, cmp = 1, "$gt"
+	}
+	if req.Cursor != "" {
+		cursor, err := decodeCursor(req.Cursor, req.Sort)
+		if err != nil {
+			return nil, err
+		}
+		var last any = cursor.Timestamp
+		if req.Sort == domain.SortFacesDetected {
+			last = cursor.FacesDetected
+		}
+		filter = append(filter, bson.E{Key: "$or", Value: bson.A{
+			bson.M{req.Sort: bson.M{cmp: last}},
+			bson.M{req.Sort: last, "_id": bson.M{cmp: cursor.ID}},
+		}})
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: req.Sort, Value: direction}, {Key: "_id", Value: direction}}).
+		SetLimit(int64(req.Limit) + 1).
+		SetProjection(bson.M{"faces.embedding": 0})
+	cursor, err := p.collection.Find(ctx, filter, opts)
+	if err != nil {
+		// Log the error and return it
+		logrus.Error(err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	page := &domain.PhotoPage{Photos: []domain.Photo{}}
+	if err := cursor.All(ctx, &page.Photos); err != nil {
+		// Log the error and return it
+		logrus.Error(err)
+		return nil, err
+	}
+	if len(page.Photos) > req.Limit {
+		page.Photos = page.Photos[:req.Limit]
+		last := page.Photos[req.Limit-1]
+		page.NextCursor = encodeCursor(photoCursor{
+			Sort:          req.Sort,
+			Timestamp:     last.TimeStamp,
+			FacesDetected: last.FacesDetected,
+			ID:            last.ID,
+		})
+	}
+	return page, nil
+}
+
+// listFilter returns the query of the filters of a photo listing.
+func listFilter(req domain.PhotoListRequest, quality domain.QualityFilter) (bson.D, error) {
+	filter := bson.D{}
+	if req.Status != "" {
+		filter = append(filter, bson.E{Key: "status", Value: req.Status})
+	}
+	from, to, err := req.TimeRange()
+	if err != nil {
+		return nil, err
+	}
+	timestamp := bson.M{}
+	if !from.IsZero() {
+		timestamp["$gte"] = from
+	}
+	if !to.IsZero() {
+		timestamp["$lt"] = to
+	}
+	if len(timestamp) > 0 {
+		filter = append(filter, bson.E{Key: "timestamp", Value: timestamp})
+	}
+	faces := bson.M{}
+	if req.MinFaces > 0 {
+		faces["$gte"] = req.MinFaces
+	}
+	if req.MaxFaces >= 0 {
+		faces["$lte"] = req.MaxFaces
+	}
+	if len(faces) > 0 {
+		filter = append(filter, bson.E{Key: "faces_detected", Value: faces})
+	}
+	if req.Tag != "" {
+		filter = append(filter, bson.E{Key: "tags", Value: req.Tag})
+	}
+	if req.Detector != "" {
+		filter = append(filter, bson.E{Key: "detector", Value: req.Detector})
+	}
+	if !quality.Empty() {
+		filter = append(filter, bson.E{Key: "faces", Value: bson.M{"$elemMatch": qualityQuery(quality)}})
+	}
+	return filter, nil
+}
+
+// FindFaceEmbeddings finds every photo with at least one face embedding,
+// limited to the given IDs when any are passed. Only the photo ID and the
+// face embeddings and quality metrics are loaded.
+//
+// Parameters:
+// - ctx: The context.Context object for the function.
+// - ids: The IDs of the photos to load, all photos when empty.
+//
+// Returns:
+// - photos: A slice of domain.Photo objects holding only IDs and face embeddings.
 // - error: An error object if there was an error finding the photos, otherwise nil.
-func (p *PhotoRepository) FindAll(ctx context.Context) ([]domain.Photo, error) {
+func (p *PhotoRepository) FindFaceEmbeddings(ctx context.Context, ids ...domain.PhotoID) ([]domain.Photo, error) {
 	var photos []domain.Photo
-	cursor, err := p.collection.Find(ctx, bson.M{})
+	filter := bson.M{"faces.embedding": bson.M{"$exists": true}}
+	if len(ids) > 0 {
+		filter["_id"] = bson.M{"$in": ids}
+	}
+	projection := options.Find().SetProjection(bson.M{"_id": 1, "faces.embedding": 1, "faces.quality": 1})
+	cursor, err := p.collection.Find(ctx, filter, projection)
 	if err != nil {
 		// Log the error and return it
 		logrus.Error(err)
 		return nil, err
 	}
 	defer cursor.Close(ctx)
-	for cursor.Next(ctx) {
-		var photo domain.Photo
-		err := cursor.Decode(&photo)
-		if err != nil {
-			// Log the error and return it
-			logrus.Error(err)
-			return nil, err
-		}
-		photos = append(photos, photo)
+	if err := cursor.All(ctx, &photos); err != nil {
+		// Log the error and return it
+		logrus.Error(err)
+		return nil, err
 	}
 	return photos, nil
 }
 
-// Delete deletes a photo document from the MongoDB collection by its ID.
+// FindByPersonID finds every photo with a face assigned to or suggested as
+// the given person that passes the quality filter, newest first.
 //
 // Parameters:
 // - ctx: The context.Context object for the function.
-// - id: The ID of the photo to be deleted.
+// - personID: The ID of the person.
+// - quality: The quality filter the person's face must pass.
 //
 // Returns:
-// - error: An error object if there was an error deleting the photo, otherwise nil.
-func (p *PhotoRepository) Delete(ctx context.Context, id string) error {
-	_, err := p.collection.DeleteOne(ctx, bson.M{"_id": id})
+// - photos: A slice of domain.Photo objects the person appears in.
+// - error: An error object if there was an error finding the photos, otherwise nil.
+func (p *PhotoRepository) FindByPersonID(ctx context.Context, personID domain.PersonID, quality domain.QualityFilter) ([]domain.Photo, error) {
+	photos := []domain.Photo{}
+	face := qualityQuery(quality)
+	face["$or"] = bson.A{
+		bson.M{"person_id": personID},
+		bson.M{"person_suggestion.person_id": personID},
+	}
+	opts := options.Find().SetSort(bson.M{"timestamp": -1})
+	cursor, err := p.collection.Find(ctx, bson.M{"faces": bson.M{"$elemMatch": face}}, opts)
+	if err != nil {
+		// Log the error and return it
+		logrus.Error(err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	if err := cursor.All(ctx, &photos); err != nil {
+		// Log the error and return it
+		logrus.Error(err)
+		return nil, err
+	}
+	return photos, nil
+}
+
+// FindByBurstID finds every photo of a burst, oldest first. Face embeddings
+// are not loaded.
+//
+// Parameters:
+// - ctx: The context.Context object for the function.
+// - burstID: The burst ID the photos were uploaded with.
+//
+// Returns:
+// - photos: A slice of domain.Photo objects representing the photos of the burst.
+// - error: An error object if there was an error finding the photos, otherwise nil.
+func (p *PhotoRepository) FindByBurstID(ctx context.Context, burstID string) ([]domain.Photo, error) {
+	photos := []domain.Photo{}
+	opts := options.Find().
+		SetSort(bson.M{"timestamp": 1}).
+		SetProjection(bson.M{"faces.embedding": 0})
+	cursor, err := p.collection.Find(ctx, bson.M{"burst_id": burstID}, opts)
+	if err != nil {
+		// Log the error and return it
+		logrus.Error(err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	if err := cursor.All(ctx, &photos); err != nil {
+		// Log the error and return it
+		logrus.Error(err)
+		return nil, err
+	}
+	return photos, nil
+}
+
+// SetFacePerson records that a face was assigned to the given person.
+//
+// Parameters:
+// - ctx: The context.Context object for the function.
+// - face: The face to update.
+// - personID: The ID of the person.
+// - score: The match score of the face.
+//
+// Returns:
+// - error: An error object if there was an error updating the photo, otherwise nil.
+func (p *PhotoRepository) SetFacePerson(ctx context.Context, face domain.FaceRef, personID domain.PersonID, score float64) error {
+	prefix := fmt.Sprintf("faces.%d.", face.FaceIndex)
+	update := bson.M{"$set": bson.M{prefix + "person_id": personID, prefix + "person_score": score}}
+	_, err := p.collection.UpdateOne(ctx, bson.M{"_id": face.PhotoID}, update)
+	if err != nil {
+		// Log the error and return it
+		logrus.Error(err)
+		return err
+	}
+	return nil
+}
+
+// Delete deletes a photo document from the MongoDB collection by its ID,
+// unless it was updated since it was read.
+//
+// Parameters:
+// - ctx: The context.Context object for the function.
+// - photo: A pointer to a domain.Photo object representing the photo as last read.
+//
+// Returns:
+// - error: mongo.ErrNoDocuments if the photo no longer exists or was updated since, an error object if there was an error deleting the photo, otherwise nil.
+func (p *PhotoRepository) Delete(ctx context.Context, photo *domain.Photo) error {
+	filter := bson.M{"_id": photo.ID, "updated_at": photo.UpdatedAt}
+	if photo.UpdatedAt.IsZero() {
+		filter["updated_at"] = bson.M{"$exists": false}
+	}
+	result, err := p.collection.DeleteOne(ctx, filter)
 	if err != nil {
 		// Log the error and return it
 		logrus.Error(err)
 		return err
 	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
 	return nil
 }
 
@@ -124,13 +388,45 @@ func (p *PhotoRepository) Delete(ctx context.Context, id string) error {
 // - photo: A pointer to a domain.Photo object representing the photo to be updated.
 //
 // Returns:
-// - error: An error object if there was an error updating the photo, otherwise nil.
+// - error: mongo.ErrNoDocuments if the photo no longer exists, an error object if there was an error updating the photo, otherwise nil.
 func (p *PhotoRepository) Update(ctx context.Context, photo *domain.Photo) error {
-	_, err := p.collection.UpdateOne(ctx, bson.M{"_id": photo.ID}, bson.M{"$set": photo})
+	result, err := p.collection.UpdateOne(ctx, bson.M{"_id": photo.ID}, bson.M{"$set": photo})
 	if err != nil {
 		// Log the error and return it
 		logrus.Error(err)
 		return err
 	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
 	return nil
 }
+
+// qualityQuery returns the conditions a face element must match to pass the
+// quality filter.
+func qualityQuery(f domain.QualityFilter) bson.M {
+	query := bson.M{}
+	if f.MinSharpness > 0 {
+		query["quality.sharpness"] = bson.M{"$gte": f.MinSharpness}
+	}
+	brightness := bson.M{}
+	if f.MinBrightness > 0 {
+		brightness["$gte"] = f.MinBrightness
+	}
+	if f.MaxBrightness > 0 {
+		brightness["$lte"] = f.MaxBrightness
+	}
+	if len(brightness) > 0 {
+		query["quality.brightness"] = brightness
+	}
+	if f.MinContrast > 0 {
+		query["quality.contrast"] = bson.M{"$gte": f.MinContrast}
+	}
+	if f.MinFaceSize > 0 {
+		query["quality.size"] = bson.M{"$gte": f.MinFaceSize}
+	}
+	if f.Unoccluded {
+		query["quality.occlusion"] = bson.M{"$exists": false}
+	}
+	return query
+}
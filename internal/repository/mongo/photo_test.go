@@ -0,0 +1,70 @@
+package mongo
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/anggi-susanto/go-face-detection-be/domain"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor photoCursor
+	}{
+		{name: "by timestamp", cursor: photoCursor{
+			Sort:      domain.SortTimestamp,
+			Timestamp: time.Date(2024, 3, 13, 15, 4, 5, 123456789, time.UTC),
+			ID:        domain.NewPhotoID(),
+		}},
+		{name: "by faces detected", cursor: photoCursor{
+			Sort:          domain.SortFacesDetected,
+			FacesDetected: 7,
+			ID:            domain.NewPhotoID(),
+		}},
+		{name: "no faces", cursor: photoCursor{
+			Sort: domain.SortFacesDetected,
+			ID:   domain.NewPhotoID(),
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeCursor(encodeCursor(tt.cursor), tt.cursor.Sort)
+			if err != nil {
+				t.Fatalf("decodeCursor() error = %v", err)
+			}
+			if got.Sort != tt.cursor.Sort || !got.Timestamp.Equal(tt.cursor.Timestamp) ||
+				got.FacesDetected != tt.cursor.FacesDetected || got.ID != tt.cursor.ID {
+				t.Errorf("decodeCursor() = %+v, want %+v", got, tt.cursor)
+			}
+		})
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	valid := encodeCursor(photoCursor{Sort: domain.SortTimestamp, Timestamp: time.Now(), ID: domain.NewPhotoID()})
+	encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+
+	tests := []struct {
+		name   string
+		cursor string
+		sort   string
+	}{
+		{name: "other sort", cursor: valid, sort: domain.SortFacesDetected},
+		{name: "not base64", cursor: "not a cursor!", sort: domain.SortTimestamp},
+		{name: "padded base64", cursor: base64.URLEncoding.EncodeToString([]byte(`{"s":"timestamp"}`)), sort: domain.SortTimestamp},
+		{name: "not json", cursor: encode("timestamp"), sort: domain.SortTimestamp},
+		{name: "invalid photo id", cursor: encode(`{"s":"timestamp","id":"42"}`), sort: domain.SortTimestamp},
+		{name: "invalid timestamp", cursor: encode(`{"s":"timestamp","t":"yesterday"}`), sort: domain.SortTimestamp},
+		{name: "missing sort", cursor: encode(`{}`), sort: domain.SortTimestamp},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeCursor(tt.cursor, tt.sort); !errors.Is(err, domain.ErrInvalidCursor) {
+				t.Errorf("decodeCursor(%q, %q) error = %v, want %v", tt.cursor, tt.sort, err, domain.ErrInvalidCursor)
+			}
+		})
+	}
+}
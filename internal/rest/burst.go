@@ -0,0 +1,56 @@
+package rest
+
+import (
+	"errors"
+
+	"github.com/anggi-susanto/go-face-detection-be/burst"
+	"github.com/anggi-susanto/go-face-detection-be/domain"
+	"github.com/gofiber/fiber/v2"
+)
+
+type BurstHandler interface {
+	GetBurst(c *fiber.Ctx) error
+}
+
+type burstHandler struct {
+	burstService burst.Service
+}
+
+func NewBurstHandler(burstService burst.Service) BurstHandler {
+	return &burstHandler{
+		burstService: burstService,
+	}
+}
+
+// GetBurst handles burst get.
+//
+// @Summary get burst
+// @Description rank the photos uploaded with a burst id and recommend the best frame, once every photo is processed
+// @Tags Face Detection
+// @Produce json
+// @Param id path string true "burst id"
+// @Success 200 {object} domain.Burst
+// @Failure 400 {object} ResponseError
+// @Failure 404 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /bursts/{id} [get]
+func (h *burstHandler) GetBurst(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if err := domain.ValidateBurstID(id); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+	result, err := h.burstService.GetBurst(c.Context(), id)
+	if errors.Is(err, burst.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusOK).JSON(result)
+}
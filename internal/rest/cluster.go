@@ -0,0 +1,209 @@
+package rest
+
+import (
+	"errors"
+
+	"github.com/anggi-susanto/go-face-detection-be/cluster"
+	"github.com/anggi-susanto/go-face-detection-be/domain"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type ClusterHandler interface {
+	ListClusters(c *fiber.Ctx) error
+	GetCluster(c *fiber.Ctx) error
+	MergeClusters(c *fiber.Ctx) error
+	SplitCluster(c *fiber.Ctx) error
+	NameCluster(c *fiber.Ctx) error
+}
+
+type clusterHandler struct {
+	clusterService cluster.Service
+}
+
+func NewClusterHandler(clusterService cluster.Service) ClusterHandler {
+	return &clusterHandler{
+		clusterService: clusterService,
+	}
+}
+
+// ListClusters handles cluster list.
+//
+// @Summary list clusters
+// @Description list every face cluster, largest first
+// @Tags Clusters
+// @Produce json
+// @Success 200 {array} domain.Cluster
+// @Failure 500 {object} ResponseError
+// @Router /clusters [get]
+func (h *clusterHandler) ListClusters(c *fiber.Ctx) error {
+	clusters, err := h.clusterService.ListClusters(c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusOK).JSON(clusters)
+}
+
+// GetCluster handles cluster get.
+//
+// @Summary get cluster
+// @Description get a face cluster with all its faces
+// @Tags Clusters
+// @Produce json
+// @Param id path string true "cluster id"
+// @Success 200 {object} domain.Cluster
+// @Failure 400 {object} ResponseError
+// @Failure 404 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /clusters/{id} [get]
+func (h *clusterHandler) GetCluster(c *fiber.Ctx) error {
+	id, err := domain.ParseClusterID(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+	result, err := h.clusterService.GetCluster(c.Context(), id)
+	if err != nil {
+		return clusterError(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(result)
+}
+
+// MergeClusters handles cluster merge.
+//
+// @Summary merge clusters
+// @Description move every face of the given clusters into the cluster and delete them
+// @Tags Clusters
+// @Accept json
+// @Produce json
+// @Param id path string true "cluster id"
+// @Param request body domain.ClusterMergeRequest true "clusters to merge"
+// @Success 200 {object} domain.Cluster
+// @Failure 400 {object} ResponseError
+// @Failure 404 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /clusters/{id}/merge [post]
+func (h *clusterHandler) MergeClusters(c *fiber.Ctx) error {
+	id, err := domain.ParseClusterID(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+	var req domain.ClusterMergeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+	if err := req.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+	result, err := h.clusterService.Merge(c.Context(), id, req)
+	if err != nil {
+		return clusterError(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(result)
+}
+
+// SplitCluster handles cluster split.
+//
+// @Summary split cluster
+// @Description move the given faces out of the cluster into a new cluster
+// @Tags Clusters
+// @Accept json
+// @Produce json
+// @Param id path string true "cluster id"
+// @Param request body domain.ClusterSplitRequest true "faces to move"
+// @Success 201 {object} domain.Cluster
+// @Failure 400 {object} ResponseError
+// @Failure 404 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /clusters/{id}/split [post]
+func (h *clusterHandler) SplitCluster(c *fiber.Ctx) error {
+	id, err := domain.ParseClusterID(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+	var req domain.ClusterSplitRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+	if err := req.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+	result, err := h.clusterService.Split(c.Context(), id, req)
+	if err != nil {
+		return clusterError(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(result)
+}
+
+// NameCluster handles cluster naming.
+//
+// @Summary name cluster
+// @Description enroll the cluster as a named person and assign its faces to them
+// @Tags Clusters
+// @Accept json
+// @Produce json
+// @Param id path string true "cluster id"
+// @Param request body domain.PersonRequest true "person name"
+// @Success 200 {object} domain.Cluster
+// @Failure 400 {object} ResponseError
+// @Failure 404 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /clusters/{id}/name [post]
+func (h *clusterHandler) NameCluster(c *fiber.Ctx) error {
+	id, err := domain.ParseClusterID(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+	var req domain.PersonRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+	if err := req.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+	result, err := h.clusterService.Name(c.Context(), id, req)
+	if err != nil {
+		return clusterError(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(result)
+}
+
+// clusterError responds 404 for unknown clusters, 400 for edits that do not
+// fit them and 500 otherwise.
+func clusterError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return c.Status(fiber.StatusNotFound).JSON(ResponseError{
+			Message: "cluster not found",
+		})
+	case errors.Is(err, cluster.ErrInvalidEdit):
+		return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// errRangeNotSatisfiable is returned for a byte range outside the file.
+var errRangeNotSatisfiable = errors.New("range not satisfiable")
+
+// sendPhotoFile streams a stored photo file from disk without buffering it.
+// The Content-Type is sniffed from the content rather than taken from the
+// file name, which comes from the client. The ETag and Last-Modified headers
+// come from the file. If-None-Match and If-Modified-Since are answered with
+// 304, and a single byte range, honoring If-Range, with 206 or 416. Several
+// ranges are not supported and get the whole file.
+//
+// Parameters:
+// - c: The request context.
+// - filePath: The path of the stored file.
+//
+// Returns:
+// - error: An error object if the response could not be written, otherwise nil.
+func sendPhotoFile(c *fiber.Ctx, filePath string) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return c.Status(fiber.StatusNotFound).JSON(ResponseError{
+				Message: "file not available",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+	// Once it is the body stream, the response closes the file after
+	// sending it.
+	streaming := false
+	defer func() {
+		if !streaming {
+			file.Close()
+		}
+	}()
+
+	info, err := file.Stat()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return c.Status(fiber.StatusInternalServerError).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+	contentType := http.DetectContentType(head[:n])
+
+	size, modTime := info.Size(), info.ModTime()
+	etag := fmt.Sprintf(`"%x-%x"`, modTime.UnixNano(), size)
+	c.Set(fiber.HeaderETag, etag)
+	c.Set(fiber.HeaderLastModified, modTime.UTC().Format(http.TimeFormat))
+	c.Set(fiber.HeaderAcceptRanges, "bytes")
+	if notModified(c, etag, modTime) {
+		c.Status(fiber.StatusNotModified)
+		return nil
+	}
+
+	status, start, end := fiber.StatusOK, int64(0), size-1
+	if header := c.Get(fiber.HeaderRange); header != "" && rangeCurrent(c, etag, modTime) {
+		first, last, ok, err := parseByteRange(header, size)
+		if err != nil {
+			c.Set(fiber.HeaderContentRange, fmt.Sprintf("bytes */%d", size))
+			return c.Status(fiber.StatusRequestedRangeNotSatisfiable).JSON(ResponseError{
+				Message: err.Error(),
+			})
+		}
+		if ok {
+			status, start, end = fiber.StatusPartialContent, first, last
+			c.Set(fiber.HeaderContentRange, fmt.Sprintf("bytes %d-%d/%d", start, end, size))
+		}
+	}
+
+	c.Status(status)
+	c.Set(fiber.HeaderContentType, contentType)
+	length := end - start + 1
+	c.Response().SetBodyStream(fileSection{io.NewSectionReader(file, start, length), file}, int(length))
+	streaming = true
+	return nil
+}
+
+// fileSection streams a section of a file and closes the file once the
+// response is written.
+type fileSection struct {
+	*io.SectionReader
+	io.Closer
+}
+
+// notModified reports whether the client copy is current. If-None-Match
+// takes precedence over If-Modified-Since, which has a one second precision.
+func notModified(c *fiber.Ctx, etag string, modTime time.Time) bool {
+	if match := c.Get(fiber.HeaderIfNoneMatch); match != "" {
+		for _, tag := range strings.Split(match, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+				return true
+			}
+		}
+		return false
+	}
+	since, err := http.ParseTime(c.Get(fiber.HeaderIfModifiedSince))
+	return err == nil && !modTime.Truncate(time.Second).After(since)
+}
+
+// rangeCurrent reports whether a Range request applies, which it does unless
+// If-Range names an older version of the file.
+func rangeCurrent(c *fiber.Ctx, etag string, modTime time.Time) bool {
+	ifRange := c.Get(fiber.HeaderIfRange)
+	if ifRange == "" {
+		return true
+	}
+	if strings.HasPrefix(ifRange, `"`) {
+		return ifRange == etag
+	}
+	date, err := http.ParseTime(ifRange)
+	return err == nil && modTime.Truncate(time.Second).Equal(date)
+}
+
+// parseByteRange parses a Range header asking for a single byte range of a
+// file of the given size, as "bytes=first-last", "bytes=first-" or
+// "bytes=-suffix".
+//
+// Parameters:
+// - header: The Range header.
+// - size: The size of the file.
+//
+// Returns:
+// - int64: The first byte of the range.
+// - int64: The last byte of the range, clamped to the file.
+// - bool: False if the header is malformed or asks for several ranges, which are ignored.
+// - error: errRangeNotSatisfiable if the range lies outside the file, otherwise nil.
+func parseByteRange(header string, size int64) (int64, int64, bool, error) {
+	spec, found := strings.CutPrefix(header, "bytes=")
+	if !found || strings.Contains(spec, ",") {
+		return 0, 0, false, nil
+	}
+	firstSpec, lastSpec, found := strings.Cut(strings.TrimSpace(spec), "-")
+	if !found {
+		return 0, 0, false, nil
+	}
+
+	if firstSpec == "" {
+		suffix, err := strconv.ParseInt(lastSpec, 10, 64)
+		if err != nil || suffix < 0 {
+			return 0, 0, false, nil
+		}
+		if suffix == 0 || size == 0 {
+			return 0, 0, false, errRangeNotSatisfiable
+		}
+		return max(0, size-suffix), size - 1, true, nil
+	}
+
+	first, err := strconv.ParseInt(firstSpec, 10, 64)
+	if err != nil || first < 0 {
+		return 0, 0, false, nil
+	}
+	last := size - 1
+	if lastSpec != "" {
+		if last, err = strconv.ParseInt(lastSpec, 10, 64); err != nil || last < first {
+			return 0, 0, false, nil
+		}
+	}
+	if first >= size {
+		return 0, 0, false, errRangeNotSatisfiable
+	}
+	return first, min(last, size-1), true, nil
+}
@@ -0,0 +1,129 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestSendPhotoFile(t *testing.T) {
+	content := pngPhoto(t, 40, 30)
+	size := len(content)
+	path := filepath.Join(t.TempDir(), "upload.jpg")
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		t.Fatalf("os.WriteFile() error = %v", err)
+	}
+	modTime := time.Date(2024, 3, 1, 12, 0, 0, 500, time.UTC)
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("os.Chtimes() error = %v", err)
+	}
+	etag := fmt.Sprintf(`"%x-%x"`, modTime.UnixNano(), size)
+	lastModified := modTime.Format(http.TimeFormat)
+	earlier := modTime.Add(-time.Hour).Format(http.TimeFormat)
+	whole := fmt.Sprintf("0-%d", size-1)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		headers    map[string]string
+		wantStatus int
+		// wantRange is the served "first-last" byte range, empty for none.
+		wantRange        string
+		wantContentRange string
+	}{
+		{name: "whole file", wantStatus: fiber.StatusOK, wantRange: whole},
+		{name: "head", method: fiber.MethodHead, wantStatus: fiber.StatusOK},
+		{name: "matching etag", headers: map[string]string{fiber.HeaderIfNoneMatch: etag}, wantStatus: fiber.StatusNotModified},
+		{name: "matching weak etag in a list", headers: map[string]string{fiber.HeaderIfNoneMatch: `"other", W/` + etag}, wantStatus: fiber.StatusNotModified},
+		{name: "any etag", headers: map[string]string{fiber.HeaderIfNoneMatch: "*"}, wantStatus: fiber.StatusNotModified},
+		{name: "stale etag", headers: map[string]string{fiber.HeaderIfNoneMatch: `"other"`}, wantStatus: fiber.StatusOK, wantRange: whole},
+		{name: "not modified since", headers: map[string]string{fiber.HeaderIfModifiedSince: lastModified}, wantStatus: fiber.StatusNotModified},
+		{name: "modified since", headers: map[string]string{fiber.HeaderIfModifiedSince: earlier}, wantStatus: fiber.StatusOK, wantRange: whole},
+		{name: "etag wins over date", headers: map[string]string{fiber.HeaderIfNoneMatch: `"other"`, fiber.HeaderIfModifiedSince: lastModified},
+			wantStatus: fiber.StatusOK, wantRange: whole},
+		{name: "range", headers: map[string]string{fiber.HeaderRange: "bytes=0-9"},
+			wantStatus: fiber.StatusPartialContent, wantRange: "0-9", wantContentRange: fmt.Sprintf("bytes 0-9/%d", size)},
+		{name: "open range", headers: map[string]string{fiber.HeaderRange: "bytes=10-"},
+			wantStatus: fiber.StatusPartialContent, wantRange: fmt.Sprintf("10-%d", size-1), wantContentRange: fmt.Sprintf("bytes 10-%d/%d", size-1, size)},
+		{name: "suffix range", headers: map[string]string{fiber.HeaderRange: "bytes=-5"},
+			wantStatus: fiber.StatusPartialContent, wantRange: fmt.Sprintf("%d-%d", size-5, size-1), wantContentRange: fmt.Sprintf("bytes %d-%d/%d", size-5, size-1, size)},
+		{name: "range past the end", headers: map[string]string{fiber.HeaderRange: "bytes=5-100000"},
+			wantStatus: fiber.StatusPartialContent, wantRange: fmt.Sprintf("5-%d", size-1), wantContentRange: fmt.Sprintf("bytes 5-%d/%d", size-1, size)},
+		{name: "range outside the file", headers: map[string]string{fiber.HeaderRange: fmt.Sprintf("bytes=%d-", size)},
+			wantStatus: fiber.StatusRequestedRangeNotSatisfiable, wantContentRange: fmt.Sprintf("bytes */%d", size)},
+		{name: "several ranges", headers: map[string]string{fiber.HeaderRange: "bytes=0-1,4-5"}, wantStatus: fiber.StatusOK, wantRange: whole},
+		{name: "malformed range", headers: map[string]string{fiber.HeaderRange: "bytes=9-2"}, wantStatus: fiber.StatusOK, wantRange: whole},
+		{name: "current etag if-range", headers: map[string]string{fiber.HeaderRange: "bytes=0-9", fiber.HeaderIfRange: etag},
+			wantStatus: fiber.StatusPartialContent, wantRange: "0-9", wantContentRange: fmt.Sprintf("bytes 0-9/%d", size)},
+		{name: "current date if-range", headers: map[string]string{fiber.HeaderRange: "bytes=0-9", fiber.HeaderIfRange: lastModified},
+			wantStatus: fiber.StatusPartialContent, wantRange: "0-9", wantContentRange: fmt.Sprintf("bytes 0-9/%d", size)},
+		{name: "stale if-range", headers: map[string]string{fiber.HeaderRange: "bytes=0-9", fiber.HeaderIfRange: `"other"`},
+			wantStatus: fiber.StatusOK, wantRange: whole},
+		{name: "missing file", path: filepath.Join(t.TempDir(), "gone.jpg"), wantStatus: fiber.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := path
+			if tt.path != "" {
+				filePath = tt.path
+			}
+			app := fiber.New()
+			app.Get("/file", func(c *fiber.Ctx) error { return sendPhotoFile(c, filePath) })
+
+			method := fiber.MethodGet
+			if tt.method != "" {
+				method = tt.method
+			}
+			req := httptest.NewRequest(method, "/file", nil)
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			resp, body := send(t, app, req)
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", resp.StatusCode, tt.wantStatus, body)
+			}
+			if got := resp.Header.Get(fiber.HeaderContentRange); got != tt.wantContentRange {
+				t.Errorf("Content-Range = %q, want %q", got, tt.wantContentRange)
+			}
+			if tt.wantStatus == fiber.StatusNotFound {
+				return
+			}
+			if got := resp.Header.Get(fiber.HeaderETag); got != etag {
+				t.Errorf("ETag = %q, want %q", got, etag)
+			}
+			if got := resp.Header.Get(fiber.HeaderLastModified); got != lastModified {
+				t.Errorf("Last-Modified = %q, want %q", got, lastModified)
+			}
+
+			if tt.wantRange == "" {
+				if len(body) != 0 && tt.wantStatus != fiber.StatusRequestedRangeNotSatisfiable {
+					t.Errorf("body has %d bytes, want none", len(body))
+				}
+				if tt.method == fiber.MethodHead && resp.Header.Get(fiber.HeaderContentLength) != strconv.Itoa(size) {
+					t.Errorf("Content-Length = %q, want %d", resp.Header.Get(fiber.HeaderContentLength), size)
+				}
+				return
+			}
+			var first, last int
+			fmt.Sscanf(tt.wantRange, "%d-%d", &first, &last)
+			if string(body) != string(content[first:last+1]) {
+				t.Errorf("body has %d bytes, want bytes %s of the file", len(body), tt.wantRange)
+			}
+			if got := resp.Header.Get(fiber.HeaderContentType); got != "image/png" {
+				t.Errorf("Content-Type = %q, want the sniffed image/png", got)
+			}
+			if got := resp.Header.Get(fiber.HeaderAcceptRanges); got != "bytes" {
+				t.Errorf("Accept-Ranges = %q, want bytes", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"image"
+
+	"github.com/anggi-susanto/go-face-detection-be/domain"
+	"github.com/anggi-susanto/go-face-detection-be/internal/embedding"
+	"github.com/anggi-susanto/go-face-detection-be/person"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type PersonHandler interface {
+	Enroll(c *fiber.Ctx) error
+	ListPeople(c *fiber.Ctx) error
+	GetPerson(c *fiber.Ctx) error
+	GetPersonPhotos(c *fiber.Ctx) error
+}
+
+type personHandler struct {
+	personService person.Service
+}
+
+func NewPersonHandler(personService person.Service) PersonHandler {
+	return &personHandler{
+		personService: personService,
+	}
+}
+
+// Enroll handles person enrollment.
+//
+// @Summary enroll a person
+// @Description enroll a named person from one or more reference photos, new faces looking like them get the person as a person_suggestion with its look-alike score. Matching uses an LBP texture descriptor, not a face recognition model, so suggestions are not identifications and faces are never assigned automatically.
+// @Tags People
+// @Accept multipart/form-data
+// @Produce json
+// @Param name formData string true "person name"
+// @Param photos formData file true "reference photos, the largest face of each is used"
+// @Success 201 {object} domain.Person
+// @Failure 400 {object} ResponseError
+// @Failure 422 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /people [post]
+func (h *personHandler) Enroll(c *fiber.Ctx) error {
+	var req domain.PersonRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+	if err := req.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil || len(form.File["photos"]) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+			Message: "at least one reference photo is required",
+		})
+	}
+	files := form.File["photos"]
+	if len(files) > domain.MaxReferencePhotos {
+		return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+			Message: fmt.Sprintf("at most %d reference photos are allowed", domain.MaxReferencePhotos),
+		})
+	}
+	photos := make([]image.Image, 0, len(files))
+	for i, file := range files {
+		img, err := readUpright(file)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+				Message: fmt.Sprintf("invalid reference photo %d", i+1),
+			})
+		}
+		photos = append(photos, img)
+	}
+
+	p, err := h.personService.Enroll(c.Context(), req, photos)
+	if errors.Is(err, embedding.ErrNoFace) {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusCreated).JSON(p)
+}
+
+// ListPeople handles people list.
+//
+// @Summary list people
+// @Description list every enrolled person
+// @Tags People
+// @Produce json
+// @Success 200 {array} domain.Person
+// @Failure 500 {object} ResponseError
+// @Router /people [get]
+func (h *personHandler) ListPeople(c *fiber.Ctx) error {
+	people, err := h.personService.ListPeople(c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusOK).JSON(people)
+}
+
+// GetPerson handles person get.
+//
+// @Summary get person
+// @Description get an enrolled person
+// @Tags People
+// @Produce json
+// @Param id path string true "person id"
+// @Success 200 {object} domain.Person
+// @Failure 400 {object} ResponseError
+// @Failure 404 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /people/{id} [get]
+func (h *personHandler) GetPerson(c *fiber.Ctx) error {
+	id, err := domain.ParsePersonID(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+	p, err := h.personService.GetPerson(c.Context(), id)
+	if err != nil {
+		return personError(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(p)
+}
+
+// GetPersonPhotos handles person photos list.
+//
+// @Summary list photos of a person
+// @Description list every photo with a face assigned to the person by naming a cluster, or suggested as the person because it looks alike, newest first. Suggestions carry a look-alike score and are not identity matches.
+// @Tags People
+// @Produce json
+// @Param id path string true "person id"
+// @Param min_sharpness query number false "only list photos where the person's face is at least this sharp"
+// @Param min_brightness query number false "only list photos where the person's face is at least this bright, 0 to 255"
+// @Param max_brightness query number false "only list photos where the person's face is at most this bright, 0 to 255"
+// @Param min_contrast query number false "only list photos where the person's face has at least this luma standard deviation"
+// @Param min_face_size query integer false "only list photos where the smaller side of the person's face is at least this many pixels"
+// @Param unoccluded query boolean false "only list photos where the person's face has no occlusion hints"
+// @Success 200 {array} domain.Photo
+// @Failure 400 {object} ResponseError
+// @Failure 404 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /people/{id}/photos [get]
+func (h *personHandler) GetPersonPhotos(c *fiber.Ctx) error {
+	id, err := domain.ParsePersonID(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+	var quality domain.QualityFilter
+	if err := c.QueryParser(&quality); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+	if err := quality.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+	photos, err := h.personService.GetPhotos(c.Context(), id, quality)
+	if err != nil {
+		return personError(c, err)
+	}
+	for i := range photos {
+		setFileURLs(&photos[i])
+	}
+	return c.Status(fiber.StatusOK).JSON(photos)
+}
+
+// personError responds 404 for unknown people and 500 otherwise.
+func personError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return c.Status(fiber.StatusNotFound).JSON(ResponseError{
+			Message: "person not found",
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(ResponseError{
+		Message: err.Error(),
+	})
+}
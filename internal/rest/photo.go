@@ -1,13 +1,17 @@
 package rest
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/anggi-susanto/go-face-detection-be/domain"
+	"github.com/anggi-susanto/go-face-detection-be/internal/detector"
 	"github.com/anggi-susanto/go-face-detection-be/internal/queue"
 	"github.com/anggi-susanto/go-face-detection-be/internal/storage"
 	"github.com/anggi-susanto/go-face-detection-be/photo"
 	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 // ResponseError represents an error response
@@ -19,71 +23,175 @@ type PhotoHandler interface {
 	Upload(c *fiber.Ctx) error
 	CheckResult(c *fiber.Ctx) error
 	GetPhoto(c *fiber.Ctx) error
+	DeletePhoto(c *fiber.Ctx) error
+	ListPhotos(c *fiber.Ctx) error
+	GetOriginalPhoto(c *fiber.Ctx) error
+	GetAnnotatedPhoto(c *fiber.Ctx) error
+	GetRedactedPhoto(c *fiber.Ctx) error
+	GetFaceCrop(c *fiber.Ctx) error
 }
 
 type photoHandler struct {
 	photoService  photo.Service
 	photoProducer queue.Producer
+	detector      detector.Detector
 }
 
-func NewPhotoHandler(photoService photo.Service, photoProducer queue.Producer) PhotoHandler {
+func NewPhotoHandler(photoService photo.Service, photoProducer queue.Producer, detector detector.Detector) PhotoHandler {
 	return &photoHandler{
 		photoService:  photoService,
 		photoProducer: photoProducer,
+		detector:      detector,
 	}
 }
 
 // Upload handles file upload.
 //
 // @Summary upload image for face detection
-// @Description upload image for face detection
+// @Description queue a photo for face detection and return the job, poll its result link until the status is no longer pending
 // @Tags Face Detection
-// @Accept json
+// @Accept multipart/form-data
 // @Produce json
-// @Param photo body domain.Photo true "photp data"
-// @Success 201 {object} domain.Photo
+// @Param photo formData file true "photo to scan, JPEG or PNG"
+// @Param scale_factor formData number false "cascade scale factor, greater than 1" default(1.1)
+// @Param min_neighbors formData integer false "minimum neighbouring detections per face" default(4)
+// @Param min_size formData integer false "minimum face size in pixels"
+// @Param max_size formData integer false "maximum face size in pixels"
+// @Param tiled formData boolean false "scan the full resolution image in overlapping tiles, for large or crowded photos"
+// @Param tile_size formData integer false "tile side length in pixels" default(1024)
+// @Param tile_overlap formData number false "fraction of a tile shared with its neighbours" default(0.25)
+// @Param rotation_retry formData string false "also scan rotated copies of the photo, when nothing is found or always" Enums(on_empty, exhaustive)
+// @Param rotation_tilt formData boolean false "include -15 and 15 degree tilts in rotation retries"
+// @Param max_yaw formData number false "drop faces whose estimated yaw exceeds this many degrees, 0 keeps every face"
+// @Param models formData []string false "cascade models to run, the pigo backend only runs the frontal models; eyes, upper_body and smile detections are returned as regions, not faces" collectionFormat(multi) Enums(frontal_default, frontal_alt, frontal_alt2, profile, eyes, upper_body, smile)
+// @Param profile formData string false "add a use case specific report to the result" Enums(compliance)
+// @Param redact formData string false "write a redacted variant instead of the annotated one" Enums(blur, pixelate, box)
+// @Param discard_original formData boolean false "delete the original once redacted, or when processing fails, requires redact"
+// @Param burst_id formData string false "group the photo with the other photos of a burst for best-shot selection"
+// @Param tags formData []string false "labels to filter photo listings by" collectionFormat(multi)
+// @Success 202 {object} domain.PhotoJob
+// @Header 202 {string} Location "status URL of the job"
 // @Failure 400 {object} ResponseError
 // @Failure 500 {object} ResponseError
 // @Router /upload [post]
 func (h *photoHandler) Upload(c *fiber.Ctx) error {
 	_, err := c.FormFile("photo")
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid file"})
+		return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+			Message: "photo file is required",
+		})
+	}
+
+	params := domain.DefaultDetectionParams()
+	if err := c.BodyParser(&params); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+	params.Models = params.ModelList()
+	if err := params.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+	for _, model := range params.Models {
+		if !h.detector.Supports(model) {
+			return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+				Message: fmt.Sprintf("model %q is not supported by the %s detector backend", model, h.detector.Name()),
+			})
+		}
+	}
+
+	var redaction domain.RedactionOptions
+	if err := c.BodyParser(&redaction); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+	if err := redaction.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+
+	burstID := c.FormValue("burst_id")
+	if err := domain.ValidateBurstID(burstID); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+
+	var labels struct {
+		Tags []string `form:"tags"`
+	}
+	if err := c.BodyParser(&labels); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+	tags, err := domain.TagList(labels.Tags)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+			Message: err.Error(),
+		})
 	}
 
 	filePath, err := storage.SavePhoto(c)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to save photo"})
+		return c.Status(fiber.StatusInternalServerError).JSON(ResponseError{
+			Message: "failed to save photo",
+		})
 	}
 
-	photoID := time.Now().UnixNano()
+	photoID := domain.NewPhotoID()
+	now := time.Now()
 
 	photo := &domain.Photo{
 		ID:            photoID,
 		FilePath:      filePath,
 		Status:        "pending",
+		BurstID:       burstID,
+		Tags:          tags,
+		Params:        params,
+		Redaction:     redaction,
 		FacesDetected: 0,
-		TimeStamp:     time.Now(),
-	}
-	if err := c.BodyParser(photo); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
-			Message: err.Error(),
-		})
+		TimeStamp:     now,
+		UpdatedAt:     now,
 	}
 	if err := h.photoService.Save(c.Context(), photo); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(ResponseError{
 			Message: err.Error(),
 		})
 	}
-	if err := h.photoProducer.SendToQueue(photoID); err != nil {
+	if err := h.photoProducer.SendToQueue(queue.Message{PhotoID: photoID, Params: params, Redaction: redaction}); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(ResponseError{
 			Message: err.Error(),
 		})
 	}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"message": "Photo uploaded successfully",
-	})
+	job := newPhotoJob(photo)
+	c.Location(job.Links.Result)
+	return c.Status(fiber.StatusAccepted).JSON(job)
+}
+
+// newPhotoJob returns the job resource of a newly uploaded photo.
+func newPhotoJob(photo *domain.Photo) domain.PhotoJob {
+	id := photo.ID.String()
+	job := domain.PhotoJob{
+		ID:        photo.ID,
+		Status:    photo.Status,
+		BurstID:   photo.BurstID,
+		CreatedAt: photo.TimeStamp,
+		UpdatedAt: photo.UpdatedAt,
+		Links: domain.PhotoLinks{
+			Result: "/result/" + id,
+			Photo:  "/photo/" + id,
+		},
+	}
+	if photo.BurstID != "" {
+		job.Links.Burst = "/bursts/" + photo.BurstID
+	}
+	return job
 }
 
 // CheckResult handles photo check result.
@@ -95,16 +203,22 @@ func (h *photoHandler) Upload(c *fiber.Ctx) error {
 // @Produce json
 // @Param id path string true "photo id"
 // @Success 200 {object} domain.Photo
+// @Failure 400 {object} ResponseError
+// @Failure 404 {object} ResponseError
 // @Failure 500 {object} ResponseError
 // @Router /result/{id} [get]
 func (h *photoHandler) CheckResult(c *fiber.Ctx) error {
-	id := c.Params("id")
-	photo, err := h.photoService.CheckResult(c.Context(), id)
+	id, err := domain.ParsePhotoID(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(ResponseError{
+		return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
 			Message: err.Error(),
 		})
 	}
+	photo, err := h.photoService.CheckResult(c.Context(), id)
+	if err != nil {
+		return photoError(c, err)
+	}
+	setFileURLs(photo)
 	return c.Status(fiber.StatusOK).JSON(photo)
 }
 
@@ -117,15 +231,314 @@ func (h *photoHandler) CheckResult(c *fiber.Ctx) error {
 // @Produce json
 // @Param id path string true "photo id"
 // @Success 200 {object} domain.Photo
+// @Failure 400 {object} ResponseError
+// @Failure 404 {object} ResponseError
 // @Failure 500 {object} ResponseError
 // @Router /photo/{id} [get]
 func (h *photoHandler) GetPhoto(c *fiber.Ctx) error {
-	id := c.Params("id")
+	id, err := domain.ParsePhotoID(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
 	photo, err := h.photoService.GetPhoto(c.Context(), id)
+	if err != nil {
+		return photoError(c, err)
+	}
+	setFileURLs(photo)
+	return c.Status(fiber.StatusOK).JSON(photo)
+}
+
+// DeletePhoto handles photo delete.
+//
+// @Summary delete photo
+// @Description delete the photo with its original, processed, redacted and face crop files, and drop it from search and clusters; a job still queued for it is skipped
+// @Tags Face Detection
+// @Produce json
+// @Param id path string true "photo id"
+// @Success 200 {object} domain.PhotoDeletion
+// @Failure 400 {object} ResponseError
+// @Failure 404 {object} ResponseError
+// @Failure 409 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /photo/{id} [delete]
+func (h *photoHandler) DeletePhoto(c *fiber.Ctx) error {
+	id, err := domain.ParsePhotoID(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+	deletion, err := h.photoService.Delete(c.Context(), id)
+	if errors.Is(err, photo.ErrDeleteConflict) {
+		return c.Status(fiber.StatusConflict).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+	if err != nil {
+		return photoError(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(deletion)
+}
+
+// ListPhotos handles photo list.
+//
+// @Summary list photos
+// @Description list photos page by page, newest first unless sorted otherwise, pass next_cursor as cursor to fetch the next page
+// @Tags Face Detection
+// @Produce json
+// @Param status query string false "job status" Enums(pending, processed, error)
+// @Param from query string false "uploaded at or after this RFC 3339 time"
+// @Param to query string false "uploaded before this RFC 3339 time"
+// @Param min_faces query integer false "minimum number of detected faces"
+// @Param max_faces query integer false "maximum number of detected faces"
+// @Param tag query string false "upload tag"
+// @Param detector query string false "detector backend that processed the photo" Enums(pigo, python, python-process)
+// @Param min_sharpness query number false "only list photos with a face at least this sharp"
+// @Param min_brightness query number false "only list photos with a face at least this bright, 0 to 255"
+// @Param max_brightness query number false "only list photos with a face at most this bright, 0 to 255"
+// @Param min_contrast query number false "only list photos with a face with at least this luma standard deviation"
+// @Param min_face_size query integer false "only list photos with a face whose smaller side is at least this many pixels"
+// @Param unoccluded query boolean false "only list photos with a face without occlusion hints"
+// @Param sort query string false "sort field" Enums(timestamp, faces_detected) default(timestamp)
+// @Param order query string false "sort order" Enums(asc, desc) default(desc)
+// @Param limit query integer false "page size" default(20)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} domain.PhotoPage
+// @Failure 400 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /photos [get]
+func (h *photoHandler) ListPhotos(c *fiber.Ctx) error {
+	req := domain.DefaultPhotoListRequest()
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+	if err := req.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+	var quality domain.QualityFilter
+	if err := c.QueryParser(&quality); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+	if err := quality.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+
+	page, err := h.photoService.ListPhotos(c.Context(), req, quality)
+	if errors.Is(err, domain.ErrInvalidCursor) {
+		return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(ResponseError{
 			Message: err.Error(),
 		})
 	}
-	return c.Status(fiber.StatusOK).JSON(photo)
+	for i := range page.Photos {
+		setFileURLs(&page.Photos[i])
+	}
+	return c.Status(fiber.StatusOK).JSON(page)
+}
+
+// GetOriginalPhoto handles original photo get.
+//
+// @Summary get original photo
+// @Description stream the uploaded photo, with ETag and Last-Modified validators, conditional GET and Range support
+// @Tags Face Detection
+// @Produce image/jpeg,image/png
+// @Param id path string true "photo id"
+// @Param Range header string false "byte range"
+// @Param If-None-Match header string false "ETag of a cached copy"
+// @Param If-Modified-Since header string false "Last-Modified of a cached copy"
+// @Success 200 {file} binary
+// @Success 206 {file} binary
+// @Success 304 "not modified"
+// @Failure 400 {object} ResponseError
+// @Failure 404 {object} ResponseError
+// @Failure 416 "range not satisfiable"
+// @Failure 500 {object} ResponseError
+// @Router /photo/{id}/original [get]
+func (h *photoHandler) GetOriginalPhoto(c *fiber.Ctx) error {
+	id, err := domain.ParsePhotoID(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+	photo, err := h.photoService.GetPhoto(c.Context(), id)
+	if err != nil {
+		return photoError(c, err)
+	}
+	if photo.FilePath == "" {
+		return c.Status(fiber.StatusNotFound).JSON(ResponseError{
+			Message: "original photo not available",
+		})
+	}
+	return sendPhotoFile(c, photo.FilePath)
+}
+
+// GetAnnotatedPhoto handles annotated photo get.
+//
+// @Summary get annotated photo
+// @Description stream the processed photo with the detected faces outlined, with ETag and Last-Modified validators, conditional GET and Range support
+// @Tags Face Detection
+// @Produce image/jpeg,image/png
+// @Param id path string true "photo id"
+// @Param Range header string false "byte range"
+// @Param If-None-Match header string false "ETag of a cached copy"
+// @Param If-Modified-Since header string false "Last-Modified of a cached copy"
+// @Success 200 {file} binary
+// @Success 206 {file} binary
+// @Success 304 "not modified"
+// @Failure 400 {object} ResponseError
+// @Failure 404 {object} ResponseError
+// @Failure 416 "range not satisfiable"
+// @Failure 500 {object} ResponseError
+// @Router /photo/{id}/annotated [get]
+func (h *photoHandler) GetAnnotatedPhoto(c *fiber.Ctx) error {
+	id, err := domain.ParsePhotoID(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+	photo, err := h.photoService.GetPhoto(c.Context(), id)
+	if err != nil {
+		return photoError(c, err)
+	}
+	if !hasAnnotated(photo) {
+		return c.Status(fiber.StatusNotFound).JSON(ResponseError{
+			Message: "annotated photo not available",
+		})
+	}
+	return sendPhotoFile(c, storage.ProcessedPath(photo.FilePath))
+}
+
+// GetRedactedPhoto handles redacted photo get.
+//
+// @Summary get redacted photo
+// @Description stream the photo variant with every detected face obscured, with ETag and Last-Modified validators, conditional GET and Range support
+// @Tags Face Detection
+// @Produce image/jpeg,image/png
+// @Param id path string true "photo id"
+// @Param Range header string false "byte range"
+// @Param If-None-Match header string false "ETag of a cached copy"
+// @Param If-Modified-Since header string false "Last-Modified of a cached copy"
+// @Success 200 {file} binary
+// @Success 206 {file} binary
+// @Success 304 "not modified"
+// @Failure 400 {object} ResponseError
+// @Failure 404 {object} ResponseError
+// @Failure 416 "range not satisfiable"
+// @Failure 500 {object} ResponseError
+// @Router /photo/{id}/redacted [get]
+func (h *photoHandler) GetRedactedPhoto(c *fiber.Ctx) error {
+	id, err := domain.ParsePhotoID(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+	photo, err := h.photoService.GetPhoto(c.Context(), id)
+	if err != nil {
+		return photoError(c, err)
+	}
+	if photo.RedactedPath == "" {
+		return c.Status(fiber.StatusNotFound).JSON(ResponseError{
+			Message: "redacted photo not available",
+		})
+	}
+	return sendPhotoFile(c, photo.RedactedPath)
+}
+
+// GetFaceCrop handles face crop get.
+//
+// @Summary get face crop
+// @Description stream the thumbnail of a single detected face, with ETag and Last-Modified validators, conditional GET and Range support
+// @Tags Face Detection
+// @Produce image/jpeg
+// @Param id path string true "photo id"
+// @Param index path integer true "face index in the photo result"
+// @Param Range header string false "byte range"
+// @Param If-None-Match header string false "ETag of a cached copy"
+// @Param If-Modified-Since header string false "Last-Modified of a cached copy"
+// @Success 200 {file} binary
+// @Success 206 {file} binary
+// @Success 304 "not modified"
+// @Failure 400 {object} ResponseError
+// @Failure 404 {object} ResponseError
+// @Failure 416 "range not satisfiable"
+// @Failure 500 {object} ResponseError
+// @Router /photo/{id}/faces/{index} [get]
+func (h *photoHandler) GetFaceCrop(c *fiber.Ctx) error {
+	id, err := domain.ParsePhotoID(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+	index, err := c.ParamsInt("index")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+			Message: "invalid face index",
+		})
+	}
+	photo, err := h.photoService.GetPhoto(c.Context(), id)
+	if err != nil {
+		return photoError(c, err)
+	}
+	if index < 0 || index >= len(photo.Faces) || photo.Faces[index].CropPath == "" {
+		return c.Status(fiber.StatusNotFound).JSON(ResponseError{
+			Message: "face crop not available",
+		})
+	}
+	return sendPhotoFile(c, photo.Faces[index].CropPath)
+}
+
+// hasAnnotated reports whether the annotated copy of the photo was stored.
+// It is written by a successful detection run that did not redact the photo.
+func hasAnnotated(photo *domain.Photo) bool {
+	return photo.Status == "processed" && photo.RedactedPath == "" && photo.FilePath != ""
+}
+
+// setFileURLs replaces the stored file paths of the photo by the URLs that
+// serve them.
+func setFileURLs(photo *domain.Photo) {
+	base := "/photo/" + photo.ID.String()
+	if photo.FilePath != "" {
+		photo.PhotoURL = base + "/original"
+	}
+	if hasAnnotated(photo) {
+		photo.AnnotatedURL = base + "/annotated"
+	}
+	if photo.RedactedPath != "" {
+		photo.RedactedURL = base + "/redacted"
+	}
+	for i := range photo.Faces {
+		if photo.Faces[i].CropPath != "" {
+			photo.Faces[i].CropURL = fmt.Sprintf("%s/faces/%d", base, i)
+		}
+	}
+}
+
+// photoError responds 404 for unknown photos and 500 otherwise.
+func photoError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return c.Status(fiber.StatusNotFound).JSON(ResponseError{
+			Message: "photo not found",
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(ResponseError{
+		Message: err.Error(),
+	})
 }
@@ -0,0 +1,350 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"image"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/anggi-susanto/go-face-detection-be/domain"
+	"github.com/anggi-susanto/go-face-detection-be/internal/detector"
+	"github.com/anggi-susanto/go-face-detection-be/internal/queue"
+	"github.com/anggi-susanto/go-face-detection-be/photo"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// fakePhotoService keeps photos in memory.
+type fakePhotoService struct {
+	photos    map[domain.PhotoID]*domain.Photo
+	saved     []*domain.Photo
+	deleted   []domain.PhotoID
+	saveErr   error
+	deleteErr error
+}
+
+func (s *fakePhotoService) Save(ctx context.Context, photo *domain.Photo) error {
+	if s.saveErr != nil {
+		return s.saveErr
+	}
+	s.saved = append(s.saved, photo)
+	return nil
+}
+
+func (s *fakePhotoService) CheckResult(ctx context.Context, id domain.PhotoID) (*domain.Photo, error) {
+	return s.GetPhoto(ctx, id)
+}
+
+func (s *fakePhotoService) GetPhoto(ctx context.Context, id domain.PhotoID) (*domain.Photo, error) {
+	photo, ok := s.photos[id]
+	if !ok {
+		return nil, mongo.ErrNoDocuments
+	}
+	copied := *photo
+	return &copied, nil
+}
+
+func (s *fakePhotoService) ListPhotos(ctx context.Context, req domain.PhotoListRequest, quality domain.QualityFilter) (*domain.PhotoPage, error) {
+	return &domain.PhotoPage{}, nil
+}
+
+func (s *fakePhotoService) Delete(ctx context.Context, id domain.PhotoID) (*domain.PhotoDeletion, error) {
+	s.deleted = append(s.deleted, id)
+	if s.deleteErr != nil {
+		return nil, s.deleteErr
+	}
+	if _, ok := s.photos[id]; !ok {
+		return nil, mongo.ErrNoDocuments
+	}
+	delete(s.photos, id)
+	return &domain.PhotoDeletion{ID: id}, nil
+}
+
+// fakeProducer records the queued messages.
+type fakeProducer struct {
+	messages []queue.Message
+	err      error
+}
+
+func (p *fakeProducer) SendToQueue(msg queue.Message) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.messages = append(p.messages, msg)
+	return nil
+}
+
+// fakeDetector is a backend that only runs the frontal cascades.
+type fakeDetector struct{}
+
+func (fakeDetector) Detect(ctx context.Context, img image.Image, params domain.DetectionParams) ([]detector.Detection, error) {
+	return nil, nil
+}
+
+func (fakeDetector) Name() string { return detector.BackendPigo }
+
+func (fakeDetector) Supports(model string) bool {
+	return model == domain.ModelFrontalDefault || model == domain.ModelFrontalAlt || model == domain.ModelFrontalAlt2
+}
+
+func (fakeDetector) Close() error { return nil }
+
+// multipartForm builds a multipart body with the given form fields and
+// files, keyed by their field name.
+func multipartForm(t *testing.T, files map[string][]byte, fields map[string][]string) (*bytes.Buffer, string) {
+	t.Helper()
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+	for name, file := range files {
+		part, err := w.CreateFormFile(name, name+".png")
+		if err != nil {
+			t.Fatalf("CreateFormFile() error = %v", err)
+		}
+		part.Write(file)
+	}
+	for name, values := range fields {
+		for _, v := range values {
+			w.WriteField(name, v)
+		}
+	}
+	w.Close()
+	return body, w.FormDataContentType()
+}
+
+// send runs the request against the app and returns the response with its
+// body read.
+func send(t *testing.T, app *fiber.App, req *http.Request) (*http.Response, []byte) {
+	t.Helper()
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("app.Test() error = %v", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("reading response error = %v", err)
+	}
+	return resp, data
+}
+
+// pngPhoto returns a PNG encoded w x h photo.
+func pngPhoto(t *testing.T, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, w, h))); err != nil {
+		t.Fatalf("png.Encode() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+// testUpload posts a multipart upload of a small photo with the given form
+// fields, leaving the photo out when withPhoto is false, and returns the
+// response status, body and Location header.
+func testUpload(t *testing.T, h PhotoHandler, withPhoto bool, fields map[string][]string) (int, []byte, string) {
+	t.Helper()
+	app := fiber.New()
+	app.Post("/upload", h.Upload)
+
+	var files map[string][]byte
+	if withPhoto {
+		files = map[string][]byte{"photo": pngPhoto(t, 8, 8)}
+	}
+	body, contentType := multipartForm(t, files, fields)
+	req := httptest.NewRequest(fiber.MethodPost, "/upload", body)
+	req.Header.Set(fiber.HeaderContentType, contentType)
+	resp, data := send(t, app, req)
+	return resp.StatusCode, data, resp.Header.Get(fiber.HeaderLocation)
+}
+
+// errorMessage decodes a ResponseError body.
+func errorMessage(t *testing.T, body []byte) string {
+	t.Helper()
+	var e ResponseError
+	if err := json.Unmarshal(body, &e); err != nil {
+		t.Fatalf("json.Unmarshal(%s) error = %v", body, err)
+	}
+	return e.Message
+}
+
+func TestUpload(t *testing.T) {
+	tests := []struct {
+		name        string
+		noPhoto     bool
+		fields      map[string][]string
+		saveErr     error
+		queueErr    error
+		wantStatus  int
+		wantErr     string
+		wantModels  []string
+		wantBurstID string
+		wantTags    []string
+	}{
+		{name: "defaults", wantStatus: fiber.StatusAccepted, wantModels: []string{domain.ModelFrontalDefault}},
+		{name: "models, burst and tags", fields: map[string][]string{
+			"models": {"frontal_alt, frontal_alt2"}, "burst_id": {"shoot_1"}, "tags": {"team,outdoor"},
+		}, wantStatus: fiber.StatusAccepted, wantModels: []string{domain.ModelFrontalAlt, domain.ModelFrontalAlt2}, wantBurstID: "shoot_1", wantTags: []string{"team", "outdoor"}},
+		{name: "no photo", noPhoto: true, wantStatus: fiber.StatusBadRequest, wantErr: "photo file is required"},
+		{name: "unsupported model", fields: map[string][]string{"models": {domain.ModelProfile}},
+			wantStatus: fiber.StatusBadRequest, wantErr: `model "profile" is not supported by the pigo detector backend`},
+		{name: "invalid burst id", fields: map[string][]string{"burst_id": {"shoot 1"}},
+			wantStatus: fiber.StatusBadRequest, wantErr: "burst_id must be at most 64 letters, digits, dashes or underscores"},
+		{name: "save failure", saveErr: errors.New("database unavailable"),
+			wantStatus: fiber.StatusInternalServerError, wantErr: "database unavailable"},
+		{name: "queue failure", queueErr: errors.New("broker unavailable"),
+			wantStatus: fiber.StatusInternalServerError, wantErr: "broker unavailable"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("PHOTO_STORAGE_PATH", t.TempDir())
+			service, producer := &fakePhotoService{saveErr: tt.saveErr}, &fakeProducer{err: tt.queueErr}
+			status, body, location := testUpload(t, NewPhotoHandler(service, producer, fakeDetector{}), !tt.noPhoto, tt.fields)
+			if status != tt.wantStatus {
+				t.Fatalf("Upload() status = %d, want %d: %s", status, tt.wantStatus, body)
+			}
+			if tt.wantErr != "" {
+				if got := errorMessage(t, body); got != tt.wantErr {
+					t.Errorf("Upload() error = %q, want %q", got, tt.wantErr)
+				}
+				return
+			}
+
+			if len(service.saved) != 1 || len(producer.messages) != 1 {
+				t.Fatalf("Upload() saved %d photos and queued %d jobs, want 1 and 1", len(service.saved), len(producer.messages))
+			}
+			photo, msg := service.saved[0], producer.messages[0]
+			if photo.Status != "pending" || photo.BurstID != tt.wantBurstID || !slices.Equal(photo.Tags, tt.wantTags) {
+				t.Errorf("saved photo status = %q, burst = %q, tags = %v, want pending, %q, %v", photo.Status, photo.BurstID, photo.Tags, tt.wantBurstID, tt.wantTags)
+			}
+			if _, err := os.Stat(photo.FilePath); err != nil {
+				t.Errorf("uploaded file not stored: %v", err)
+			}
+			if msg.PhotoID != photo.ID || !slices.Equal(msg.Params.Models, tt.wantModels) {
+				t.Errorf("queued %s with models %v, want %s with %v", msg.PhotoID, msg.Params.Models, photo.ID, tt.wantModels)
+			}
+
+			var job domain.PhotoJob
+			if err := json.Unmarshal(body, &job); err != nil {
+				t.Fatalf("json.Unmarshal(%s) error = %v", body, err)
+			}
+			id := photo.ID.String()
+			wantLinks := domain.PhotoLinks{Result: "/result/" + id, Photo: "/photo/" + id}
+			if tt.wantBurstID != "" {
+				wantLinks.Burst = "/bursts/" + tt.wantBurstID
+			}
+			if job.ID != photo.ID || job.Status != "pending" || job.BurstID != tt.wantBurstID || job.Links != wantLinks {
+				t.Errorf("Upload() job = %+v, want %s pending in burst %q with links %+v", job, id, tt.wantBurstID, wantLinks)
+			}
+			if !job.CreatedAt.Equal(photo.TimeStamp) {
+				t.Errorf("job created at %v, want %v", job.CreatedAt, photo.TimeStamp)
+			}
+			if location != wantLinks.Result {
+				t.Errorf("Location = %q, want %q", location, wantLinks.Result)
+			}
+		})
+	}
+}
+
+func TestUploadRedaction(t *testing.T) {
+	tests := []struct {
+		name       string
+		fields     map[string][]string
+		wantStatus int
+		wantErr    string
+		want       domain.RedactionOptions
+	}{
+		{name: "no redaction", wantStatus: fiber.StatusAccepted},
+		{name: "blur", fields: map[string][]string{"redact": {"blur"}},
+			wantStatus: fiber.StatusAccepted, want: domain.RedactionOptions{Method: domain.RedactBlur}},
+		{name: "box discarding the original", fields: map[string][]string{"redact": {"box"}, "discard_original": {"true"}},
+			wantStatus: fiber.StatusAccepted, want: domain.RedactionOptions{Method: domain.RedactBox, DiscardOriginal: true}},
+		{name: "unknown method", fields: map[string][]string{"redact": {"smudge"}},
+			wantStatus: fiber.StatusBadRequest, wantErr: `redact must be one of "blur", "pixelate" or "box"`},
+		{name: "discard without redaction", fields: map[string][]string{"discard_original": {"true"}},
+			wantStatus: fiber.StatusBadRequest, wantErr: "discard_original requires redact to be set"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("PHOTO_STORAGE_PATH", t.TempDir())
+			service, producer := &fakePhotoService{}, &fakeProducer{}
+			status, body, _ := testUpload(t, NewPhotoHandler(service, producer, fakeDetector{}), true, tt.fields)
+			if status != tt.wantStatus {
+				t.Fatalf("Upload() status = %d, want %d: %s", status, tt.wantStatus, body)
+			}
+			if tt.wantErr != "" {
+				if got := errorMessage(t, body); got != tt.wantErr {
+					t.Errorf("Upload() error = %q, want %q", got, tt.wantErr)
+				}
+				if len(service.saved) != 0 || len(producer.messages) != 0 {
+					t.Error("Upload() stored or queued an invalid upload")
+				}
+				return
+			}
+			if len(service.saved) != 1 || len(producer.messages) != 1 {
+				t.Fatalf("Upload() saved %d photos and queued %d jobs, want 1 and 1", len(service.saved), len(producer.messages))
+			}
+			if got := service.saved[0].Redaction; got != tt.want {
+				t.Errorf("saved redaction = %+v, want %+v", got, tt.want)
+			}
+			if got := producer.messages[0].Redaction; got != tt.want {
+				t.Errorf("queued redaction = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeletePhoto(t *testing.T) {
+	stored := domain.NewPhotoID()
+	tests := []struct {
+		name        string
+		id          string
+		deleteErr   error
+		wantStatus  int
+		wantErr     string
+		wantDeleted bool
+	}{
+		{name: "stored photo", id: stored.String(), wantStatus: fiber.StatusOK, wantDeleted: true},
+		{name: "unknown photo", id: domain.NewPhotoID().String(), wantStatus: fiber.StatusNotFound, wantErr: "photo not found", wantDeleted: true},
+		{name: "kept updating while deleting", id: stored.String(), deleteErr: photo.ErrDeleteConflict,
+			wantStatus: fiber.StatusConflict, wantErr: photo.ErrDeleteConflict.Error(), wantDeleted: true},
+		{name: "delete failure", id: stored.String(), deleteErr: errors.New("database unavailable"),
+			wantStatus: fiber.StatusInternalServerError, wantErr: "database unavailable", wantDeleted: true},
+		{name: "invalid id", id: "42", wantStatus: fiber.StatusBadRequest, wantErr: domain.ErrInvalidPhotoID.Error()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &fakePhotoService{
+				photos:    map[domain.PhotoID]*domain.Photo{stored: {ID: stored, Status: "error"}},
+				deleteErr: tt.deleteErr,
+			}
+			app := fiber.New()
+			app.Delete("/photo/:id", NewPhotoHandler(service, &fakeProducer{}, fakeDetector{}).DeletePhoto)
+
+			resp, body := send(t, app, httptest.NewRequest(fiber.MethodDelete, "/photo/"+tt.id, nil))
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("DeletePhoto() status = %d, want %d: %s", resp.StatusCode, tt.wantStatus, body)
+			}
+			if (len(service.deleted) == 1) != tt.wantDeleted {
+				t.Errorf("DeletePhoto() deleted %v, want a delete %v", service.deleted, tt.wantDeleted)
+			}
+			if tt.wantErr != "" {
+				if got := errorMessage(t, body); got != tt.wantErr {
+					t.Errorf("DeletePhoto() error = %q, want %q", got, tt.wantErr)
+				}
+				return
+			}
+			var deletion domain.PhotoDeletion
+			if err := json.Unmarshal(body, &deletion); err != nil || deletion.ID != stored {
+				t.Errorf("DeletePhoto() body = %s, want the deletion of %s", body, stored)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package rest
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"io"
+	"mime/multipart"
+
+	"github.com/anggi-susanto/go-face-detection-be/domain"
+	"github.com/anggi-susanto/go-face-detection-be/internal/embedding"
+	"github.com/anggi-susanto/go-face-detection-be/internal/imaging"
+	"github.com/anggi-susanto/go-face-detection-be/search"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type SearchHandler interface {
+	SearchFaces(c *fiber.Ctx) error
+}
+
+type searchHandler struct {
+	searchService search.Service
+}
+
+func NewSearchHandler(searchService search.Service) SearchHandler {
+	return &searchHandler{
+		searchService: searchService,
+	}
+}
+
+// SearchFaces handles search by face.
+//
+// @Summary search similar faces
+// @Description find the stored faces most similar to the largest face of an uploaded probe image, or to an existing face. Similarity comes from an LBP texture descriptor, not a face recognition model: it ranks look-alike faces and is not an identity match.
+// @Tags Face Search
+// @Accept multipart/form-data
+// @Produce json
+// @Param photo formData file false "probe image, required unless photo_id is set"
+// @Param photo_id formData string false "photo of an existing probe face"
+// @Param face_index formData integer false "index of the probe face in the photo result" default(0)
+// @Param k formData integer false "number of matches to return" default(10)
+// @Param min_sharpness formData number false "only match faces at least this sharp"
+// @Param min_brightness formData number false "only match faces at least this bright, 0 to 255"
+// @Param max_brightness formData number false "only match faces at most this bright, 0 to 255"
+// @Param min_contrast formData number false "only match faces with at least this luma standard deviation"
+// @Param min_face_size formData integer false "only match faces whose smaller side is at least this many pixels"
+// @Param unoccluded formData boolean false "only match faces without occlusion hints"
+// @Success 200 {object} domain.FaceSearchResult
+// @Failure 400 {object} ResponseError
+// @Failure 404 {object} ResponseError
+// @Failure 422 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Router /search/faces [post]
+func (h *searchHandler) SearchFaces(c *fiber.Ctx) error {
+	req := domain.FaceSearchRequest{Limit: domain.DefaultSearchLimit}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+	if err := req.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+	var filter domain.QualityFilter
+	if err := c.BodyParser(&filter); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+	if err := filter.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+
+	var matches []domain.FaceMatch
+	if file, err := c.FormFile("photo"); err == nil {
+		img, err := readUpright(file)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+				Message: "invalid probe image",
+			})
+		}
+		matches, err = h.searchService.SearchByImage(c.Context(), img, req.Limit, filter)
+		if errors.Is(err, embedding.ErrNoFace) {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(ResponseError{
+				Message: err.Error(),
+			})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(ResponseError{
+				Message: err.Error(),
+			})
+		}
+	} else if req.PhotoID != "" {
+		photoID, err := domain.ParsePhotoID(req.PhotoID)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+				Message: err.Error(),
+			})
+		}
+		matches, err = h.searchService.SearchByFace(c.Context(), photoID, req.FaceIndex, req.Limit, filter)
+		if errors.Is(err, domain.ErrFaceNotFound) || errors.Is(err, mongo.ErrNoDocuments) {
+			return c.Status(fiber.StatusNotFound).JSON(ResponseError{
+				Message: err.Error(),
+			})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(ResponseError{
+				Message: err.Error(),
+			})
+		}
+	} else {
+		return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+			Message: "either photo or photo_id is required",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(domain.FaceSearchResult{Matches: matches})
+}
+
+// readUpright decodes an uploaded image and applies its EXIF orientation.
+func readUpright(file *multipart.FileHeader) (image.Image, error) {
+	f, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return imaging.Orient(img, imaging.DecodeOrientation(bytes.NewReader(data))), nil
+}
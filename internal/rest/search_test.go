@@ -0,0 +1,128 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"image"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/anggi-susanto/go-face-detection-be/domain"
+	"github.com/anggi-susanto/go-face-detection-be/internal/embedding"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// fakeSearchService records the searches it runs and answers with err or a
+// single match.
+type fakeSearchService struct {
+	err     error
+	byImage int
+	byFace  []domain.FaceRef
+	limit   int
+	filter  domain.QualityFilter
+}
+
+func (s *fakeSearchService) SearchByImage(ctx context.Context, img image.Image, limit int, filter domain.QualityFilter) ([]domain.FaceMatch, error) {
+	s.byImage++
+	s.limit, s.filter = limit, filter
+	if s.err != nil {
+		return nil, s.err
+	}
+	return []domain.FaceMatch{{PhotoID: domain.NewPhotoID(), Score: 0.9}}, nil
+}
+
+func (s *fakeSearchService) SearchByFace(ctx context.Context, photoID domain.PhotoID, faceIndex, limit int, filter domain.QualityFilter) ([]domain.FaceMatch, error) {
+	s.byFace = append(s.byFace, domain.FaceRef{PhotoID: photoID, FaceIndex: faceIndex})
+	s.limit, s.filter = limit, filter
+	if s.err != nil {
+		return nil, s.err
+	}
+	return []domain.FaceMatch{{PhotoID: domain.NewPhotoID(), Score: 0.9}}, nil
+}
+
+func TestSearchFaces(t *testing.T) {
+	const photoHex = "65f1c2a4e13d9b0a7c4b2e10"
+	tests := []struct {
+		name        string
+		probe       bool
+		invalid     bool
+		fields      map[string][]string
+		err         error
+		wantStatus  int
+		wantErr     string
+		wantByImage int
+		wantByFace  int
+		wantLimit   int
+		wantFilter  domain.QualityFilter
+	}{
+		{name: "probe image", probe: true, wantStatus: fiber.StatusOK, wantByImage: 1, wantLimit: domain.DefaultSearchLimit},
+		{name: "probe image with k", probe: true, fields: map[string][]string{"k": {"3"}, "min_face_size": {"40"}},
+			wantStatus: fiber.StatusOK, wantByImage: 1, wantLimit: 3, wantFilter: domain.QualityFilter{MinFaceSize: 40}},
+		{name: "existing face", fields: map[string][]string{"photo_id": {photoHex}, "face_index": {"2"}},
+			wantStatus: fiber.StatusOK, wantByFace: 1, wantLimit: domain.DefaultSearchLimit},
+		{name: "probe image wins over photo_id", probe: true, fields: map[string][]string{"photo_id": {photoHex}},
+			wantStatus: fiber.StatusOK, wantByImage: 1, wantLimit: domain.DefaultSearchLimit},
+		{name: "no probe", wantStatus: fiber.StatusBadRequest, wantErr: "either photo or photo_id is required"},
+		{name: "k too large", probe: true, fields: map[string][]string{"k": {"101"}},
+			wantStatus: fiber.StatusBadRequest, wantErr: "k must be between 1 and 100"},
+		{name: "negative face index", fields: map[string][]string{"photo_id": {photoHex}, "face_index": {"-1"}},
+			wantStatus: fiber.StatusBadRequest, wantErr: "face_index must not be negative"},
+		{name: "crossed brightness", probe: true, fields: map[string][]string{"min_brightness": {"200"}, "max_brightness": {"100"}},
+			wantStatus: fiber.StatusBadRequest, wantErr: "max_brightness must not be smaller than min_brightness"},
+		{name: "invalid photo id", fields: map[string][]string{"photo_id": {"42"}},
+			wantStatus: fiber.StatusBadRequest, wantErr: domain.ErrInvalidPhotoID.Error()},
+		{name: "not an image", probe: true, invalid: true, wantStatus: fiber.StatusBadRequest, wantErr: "invalid probe image"},
+		{name: "no face in the probe", probe: true, err: embedding.ErrNoFace,
+			wantStatus: fiber.StatusUnprocessableEntity, wantErr: embedding.ErrNoFace.Error(), wantByImage: 1, wantLimit: domain.DefaultSearchLimit},
+		{name: "face not searchable", fields: map[string][]string{"photo_id": {photoHex}}, err: domain.ErrFaceNotFound,
+			wantStatus: fiber.StatusNotFound, wantErr: domain.ErrFaceNotFound.Error(), wantByFace: 1, wantLimit: domain.DefaultSearchLimit},
+		{name: "unknown photo", fields: map[string][]string{"photo_id": {photoHex}}, err: mongo.ErrNoDocuments,
+			wantStatus: fiber.StatusNotFound, wantErr: mongo.ErrNoDocuments.Error(), wantByFace: 1, wantLimit: domain.DefaultSearchLimit},
+		{name: "search failure", probe: true, err: errors.New("index unavailable"),
+			wantStatus: fiber.StatusInternalServerError, wantErr: "index unavailable", wantByImage: 1, wantLimit: domain.DefaultSearchLimit},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &fakeSearchService{err: tt.err}
+			app := fiber.New()
+			app.Post("/search/faces", NewSearchHandler(service).SearchFaces)
+
+			var files map[string][]byte
+			if tt.probe {
+				files = map[string][]byte{"photo": pngPhoto(t, 16, 16)}
+			}
+			if tt.invalid {
+				files = map[string][]byte{"photo": []byte("not an image")}
+			}
+			body, contentType := multipartForm(t, files, tt.fields)
+			req := httptest.NewRequest(fiber.MethodPost, "/search/faces", body)
+			req.Header.Set(fiber.HeaderContentType, contentType)
+			resp, data := send(t, app, req)
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("SearchFaces() status = %d, want %d: %s", resp.StatusCode, tt.wantStatus, data)
+			}
+			if tt.wantErr != "" {
+				if got := errorMessage(t, data); got != tt.wantErr {
+					t.Errorf("SearchFaces() error = %q, want %q", got, tt.wantErr)
+				}
+			} else {
+				var result domain.FaceSearchResult
+				if err := json.Unmarshal(data, &result); err != nil || len(result.Matches) != 1 {
+					t.Errorf("SearchFaces() body = %s, want one match", data)
+				}
+			}
+			if service.byImage != tt.wantByImage || len(service.byFace) != tt.wantByFace {
+				t.Errorf("SearchFaces() ran %d image and %d face searches, want %d and %d", service.byImage, len(service.byFace), tt.wantByImage, tt.wantByFace)
+			}
+			if service.limit != tt.wantLimit || service.filter != tt.wantFilter {
+				t.Errorf("SearchFaces() limit = %d, filter = %+v, want %d, %+v", service.limit, service.filter, tt.wantLimit, tt.wantFilter)
+			}
+			if tt.wantByFace == 1 && (service.byFace[0].PhotoID.String() != photoHex) {
+				t.Errorf("SearchFaces() probe face = %+v, want photo %s", service.byFace[0], photoHex)
+			}
+		})
+	}
+}
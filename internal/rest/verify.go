@@ -0,0 +1,114 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/anggi-susanto/go-face-detection-be/domain"
+	"github.com/anggi-susanto/go-face-detection-be/internal/embedding"
+	"github.com/anggi-susanto/go-face-detection-be/verify"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type VerifyHandler interface {
+	Verify(c *fiber.Ctx) error
+}
+
+type verifyHandler struct {
+	verifyService verify.Service
+}
+
+func NewVerifyHandler(verifyService verify.Service) VerifyHandler {
+	return &verifyHandler{
+		verifyService: verifyService,
+	}
+}
+
+// Verify handles 1:1 look-alike scoring of two faces.
+//
+// @Summary score how much two faces look alike
+// @Description synchronously score how much two faces look alike. Each side is an uploaded image, whose largest face is used, or a face of a processed photo. look_alike_score comes from an LBP texture descriptor, not a face recognition model: it is not an identity decision and must not be used as one, such as matching a selfie to an ID document.
+// @Tags Face Search
+// @Accept multipart/form-data
+// @Produce json
+// @Param photo_a formData file false "first image, required unless photo_id_a is set"
+// @Param photo_id_a formData string false "photo of the first face"
+// @Param face_index_a formData integer false "index of the first face in its photo result" default(0)
+// @Param photo_b formData file false "second image, required unless photo_id_b is set"
+// @Param photo_id_b formData string false "photo of the second face"
+// @Param face_index_b formData integer false "index of the second face in its photo result" default(0)
+// @Success 200 {object} domain.VerifyResult
+// @Failure 400 {object} ResponseError
+// @Failure 404 {object} ResponseError
+// @Failure 422 {object} ResponseError
+// @Failure 500 {object} ResponseError
+// @Failure 504 {object} ResponseError
+// @Router /verify [post]
+func (h *verifyHandler) Verify(c *fiber.Ctx) error {
+	var req domain.VerifyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+
+	a, err := verifyProbe(c, "a", req.PhotoIDA, req.FaceIndexA)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+	b, err := verifyProbe(c, "b", req.PhotoIDB, req.FaceIndexB)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+
+	result, err := h.verifyService.Verify(c.Context(), a, b)
+	switch {
+	case err == nil:
+		return c.Status(fiber.StatusOK).JSON(result)
+	case errors.Is(err, context.DeadlineExceeded):
+		return c.Status(fiber.StatusGatewayTimeout).JSON(ResponseError{
+			Message: "verification exceeded its time budget",
+		})
+	case errors.Is(err, embedding.ErrNoFace):
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	case errors.Is(err, domain.ErrFaceNotFound), errors.Is(err, mongo.ErrNoDocuments):
+		return c.Status(fiber.StatusNotFound).JSON(ResponseError{
+			Message: domain.ErrFaceNotFound.Error(),
+		})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(ResponseError{
+			Message: err.Error(),
+		})
+	}
+}
+
+// verifyProbe builds one side of a verification from the photo_<side> upload
+// or, without one, from the referenced face.
+func verifyProbe(c *fiber.Ctx, side, photoID string, faceIndex int) (verify.Probe, error) {
+	if file, err := c.FormFile("photo_" + side); err == nil {
+		img, err := readUpright(file)
+		if err != nil {
+			return verify.Probe{}, fmt.Errorf("invalid image photo_%s", side)
+		}
+		return verify.Probe{Image: img}, nil
+	}
+	if photoID == "" {
+		return verify.Probe{}, fmt.Errorf("either photo_%s or photo_id_%s is required", side, side)
+	}
+	if faceIndex < 0 {
+		return verify.Probe{}, fmt.Errorf("face_index_%s must not be negative", side)
+	}
+	id, err := domain.ParsePhotoID(photoID)
+	if err != nil {
+		return verify.Probe{}, fmt.Errorf("invalid photo_id_%s", side)
+	}
+	return verify.Probe{PhotoID: id, FaceIndex: faceIndex}, nil
+}
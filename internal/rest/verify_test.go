@@ -0,0 +1,111 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/anggi-susanto/go-face-detection-be/domain"
+	"github.com/anggi-susanto/go-face-detection-be/internal/embedding"
+	"github.com/anggi-susanto/go-face-detection-be/verify"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// fakeVerifyService records the probes it compares and answers with err or
+// a fixed score.
+type fakeVerifyService struct {
+	err    error
+	probes []verify.Probe
+}
+
+func (s *fakeVerifyService) Verify(ctx context.Context, a, b verify.Probe) (*domain.VerifyResult, error) {
+	s.probes = append(s.probes, a, b)
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &domain.VerifyResult{LookAlikeScore: 0.8}, nil
+}
+
+func TestVerify(t *testing.T) {
+	const photoHex = "65f1c2a4e13d9b0a7c4b2e10"
+	stored := map[string][]string{"photo_id_a": {photoHex}, "face_index_a": {"1"}, "photo_id_b": {photoHex}}
+	tests := []struct {
+		name       string
+		files      []string
+		invalid    bool
+		fields     map[string][]string
+		err        error
+		wantStatus int
+		wantErr    string
+		wantImages []bool
+	}{
+		{name: "uploaded images", files: []string{"photo_a", "photo_b"}, wantStatus: fiber.StatusOK, wantImages: []bool{true, true}},
+		{name: "stored faces", fields: stored, wantStatus: fiber.StatusOK, wantImages: []bool{false, false}},
+		{name: "upload wins over photo_id", files: []string{"photo_a"}, fields: stored, wantStatus: fiber.StatusOK, wantImages: []bool{true, false}},
+		{name: "second side missing", files: []string{"photo_a"},
+			wantStatus: fiber.StatusBadRequest, wantErr: "either photo_b or photo_id_b is required"},
+		{name: "negative face index", files: []string{"photo_b"}, fields: map[string][]string{"photo_id_a": {photoHex}, "face_index_a": {"-1"}},
+			wantStatus: fiber.StatusBadRequest, wantErr: "face_index_a must not be negative"},
+		{name: "invalid photo id", files: []string{"photo_a"}, fields: map[string][]string{"photo_id_b": {"42"}},
+			wantStatus: fiber.StatusBadRequest, wantErr: "invalid photo_id_b"},
+		{name: "not an image", files: []string{"photo_a", "photo_b"}, invalid: true,
+			wantStatus: fiber.StatusBadRequest, wantErr: "invalid image photo_a"},
+		{name: "no face in an upload", files: []string{"photo_a", "photo_b"}, err: embedding.ErrNoFace,
+			wantStatus: fiber.StatusUnprocessableEntity, wantErr: embedding.ErrNoFace.Error(), wantImages: []bool{true, true}},
+		{name: "face not found", fields: stored, err: domain.ErrFaceNotFound,
+			wantStatus: fiber.StatusNotFound, wantErr: domain.ErrFaceNotFound.Error(), wantImages: []bool{false, false}},
+		{name: "unknown photo", fields: stored, err: mongo.ErrNoDocuments,
+			wantStatus: fiber.StatusNotFound, wantErr: domain.ErrFaceNotFound.Error(), wantImages: []bool{false, false}},
+		{name: "time budget exceeded", fields: stored, err: context.DeadlineExceeded,
+			wantStatus: fiber.StatusGatewayTimeout, wantErr: "verification exceeded its time budget", wantImages: []bool{false, false}},
+		{name: "verification failure", fields: stored, err: errors.New("detector unavailable"),
+			wantStatus: fiber.StatusInternalServerError, wantErr: "detector unavailable", wantImages: []bool{false, false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &fakeVerifyService{err: tt.err}
+			app := fiber.New()
+			app.Post("/verify", NewVerifyHandler(service).Verify)
+
+			files := map[string][]byte{}
+			for _, name := range tt.files {
+				files[name] = pngPhoto(t, 16, 16)
+				if tt.invalid {
+					files[name] = []byte("not an image")
+				}
+			}
+			body, contentType := multipartForm(t, files, tt.fields)
+			req := httptest.NewRequest(fiber.MethodPost, "/verify", body)
+			req.Header.Set(fiber.HeaderContentType, contentType)
+			resp, data := send(t, app, req)
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("Verify() status = %d, want %d: %s", resp.StatusCode, tt.wantStatus, data)
+			}
+			if tt.wantErr != "" {
+				if got := errorMessage(t, data); got != tt.wantErr {
+					t.Errorf("Verify() error = %q, want %q", got, tt.wantErr)
+				}
+			} else {
+				var result map[string]any
+				if err := json.Unmarshal(data, &result); err != nil || len(result) != 1 || result["look_alike_score"] != 0.8 {
+					t.Errorf("Verify() body = %s, want only look_alike_score 0.8", data)
+				}
+			}
+			if len(service.probes) != len(tt.wantImages) {
+				t.Fatalf("Verify() compared %d probes, want %d", len(service.probes), len(tt.wantImages))
+			}
+			for i, p := range service.probes {
+				if (p.Image != nil) != tt.wantImages[i] {
+					t.Errorf("probe %d has image %v, want %v", i, p.Image != nil, tt.wantImages[i])
+				}
+				if p.Image == nil && (p.PhotoID.String() != photoHex || p.FaceIndex != 1-i) {
+					t.Errorf("probe %d = face %d of %s, want face %d of %s", i, p.FaceIndex, p.PhotoID, 1-i, photoHex)
+				}
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -34,3 +35,47 @@ func SavePhoto(c *fiber.Ctx) (string, error) {
 
 	return filePath, nil
 }
+
+// ProcessedPath returns the path of the annotated copy of the photo stored at
+// filePath.
+func ProcessedPath(filePath string) string {
+	return derivedPath(filePath, "processed_")
+}
+
+// RedactedPath returns the path of the redacted copy of the photo stored at
+// filePath.
+func RedactedPath(filePath string) string {
+	return derivedPath(filePath, "redacted_")
+}
+
+// derivedPath names a copy of the photo stored at filePath with the given
+// prefix. Copies are encoded as PNG for PNG uploads and as JPEG otherwise,
+// so the extension is ".png" or ".jpg" whatever the upload extension.
+func derivedPath(filePath, prefix string) string {
+	base := filepath.Base(filePath)
+	ext := filepath.Ext(base)
+	name := strings.TrimSuffix(base, ext)
+	if strings.EqualFold(ext, ".png") {
+		ext = ".png"
+	} else {
+		ext = ".jpg"
+	}
+	return filepath.Join(filepath.Dir(filePath), prefix+name+ext)
+}
+
+// FaceCropPath returns the path of the thumbnail of the index-th face
+// detected on the photo stored at filePath.
+func FaceCropPath(filePath string, index int) string {
+	base := filepath.Base(filePath)
+	name := strings.TrimSuffix(base, filepath.Ext(base))
+	return filepath.Join(filepath.Dir(filePath), fmt.Sprintf("face_%d_%s.jpg", index, name))
+}
+
+// RemovePhoto deletes a stored photo file. A file that no longer exists is
+// not an error.
+func RemovePhoto(filePath string) error {
+	if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
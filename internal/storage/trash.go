@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"errors"
+	"os"
+)
+
+// trashSuffix marks files moved out of the way by a Trash.
+const trashSuffix = ".deleting"
+
+// Trash moves files out of the way so that a group of files can either be
+// removed or restored together.
+type Trash struct {
+	moved []string
+}
+
+// Move renames the file out of the way. A file that does not exist is
+// skipped and reported as not moved.
+//
+// Parameters:
+// - filePath: The path of the file to move.
+//
+// Returns:
+// - bool: True if the file was moved.
+// - error: An error object if the file exists but could not be moved, otherwise nil.
+func (t *Trash) Move(filePath string) (bool, error) {
+	if err := os.Rename(filePath, filePath+trashSuffix); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	t.moved = append(t.moved, filePath)
+	return true, nil
+}
+
+// Restore moves every file back to its path.
+//
+// Returns:
+// - []string: The paths of the files that could not be restored.
+func (t *Trash) Restore() []string {
+	var failed []string
+	for _, filePath := range t.moved {
+		if err := os.Rename(filePath+trashSuffix, filePath); err != nil {
+			failed = append(failed, filePath)
+		}
+	}
+	t.moved = nil
+	return failed
+}
+
+// Empty removes every moved file.
+//
+// Returns:
+// - []string: The original paths of the files that could not be removed. They are left under the moved name, with a ".deleting" suffix.
+func (t *Trash) Empty() []string {
+	var failed []string
+	for _, filePath := range t.moved {
+		if err := RemovePhoto(filePath + trashSuffix); err != nil {
+			failed = append(failed, filePath)
+		}
+	}
+	t.moved = nil
+	return failed
+}
@@ -0,0 +1,120 @@
+package person
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"strings"
+	"time"
+
+	"github.com/anggi-susanto/go-face-detection-be/domain"
+	"github.com/anggi-susanto/go-face-detection-be/internal/detector"
+	"github.com/anggi-susanto/go-face-detection-be/internal/embedding"
+	"github.com/anggi-susanto/go-face-detection-be/internal/recognition"
+	"github.com/anggi-susanto/go-face-detection-be/internal/repository/mongo"
+)
+
+type Service interface {
+	Enroll(ctx context.Context, req domain.PersonRequest, photos []image.Image) (*domain.Person, error)
+	EnrollEmbeddings(ctx context.Context, req domain.PersonRequest, embeddings [][]float32) (*domain.Person, error)
+	GetPerson(ctx context.Context, id domain.PersonID) (*domain.Person, error)
+	ListPeople(ctx context.Context) ([]domain.Person, error)
+	GetPhotos(ctx context.Context, id domain.PersonID, quality domain.QualityFilter) ([]domain.Photo, error)
+}
+
+type service struct {
+	personRepository mongo.PersonRepository
+	photoRepository  mongo.PhotoRepository
+	detector         detector.Detector
+	embedder         embedding.Embedder
+	gallery          recognition.Gallery
+}
+
+// NewService creates the people service.
+//
+// Parameters:
+// - personRepository: The repository holding the enrolled people.
+// - photoRepository: The repository holding the processed photos.
+// - detector: The detector used to find the face in reference photos.
+// - embedder: The embedder used to describe reference faces.
+// - gallery: The gallery new people are enrolled into.
+//
+// Returns:
+// - Service: The people service.
+func NewService(personRepository mongo.PersonRepository, photoRepository mongo.PhotoRepository, detector detector.Detector, embedder embedding.Embedder, gallery recognition.Gallery) Service {
+	return &service{
+		personRepository: personRepository,
+		photoRepository:  photoRepository,
+		detector:         detector,
+		embedder:         embedder,
+		gallery:          gallery,
+	}
+}
+
+// Enroll stores a new person described by the largest face of every
+// reference photo and adds them to the gallery.
+func (s *service) Enroll(ctx context.Context, req domain.PersonRequest, photos []image.Image) (*domain.Person, error) {
+	embeddings := make([][]float32, 0, len(photos))
+	for i, img := range photos {
+		vector, err := embedding.EmbedLargestFace(ctx, s.detector, s.embedder, img)
+		if err != nil {
+			return nil, fmt.Errorf("reference photo %d: %w", i+1, err)
+		}
+		embeddings = append(embeddings, vector)
+	}
+	return s.EnrollEmbeddings(ctx, req, embeddings)
+}
+
+// EnrollEmbeddings stores a new person described by the given reference
+// embeddings and adds them to the gallery.
+func (s *service) EnrollEmbeddings(ctx context.Context, req domain.PersonRequest, embeddings [][]float32) (*domain.Person, error) {
+	person := &domain.Person{
+		ID:         domain.NewPersonID(),
+		Name:       strings.TrimSpace(req.Name),
+		References: len(embeddings),
+		Embeddings: embeddings,
+		CreatedAt:  time.Now(),
+	}
+	if err := s.personRepository.Create(ctx, person); err != nil {
+		return nil, err
+	}
+	s.gallery.Enroll(person.ID, person.Embeddings)
+	return person, nil
+}
+
+func (s *service) GetPerson(ctx context.Context, id domain.PersonID) (*domain.Person, error) {
+	return s.personRepository.FindByID(ctx, id)
+}
+
+func (s *service) ListPeople(ctx context.Context) ([]domain.Person, error) {
+	return s.personRepository.FindAll(ctx)
+}
+
+// GetPhotos lists the photos with a face assigned to or suggested as the
+// person that passes the quality filter.
+func (s *service) GetPhotos(ctx context.Context, id domain.PersonID, quality domain.QualityFilter) ([]domain.Photo, error) {
+	if _, err := s.personRepository.FindByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.photoRepository.FindByPersonID(ctx, id, quality)
+}
+
+// LoadGallery enrolls every stored person into the gallery.
+//
+// Parameters:
+// - ctx: The context.Context object for the function.
+// - personRepository: The repository holding the enrolled people.
+// - gallery: The gallery to fill.
+//
+// Returns:
+// - error: An error object if the people could not be loaded, otherwise nil.
+func LoadGallery(ctx context.Context, personRepository *mongo.PersonRepository, gallery recognition.Gallery) error {
+	people, err := personRepository.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+	for _, p := range people {
+		gallery.Enroll(p.ID, p.Embeddings)
+	}
+	return nil
+}
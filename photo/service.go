@@ -2,24 +2,64 @@ package photo
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/anggi-susanto/go-face-detection-be/domain"
+	"github.com/anggi-susanto/go-face-detection-be/internal/clustering"
+	"github.com/anggi-susanto/go-face-detection-be/internal/index"
 	"github.com/anggi-susanto/go-face-detection-be/internal/repository/mongo"
+	"github.com/anggi-susanto/go-face-detection-be/internal/storage"
+	"github.com/sirupsen/logrus"
+	mongodriver "go.mongodb.org/mongo-driver/mongo"
 )
 
+// maxDeleteAttempts bounds the retries of a delete racing with the worker
+// updating the photo.
+const maxDeleteAttempts = 3
+
+// ErrDeleteConflict is returned when the photo kept changing while it was
+// being deleted.
+var ErrDeleteConflict = errors.New("photo was updated while deleting, try again")
+
 type Service interface {
 	Save(ctx context.Context, photo *domain.Photo) error
-	CheckResult(ctx context.Context, id string) (*domain.Photo, error)
-	GetPhoto(ctx context.Context, id string) (*domain.Photo, error)
+	CheckResult(ctx context.Context, id domain.PhotoID) (*domain.Photo, error)
+	GetPhoto(ctx context.Context, id domain.PhotoID) (*domain.Photo, error)
+	ListPhotos(ctx context.Context, req domain.PhotoListRequest, quality domain.QualityFilter) (*domain.PhotoPage, error)
+	Delete(ctx context.Context, id domain.PhotoID) (*domain.PhotoDeletion, error)
+}
+
+// photoStore stores the photo documents, it is implemented by
+// mongo.PhotoRepository.
+type photoStore interface {
+	Create(ctx context.Context, photo *domain.Photo) error
+	FindByID(ctx context.Context, id domain.PhotoID) (*domain.Photo, error)
+	List(ctx context.Context, req domain.PhotoListRequest, quality domain.QualityFilter) (*domain.PhotoPage, error)
+	Delete(ctx context.Context, photo *domain.Photo) error
 }
 
 type service struct {
-	photoRepository mongo.PhotoRepository
+	photoRepository photoStore
+	index           index.Index
+	clusters        clustering.Remover
 }
 
-func NewService(photoRepository mongo.PhotoRepository) Service {
+// NewService creates the photo service.
+//
+// Parameters:
+// - photoRepository: The repository holding the photos.
+// - index: The face search index deleted photos are dropped from.
+// - clusters: The clusters deleted photos are dropped from.
+//
+// Returns:
+// - Service: The photo service.
+func NewService(photoRepository mongo.PhotoRepository, index index.Index, clusters clustering.Remover) Service {
 	return &service{
-		photoRepository: photoRepository,
+		photoRepository: &photoRepository,
+		index:           index,
+		clusters:        clusters,
 	}
 }
 
@@ -27,10 +67,115 @@ func (s *service) Save(ctx context.Context, photo *domain.Photo) error {
 	return s.photoRepository.Create(ctx, photo)
 }
 
-func (s *service) CheckResult(ctx context.Context, id string) (*domain.Photo, error) {
+func (s *service) CheckResult(ctx context.Context, id domain.PhotoID) (*domain.Photo, error) {
 	return s.photoRepository.FindByID(ctx, id)
 }
 
-func (s *service) GetPhoto(ctx context.Context, id string) (*domain.Photo, error) {
+func (s *service) GetPhoto(ctx context.Context, id domain.PhotoID) (*domain.Photo, error) {
 	return s.photoRepository.FindByID(ctx, id)
 }
+
+func (s *service) ListPhotos(ctx context.Context, req domain.PhotoListRequest, quality domain.QualityFilter) (*domain.PhotoPage, error) {
+	return s.photoRepository.List(ctx, req, quality)
+}
+
+// Delete removes the photo document together with the original, the
+// annotated and redacted copies and the face crops. Files are first moved
+// aside and only removed once the document is deleted, so a failure before
+// that leaves the photo untouched. The document is only deleted if the
+// worker did not update it in the meantime, otherwise the delete starts over
+// with the new files.
+func (s *service) Delete(ctx context.Context, id domain.PhotoID) (*domain.PhotoDeletion, error) {
+	for attempt := 0; attempt < maxDeleteAttempts; attempt++ {
+		photo, err := s.photoRepository.FindByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		deletion, err := s.delete(ctx, photo)
+		if !errors.Is(err, mongodriver.ErrNoDocuments) {
+			return deletion, err
+		}
+	}
+	return nil, ErrDeleteConflict
+}
+
+func (s *service) delete(ctx context.Context, photo *domain.Photo) (*domain.PhotoDeletion, error) {
+	var trash storage.Trash
+	kinds := make(map[string]string)
+	var moved []string
+	for _, f := range photoFiles(photo) {
+		ok, err := trash.Move(f.path)
+		if err != nil {
+			return nil, restore(&trash, kinds, fmt.Errorf("failed to remove %s file: %w", f.kind, err))
+		}
+		if ok {
+			kinds[f.path] = f.kind
+			moved = append(moved, f.path)
+		}
+	}
+
+	if err := s.photoRepository.Delete(ctx, photo); err != nil {
+		return nil, restore(&trash, kinds, err)
+	}
+
+	deletion := &domain.PhotoDeletion{ID: photo.ID, Removed: []string{}}
+	left := make(map[string]bool)
+	for _, path := range trash.Empty() {
+		logrus.Errorf("Failed to remove moved %s of deleted photo %s", path, photo.ID)
+		left[path] = true
+	}
+	for _, path := range moved {
+		if left[path] {
+			deletion.Leftover = append(deletion.Leftover, kinds[path])
+		} else {
+			deletion.Removed = append(deletion.Removed, kinds[path])
+		}
+	}
+
+	s.index.Remove(photo.ID)
+	if err := s.clusters.RemovePhoto(ctx, photo.ID); err != nil {
+		// The next clustering run drops the faces anyway.
+		logrus.Errorf("Failed to remove deleted photo %s from clusters: %v", photo.ID, err)
+	}
+	return deletion, nil
+}
+
+// restore moves the files of a failed delete back and returns err, naming
+// the files that could not be restored.
+func restore(trash *storage.Trash, kinds map[string]string, err error) error {
+	failed := trash.Restore()
+	if len(failed) == 0 {
+		return err
+	}
+	names := make([]string, 0, len(failed))
+	for _, path := range failed {
+		logrus.Errorf("Failed to restore %s", path)
+		names = append(names, kinds[path])
+	}
+	return fmt.Errorf("%w, files left behind: %s", err, strings.Join(names, ", "))
+}
+
+type photoFile struct {
+	kind string
+	path string
+}
+
+// photoFiles lists the files stored for the photo.
+func photoFiles(photo *domain.Photo) []photoFile {
+	var files []photoFile
+	if photo.FilePath != "" {
+		files = append(files,
+			photoFile{"original", photo.FilePath},
+			photoFile{"processed", storage.ProcessedPath(photo.FilePath)},
+		)
+	}
+	if photo.RedactedPath != "" {
+		files = append(files, photoFile{"redacted", photo.RedactedPath})
+	}
+	for i, f := range photo.Faces {
+		if f.CropPath != "" {
+			files = append(files, photoFile{fmt.Sprintf("face_%d", i), f.CropPath})
+		}
+	}
+	return files
+}
@@ -0,0 +1,181 @@
+package photo
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/anggi-susanto/go-face-detection-be/domain"
+	"github.com/anggi-susanto/go-face-detection-be/internal/index"
+	"github.com/anggi-susanto/go-face-detection-be/internal/storage"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// fakePhotos keeps photo documents in memory. Delete fails with deleteErr,
+// or reports the first conflicts calls as updated since read, and checks
+// that the files of the photo were moved aside before it is called.
+type fakePhotos struct {
+	t         *testing.T
+	photos    map[domain.PhotoID]*domain.Photo
+	conflicts int
+	deleteErr error
+	deletes   int
+}
+
+func (f *fakePhotos) Create(ctx context.Context, photo *domain.Photo) error {
+	f.photos[photo.ID] = photo
+	return nil
+}
+
+func (f *fakePhotos) FindByID(ctx context.Context, id domain.PhotoID) (*domain.Photo, error) {
+	photo, ok := f.photos[id]
+	if !ok {
+		return nil, mongo.ErrNoDocuments
+	}
+	copied := *photo
+	return &copied, nil
+}
+
+func (f *fakePhotos) List(ctx context.Context, req domain.PhotoListRequest, quality domain.QualityFilter) (*domain.PhotoPage, error) {
+	return &domain.PhotoPage{}, nil
+}
+
+func (f *fakePhotos) Delete(ctx context.Context, photo *domain.Photo) error {
+	f.deletes++
+	for _, file := range photoFiles(photo) {
+		if exists(file.path) {
+			f.t.Errorf("%s file still in place when deleting the document", file.kind)
+		}
+	}
+	if f.deleteErr != nil {
+		return f.deleteErr
+	}
+	if f.deletes <= f.conflicts {
+		return mongo.ErrNoDocuments
+	}
+	delete(f.photos, photo.ID)
+	return nil
+}
+
+// fakeClusters records the photos removed from the clusters.
+type fakeClusters struct{ removed []domain.PhotoID }
+
+func (c *fakeClusters) RemovePhoto(ctx context.Context, id domain.PhotoID) error {
+	c.removed = append(c.removed, id)
+	return nil
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// storedPhoto writes the files of a processed photo with two face crops, or
+// only the original of a photo whose processing failed, and returns it.
+func storedPhoto(t *testing.T, status string) *domain.Photo {
+	t.Helper()
+	dir := t.TempDir()
+	photo := &domain.Photo{
+		ID:        domain.NewPhotoID(),
+		FilePath:  filepath.Join(dir, "photo.jpg"),
+		Status:    status,
+		UpdatedAt: time.Now(),
+	}
+	files := []string{photo.FilePath}
+	if status == "processed" {
+		photo.RedactedPath = filepath.Join(dir, "redacted_photo.jpg")
+		photo.Faces = []domain.Face{
+			{CropPath: filepath.Join(dir, "face_0.jpg"), Embedding: []float32{1, 0}},
+			{CropPath: filepath.Join(dir, "face_1.jpg"), Embedding: []float32{0, 1}},
+		}
+		files = append(files, storage.ProcessedPath(photo.FilePath), photo.RedactedPath, photo.Faces[0].CropPath, photo.Faces[1].CropPath)
+	}
+	for _, path := range files {
+		if err := os.WriteFile(path, []byte(path), 0o644); err != nil {
+			t.Fatalf("os.WriteFile() error = %v", err)
+		}
+	}
+	return photo
+}
+
+func TestDelete(t *testing.T) {
+	dbErr := errors.New("database unavailable")
+	tests := []struct {
+		name        string
+		status      string
+		unknown     bool
+		conflicts   int
+		deleteErr   error
+		wantErr     error
+		wantDeletes int
+		wantRemoved []string
+	}{
+		{name: "processed photo", status: "processed", wantDeletes: 1,
+			wantRemoved: []string{"original", "processed", "redacted", "face_0", "face_1"}},
+		{name: "photo in the error state", status: "error", wantDeletes: 1, wantRemoved: []string{"original"}},
+		{name: "updated once while deleting", status: "processed", conflicts: 1, wantDeletes: 2,
+			wantRemoved: []string{"original", "processed", "redacted", "face_0", "face_1"}},
+		{name: "kept updating while deleting", status: "processed", conflicts: maxDeleteAttempts,
+			wantErr: ErrDeleteConflict, wantDeletes: maxDeleteAttempts},
+		{name: "document delete fails", status: "processed", deleteErr: dbErr, wantErr: dbErr, wantDeletes: 1},
+		{name: "unknown photo", status: "processed", unknown: true, wantErr: mongo.ErrNoDocuments},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			photo := storedPhoto(t, tt.status)
+			files := photoFiles(photo)
+			repo := &fakePhotos{t: t, photos: map[domain.PhotoID]*domain.Photo{}, conflicts: tt.conflicts, deleteErr: tt.deleteErr}
+			if !tt.unknown {
+				repo.photos[photo.ID] = photo
+			}
+			idx := index.NewBruteForceIndex()
+			idx.Add(index.PhotoEntries(photo)...)
+			clusters := &fakeClusters{}
+			s := &service{photoRepository: repo, index: idx, clusters: clusters}
+
+			got, err := s.Delete(context.Background(), photo.ID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Delete() error = %v, want %v", err, tt.wantErr)
+			}
+			if repo.deletes != tt.wantDeletes {
+				t.Errorf("Delete() deleted the document %d times, want %d", repo.deletes, tt.wantDeletes)
+			}
+
+			if tt.wantErr != nil {
+				// A failed delete leaves the photo, its files and its faces
+				// untouched.
+				for _, f := range files {
+					if !exists(f.path) || exists(f.path+".deleting") {
+						t.Errorf("%s file not restored", f.kind)
+					}
+				}
+				if _, ok := repo.photos[photo.ID]; !ok && !tt.unknown {
+					t.Error("document deleted")
+				}
+				if idx.Len() != len(photo.Faces) || len(clusters.removed) != 0 {
+					t.Errorf("index holds %d faces and %d photos were removed from clusters, want %d and 0", idx.Len(), len(clusters.removed), len(photo.Faces))
+				}
+				return
+			}
+
+			if got.ID != photo.ID || !slices.Equal(got.Removed, tt.wantRemoved) || len(got.Leftover) != 0 {
+				t.Errorf("Delete() = %+v, want %s with removed %v", got, photo.ID, tt.wantRemoved)
+			}
+			for _, f := range files {
+				if exists(f.path) || exists(f.path+".deleting") {
+					t.Errorf("%s file left behind", f.kind)
+				}
+			}
+			if _, ok := repo.photos[photo.ID]; ok {
+				t.Error("document not deleted")
+			}
+			if idx.Len() != 0 || !slices.Equal(clusters.removed, []domain.PhotoID{photo.ID}) {
+				t.Errorf("index holds %d faces and clusters dropped %v, want 0 and %s", idx.Len(), clusters.removed, photo.ID)
+			}
+		})
+	}
+}
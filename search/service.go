@@ -0,0 +1,115 @@
+package search
+
+import (
+	"context"
+	"image"
+
+	"github.com/anggi-susanto/go-face-detection-be/domain"
+	"github.com/anggi-susanto/go-face-detection-be/internal/detector"
+	"github.com/anggi-susanto/go-face-detection-be/internal/embedding"
+	"github.com/anggi-susanto/go-face-detection-be/internal/index"
+	"github.com/anggi-susanto/go-face-detection-be/internal/repository/mongo"
+)
+
+type Service interface {
+	SearchByImage(ctx context.Context, img image.Image, limit int, filter domain.QualityFilter) ([]domain.FaceMatch, error)
+	SearchByFace(ctx context.Context, photoID domain.PhotoID, faceIndex, limit int, filter domain.QualityFilter) ([]domain.FaceMatch, error)
+}
+
+// photoFinder looks up processed photos, it is implemented by
+// mongo.PhotoRepository.
+type photoFinder interface {
+	FindByID(ctx context.Context, id domain.PhotoID) (*domain.Photo, error)
+}
+
+type service struct {
+	photoRepository photoFinder
+	detector        detector.Detector
+	embedder        embedding.Embedder
+	index           index.Index
+}
+
+// NewService creates the face search service.
+//
+// Parameters:
+// - photoRepository: The repository holding the processed photos.
+// - detector: The detector used to find the face in probe images.
+// - embedder: The embedder used to describe probe faces.
+// - index: The index holding the embeddings of every stored face.
+//
+// Returns:
+// - Service: The face search service.
+func NewService(photoRepository mongo.PhotoRepository, detector detector.Detector, embedder embedding.Embedder, index index.Index) Service {
+	return &service{
+		photoRepository: &photoRepository,
+		detector:        detector,
+		embedder:        embedder,
+		index:           index,
+	}
+}
+
+// SearchByImage finds the stored faces passing the quality filter most
+// similar to the largest face of an upright probe image.
+func (s *service) SearchByImage(ctx context.Context, img image.Image, limit int, filter domain.QualityFilter) ([]domain.FaceMatch, error) {
+	vector, err := embedding.EmbedLargestFace(ctx, s.detector, s.embedder, img)
+	if err != nil {
+		return nil, err
+	}
+	return toFaceMatches(s.index.Search(vector, limit, qualityFilter(filter))), nil
+}
+
+// SearchByFace finds the stored faces passing the quality filter most
+// similar to a face of a processed photo, leaving out the face itself.
+func (s *service) SearchByFace(ctx context.Context, photoID domain.PhotoID, faceIndex, limit int, filter domain.QualityFilter) ([]domain.FaceMatch, error) {
+	photo, err := s.photoRepository.FindByID(ctx, photoID)
+	if err != nil {
+		return nil, err
+	}
+	if faceIndex >= len(photo.Faces) || len(photo.Faces[faceIndex].Embedding) == 0 {
+		return nil, domain.ErrFaceNotFound
+	}
+
+	matches := s.index.Search(photo.Faces[faceIndex].Embedding, limit, func(e index.Entry) bool {
+		return (e.PhotoID != photo.ID || e.FaceIndex != faceIndex) && filter.Match(e.Quality)
+	})
+	return toFaceMatches(matches), nil
+}
+
+// qualityFilter returns the index filter of a quality filter, nil when it
+// accepts every face.
+func qualityFilter(filter domain.QualityFilter) func(index.Entry) bool {
+	if filter.Empty() {
+		return nil
+	}
+	return func(e index.Entry) bool {
+		return filter.Match(e.Quality)
+	}
+}
+
+func toFaceMatches(matches []index.Match) []domain.FaceMatch {
+	faces := make([]domain.FaceMatch, 0, len(matches))
+	for _, m := range matches {
+		faces = append(faces, domain.FaceMatch{PhotoID: m.PhotoID, FaceIndex: m.FaceIndex, Score: m.Score})
+	}
+	return faces
+}
+
+// LoadIndex adds the embeddings of every stored face to the index.
+//
+// Parameters:
+// - ctx: The context.Context object for the function.
+// - photoRepository: The repository holding the processed photos.
+// - idx: The index to fill.
+//
+// Returns:
+// - error: An error object if the embeddings could not be loaded, otherwise nil.
+func LoadIndex(ctx context.Context, photoRepository *mongo.PhotoRepository, idx index.Index) error {
+	photos, err := photoRepository.FindFaceEmbeddings(ctx)
+	if err != nil {
+		return err
+	}
+	for i := range photos {
+		idx.Add(index.PhotoEntries(&photos[i])...)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"image"
+	"testing"
+
+	"github.com/anggi-susanto/go-face-detection-be/domain"
+	"github.com/anggi-susanto/go-face-detection-be/internal/detector"
+	"github.com/anggi-susanto/go-face-detection-be/internal/embedding"
+	"github.com/anggi-susanto/go-face-detection-be/internal/index"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// fakePhotos keeps processed photos in memory.
+type fakePhotos map[domain.PhotoID]*domain.Photo
+
+func (f fakePhotos) FindByID(ctx context.Context, id domain.PhotoID) (*domain.Photo, error) {
+	photo, ok := f[id]
+	if !ok {
+		return nil, mongo.ErrNoDocuments
+	}
+	return photo, nil
+}
+
+// fixedDetector finds a face covering the whole image, or none when empty.
+type fixedDetector struct{ empty bool }
+
+func (d fixedDetector) Detect(ctx context.Context, img image.Image, params domain.DetectionParams) ([]detector.Detection, error) {
+	if d.empty {
+		return nil, nil
+	}
+	return []detector.Detection{{Rect: img.Bounds()}}, nil
+}
+
+func (fixedDetector) Name() string { return "fixed" }
+
+func (fixedDetector) Supports(model string) bool { return true }
+
+func (fixedDetector) Close() error { return nil }
+
+// constEmbedder describes every face by the same vector.
+type constEmbedder []float32
+
+func (e constEmbedder) Embed(face image.Image) []float32 { return e }
+
+func TestSearchByImage(t *testing.T) {
+	a, b := domain.NewPhotoID(), domain.NewPhotoID()
+	tests := []struct {
+		name    string
+		empty   bool
+		limit   int
+		filter  domain.QualityFilter
+		want    []domain.FaceMatch
+		wantErr error
+	}{
+		{name: "no face in the probe", empty: true, limit: 5, wantErr: embedding.ErrNoFace},
+		{name: "best matches first", limit: 5, want: []domain.FaceMatch{
+			{PhotoID: a, FaceIndex: 0, Score: 1}, {PhotoID: b, FaceIndex: 1, Score: 0.6}, {PhotoID: b, FaceIndex: 0, Score: 0},
+		}},
+		{name: "limited", limit: 1, want: []domain.FaceMatch{{PhotoID: a, FaceIndex: 0, Score: 1}}},
+		{name: "quality filter", limit: 5, filter: domain.QualityFilter{MinFaceSize: 50}, want: []domain.FaceMatch{
+			{PhotoID: b, FaceIndex: 1, Score: 0.6}, {PhotoID: b, FaceIndex: 0, Score: 0},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idx := index.NewBruteForceIndex()
+			idx.Add(index.Entry{PhotoID: a, Vector: []float32{1, 0}, Quality: domain.FaceQuality{Size: 30}})
+			idx.Add(
+				index.Entry{PhotoID: b, Vector: []float32{0, 1}, Quality: domain.FaceQuality{Size: 60}},
+				index.Entry{PhotoID: b, FaceIndex: 1, Vector: []float32{0.6, 0.8}, Quality: domain.FaceQuality{Size: 60}},
+			)
+			s := &service{detector: fixedDetector{empty: tt.empty}, embedder: constEmbedder{1, 0}, index: idx}
+
+			got, err := s.SearchByImage(context.Background(), image.NewGray(image.Rect(0, 0, 32, 32)), tt.limit, tt.filter)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SearchByImage() error = %v, want %v", err, tt.wantErr)
+			}
+			assertMatches(t, got, tt.want)
+		})
+	}
+}
+
+func TestSearchByFace(t *testing.T) {
+	a, b, missing := domain.NewPhotoID(), domain.NewPhotoID(), domain.NewPhotoID()
+	photos := fakePhotos{
+		a: {ID: a, Faces: []domain.Face{{Embedding: []float32{1, 0}}, {}}},
+		b: {ID: b, Faces: []domain.Face{{Embedding: []float32{0.6, 0.8}, Quality: domain.FaceQuality{Size: 60}}, {Embedding: []float32{1, 0}, Quality: domain.FaceQuality{Size: 20}}}},
+	}
+	tests := []struct {
+		name      string
+		photoID   domain.PhotoID
+		faceIndex int
+		filter    domain.QualityFilter
+		want      []domain.FaceMatch
+		wantErr   error
+	}{
+		{name: "leaves the probe face out", photoID: a, want: []domain.FaceMatch{
+			{PhotoID: b, FaceIndex: 1, Score: 1}, {PhotoID: b, FaceIndex: 0, Score: 0.6},
+		}},
+		{name: "other faces of the probe photo match", photoID: b, faceIndex: 1, want: []domain.FaceMatch{
+			{PhotoID: a, FaceIndex: 0, Score: 1}, {PhotoID: b, FaceIndex: 0, Score: 0.6},
+		}},
+		{name: "quality filter", photoID: a, filter: domain.QualityFilter{MinFaceSize: 50}, want: []domain.FaceMatch{
+			{PhotoID: b, FaceIndex: 0, Score: 0.6},
+		}},
+		{name: "face without embedding", photoID: a, faceIndex: 1, wantErr: domain.ErrFaceNotFound},
+		{name: "face out of range", photoID: a, faceIndex: 2, wantErr: domain.ErrFaceNotFound},
+		{name: "unknown photo", photoID: missing, wantErr: mongo.ErrNoDocuments},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idx := index.NewBruteForceIndex()
+			for _, photo := range photos {
+				idx.Add(index.PhotoEntries(photo)...)
+			}
+			s := &service{photoRepository: photos, index: idx}
+
+			got, err := s.SearchByFace(context.Background(), tt.photoID, tt.faceIndex, 10, tt.filter)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SearchByFace() error = %v, want %v", err, tt.wantErr)
+			}
+			assertMatches(t, got, tt.want)
+		})
+	}
+}
+
+func assertMatches(t *testing.T, got, want []domain.FaceMatch) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("matches = %+v, want %+v", got, want)
+	}
+	for i := range got {
+		if got[i].PhotoID != want[i].PhotoID || got[i].FaceIndex != want[i].FaceIndex || got[i].Score-want[i].Score > 1e-6 || want[i].Score-got[i].Score > 1e-6 {
+			t.Errorf("match %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package verify
+
+import (
+	"context"
+	"image"
+	"time"
+
+	"github.com/anggi-susanto/go-face-detection-be/domain"
+	"github.com/anggi-susanto/go-face-detection-be/internal/detector"
+	"github.com/anggi-susanto/go-face-detection-be/internal/embedding"
+	"github.com/anggi-susanto/go-face-detection-be/internal/repository/mongo"
+	"golang.org/x/sync/errgroup"
+)
+
+// Probe is one side of a verification: an upright image, whose largest face
+// is used, or a face of a processed photo.
+type Probe struct {
+	Image     image.Image
+	PhotoID   domain.PhotoID
+	FaceIndex int
+}
+
+type Service interface {
+	Verify(ctx context.Context, a, b Probe) (*domain.VerifyResult, error)
+}
+
+// photoFinder looks up processed photos, it is implemented by
+// mongo.PhotoRepository.
+type photoFinder interface {
+	FindByID(ctx context.Context, id domain.PhotoID) (*domain.Photo, error)
+}
+
+type service struct {
+	photoRepository photoFinder
+	detector        detector.Detector
+	embedder        embedding.Embedder
+	timeout         time.Duration
+}
+
+// NewService creates the face verification service.
+//
+// Parameters:
+// - photoRepository: The repository holding the processed photos.
+// - detector: The detector used to find the face in uploaded images.
+// - embedder: The embedder used to describe uploaded faces.
+// - timeout: The time budget of a single verification, 0 for none.
+//
+// Returns:
+// - Service: The face verification service.
+func NewService(photoRepository mongo.PhotoRepository, detector detector.Detector, embedder embedding.Embedder, timeout time.Duration) Service {
+	return &service{
+		photoRepository: &photoRepository,
+		detector:        detector,
+		embedder:        embedder,
+		timeout:         timeout,
+	}
+}
+
+// Verify embeds both probes in parallel within the time budget and scores
+// how much they look alike. It fails with context.DeadlineExceeded when the budget runs out.
+func (s *service) Verify(ctx context.Context, a, b Probe) (*domain.VerifyResult, error) {
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	var vectorA, vectorB []float32
+	g, gctx := errgroup.WithContext(ctx)
+	g.Go(func() (err error) {
+		vectorA, err = s.embed(gctx, a)
+		return err
+	})
+	g.Go(func() (err error) {
+		vectorB, err = s.embed(gctx, b)
+		return err
+	})
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	return &domain.VerifyResult{
+		LookAlikeScore: embedding.Cosine(vectorA, vectorB),
+	}, nil
+}
+
+func (s *service) embed(ctx context.Context, p Probe) ([]float32, error) {
+	if p.Image != nil {
+		return embedding.EmbedLargestFace(ctx, s.detector, s.embedder, p.Image)
+	}
+	photo, err := s.photoRepository.FindByID(ctx, p.PhotoID)
+	if err != nil {
+		return nil, err
+	}
+	if p.FaceIndex >= len(photo.Faces) || len(photo.Faces[p.FaceIndex].Embedding) == 0 {
+		return nil, domain.ErrFaceNotFound
+	}
+	return photo.Faces[p.FaceIndex].Embedding, nil
+}
@@ -0,0 +1,95 @@
+package verify
+
+import (
+	"context"
+	"errors"
+	"image"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/anggi-susanto/go-face-detection-be/domain"
+	"github.com/anggi-susanto/go-face-detection-be/internal/detector"
+	"github.com/anggi-susanto/go-face-detection-be/internal/embedding"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// fakePhotos keeps processed photos in memory.
+type fakePhotos map[domain.PhotoID]*domain.Photo
+
+func (f fakePhotos) FindByID(ctx context.Context, id domain.PhotoID) (*domain.Photo, error) {
+	photo, ok := f[id]
+	if !ok {
+		return nil, mongo.ErrNoDocuments
+	}
+	return photo, nil
+}
+
+// fixedDetector finds a face covering the whole image, none when empty, and
+// blocks until the context is done when slow.
+type fixedDetector struct{ empty, slow bool }
+
+func (d fixedDetector) Detect(ctx context.Context, img image.Image, params domain.DetectionParams) ([]detector.Detection, error) {
+	if d.slow {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	if d.empty {
+		return nil, nil
+	}
+	return []detector.Detection{{Rect: img.Bounds()}}, nil
+}
+
+func (fixedDetector) Name() string { return "fixed" }
+
+func (fixedDetector) Supports(model string) bool { return true }
+
+func (fixedDetector) Close() error { return nil }
+
+// constEmbedder describes every face by the same vector.
+type constEmbedder []float32
+
+func (e constEmbedder) Embed(face image.Image) []float32 { return e }
+
+func TestVerify(t *testing.T) {
+	a, b, missing := domain.NewPhotoID(), domain.NewPhotoID(), domain.NewPhotoID()
+	photos := fakePhotos{
+		a: {ID: a, Faces: []domain.Face{{Embedding: []float32{1, 0}}, {}}},
+		b: {ID: b, Faces: []domain.Face{{Embedding: []float32{0.6, 0.8}}}},
+	}
+	upload := image.NewGray(image.Rect(0, 0, 32, 32))
+	tests := []struct {
+		name      string
+		detector  fixedDetector
+		timeout   time.Duration
+		a, b      Probe
+		wantScore float64
+		wantErr   error
+	}{
+		{name: "stored faces", a: Probe{PhotoID: a}, b: Probe{PhotoID: b}, wantScore: 0.6},
+		{name: "same stored face", a: Probe{PhotoID: b}, b: Probe{PhotoID: b}, wantScore: 1},
+		{name: "uploaded and stored face", a: Probe{Image: upload}, b: Probe{PhotoID: b}, wantScore: 0.6},
+		{name: "uploaded faces", a: Probe{Image: upload}, b: Probe{Image: upload}, wantScore: 1},
+		{name: "no face in the upload", detector: fixedDetector{empty: true}, a: Probe{Image: upload}, b: Probe{PhotoID: b}, wantErr: embedding.ErrNoFace},
+		{name: "face without embedding", a: Probe{PhotoID: a, FaceIndex: 1}, b: Probe{PhotoID: b}, wantErr: domain.ErrFaceNotFound},
+		{name: "face out of range", a: Probe{PhotoID: a}, b: Probe{PhotoID: b, FaceIndex: 1}, wantErr: domain.ErrFaceNotFound},
+		{name: "unknown photo", a: Probe{PhotoID: a}, b: Probe{PhotoID: missing}, wantErr: mongo.ErrNoDocuments},
+		{name: "time budget exceeded", detector: fixedDetector{slow: true}, timeout: 10 * time.Millisecond, a: Probe{Image: upload}, b: Probe{PhotoID: b}, wantErr: context.DeadlineExceeded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &service{photoRepository: photos, detector: tt.detector, embedder: constEmbedder{1, 0}, timeout: tt.timeout}
+
+			got, err := s.Verify(context.Background(), tt.a, tt.b)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if math.Abs(got.LookAlikeScore-tt.wantScore) > 1e-6 {
+				t.Errorf("LookAlikeScore = %v, want %v", got.LookAlikeScore, tt.wantScore)
+			}
+		})
+	}
+}